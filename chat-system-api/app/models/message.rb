@@ -26,6 +26,7 @@ class Message < ApplicationRecord
       indexes :body, type: :text, analyzer: :english
       indexes :chat_id, type: :integer
       indexes :created_at, type: :date
+      indexes :updated_at, type: :date
     end
   end
   
@@ -36,7 +37,8 @@ class Message < ApplicationRecord
       number: number,
       body: body,
       chat_id: chat_id,
-      created_at: created_at
+      created_at: created_at,
+      updated_at: updated_at
     }
   end
   
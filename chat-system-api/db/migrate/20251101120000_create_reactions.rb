@@ -0,0 +1,34 @@
+class CreateReactions < ActiveRecord::Migration[7.1]
+  def change
+    create_table :reactions do |t|
+      # Foreign key to messages table
+      t.references :message, null: false, foreign_key: { on_delete: :cascade }
+      
+      # Opaque identifier of the reacting user (owned by the client application)
+      t.string :user_id, null: false, limit: 255
+      
+      # Emoji from the allowlist enforced by the Go service
+      t.string :emoji, null: false, limit: 32
+
+      # Timestamps
+      t.timestamps
+    end
+
+    # One reaction per user per emoji
+    add_index :reactions, [:message_id, :user_id, :emoji], unique: true, name: 'index_reactions_on_message_user_emoji'
+
+    create_table :reaction_counts do |t|
+      # Foreign key to messages table
+      t.references :message, null: false, foreign_key: { on_delete: :cascade }
+      
+      # Emoji and its aggregated count (flushed from Redis)
+      t.string :emoji, null: false, limit: 32
+      t.integer :count, default: 0, null: false
+
+      # Timestamps
+      t.timestamps
+    end
+
+    add_index :reaction_counts, [:message_id, :emoji], unique: true, name: 'index_reaction_counts_on_message_and_emoji'
+  end
+end
@@ -0,0 +1,21 @@
+class CreateChatReads < ActiveRecord::Migration[7.1]
+  def change
+    create_table :chat_reads do |t|
+      # Foreign key to chats table
+      t.references :chat, null: false, foreign_key: { on_delete: :cascade }
+      
+      # Opaque identifier of the reading user (owned by the client application)
+      t.string :user_id, null: false, limit: 255
+      
+      # Highest message number the user has read (only ever advances)
+      t.integer :last_read_number, default: 0, null: false
+
+      # Timestamps
+      t.timestamps
+    end
+
+    # One read marker per user per chat
+    add_index :chat_reads, [:chat_id, :user_id], unique: true, name: 'index_chat_reads_on_chat_and_user'
+    add_index :chat_reads, :user_id
+  end
+end
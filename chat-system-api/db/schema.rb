@@ -10,7 +10,7 @@
 #
 # It's strongly recommended that you check this file into your version control system.
 
-ActiveRecord::Schema[7.1].define(version: 2025_10_25_202349) do
+ActiveRecord::Schema[7.1].define(version: 2025_11_03_090000) do
   create_table "applications", charset: "utf8mb4", collation: "utf8mb4_0900_ai_ci", force: :cascade do |t|
     t.string "token", null: false
     t.string "name", null: false
@@ -21,6 +21,17 @@ ActiveRecord::Schema[7.1].define(version: 2025_10_25_202349) do
     t.index ["token"], name: "index_applications_on_token", unique: true
   end
 
+  create_table "chat_reads", charset: "utf8mb4", collation: "utf8mb4_0900_ai_ci", force: :cascade do |t|
+    t.bigint "chat_id", null: false
+    t.string "user_id", null: false
+    t.integer "last_read_number", default: 0, null: false
+    t.datetime "created_at", null: false
+    t.datetime "updated_at", null: false
+    t.index ["chat_id", "user_id"], name: "index_chat_reads_on_chat_and_user", unique: true
+    t.index ["chat_id"], name: "index_chat_reads_on_chat_id"
+    t.index ["user_id"], name: "index_chat_reads_on_user_id"
+  end
+
   create_table "chats", charset: "utf8mb4", collation: "utf8mb4_0900_ai_ci", force: :cascade do |t|
     t.bigint "application_id", null: false
     t.integer "number", null: false
@@ -44,6 +55,29 @@ ActiveRecord::Schema[7.1].define(version: 2025_10_25_202349) do
     t.index ["created_at"], name: "index_messages_on_created_at"
   end
 
+  create_table "reaction_counts", charset: "utf8mb4", collation: "utf8mb4_0900_ai_ci", force: :cascade do |t|
+    t.bigint "message_id", null: false
+    t.string "emoji", limit: 32, null: false
+    t.integer "count", default: 0, null: false
+    t.datetime "created_at", null: false
+    t.datetime "updated_at", null: false
+    t.index ["message_id", "emoji"], name: "index_reaction_counts_on_message_and_emoji", unique: true
+    t.index ["message_id"], name: "index_reaction_counts_on_message_id"
+  end
+
+  create_table "reactions", charset: "utf8mb4", collation: "utf8mb4_0900_ai_ci", force: :cascade do |t|
+    t.bigint "message_id", null: false
+    t.string "user_id", null: false
+    t.string "emoji", limit: 32, null: false
+    t.datetime "created_at", null: false
+    t.datetime "updated_at", null: false
+    t.index ["message_id", "user_id", "emoji"], name: "index_reactions_on_message_user_emoji", unique: true
+    t.index ["message_id"], name: "index_reactions_on_message_id"
+  end
+
+  add_foreign_key "chat_reads", "chats", on_delete: :cascade
   add_foreign_key "chats", "applications"
   add_foreign_key "messages", "chats"
+  add_foreign_key "reaction_counts", "messages", on_delete: :cascade
+  add_foreign_key "reactions", "messages", on_delete: :cascade
 end
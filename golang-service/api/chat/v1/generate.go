@@ -0,0 +1,12 @@
+// Package chatv1 holds the protobuf messages and gRPC client and server
+// stubs for the chat service. Other Go services import it for a typed
+// client:
+//
+//	conn, err := grpc.NewClient("chat-service:9090", grpc.WithTransportCredentials(insecure.NewCredentials()))
+//	client := chatv1.NewChatServiceClient(conn)
+//	ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", apiKey)
+//	chat, err := client.CreateChat(ctx, &chatv1.CreateChatRequest{ApplicationToken: token})
+package chatv1
+
+// Regenerating needs protoc, protoc-gen-go and protoc-gen-go-grpc on PATH
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative chat.proto
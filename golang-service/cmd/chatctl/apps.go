@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+)
+
+func appCreate(e *env, args []string) error {
+	fs := flag.NewFlagSet("app create", flag.ExitOnError)
+	name := fs.String("name", "", "application name")
+	fs.Parse(args)
+	
+	if strings.TrimSpace(*name) == "" {
+		return fmt.Errorf("-name is required")
+	}
+	
+	app, err := services.NewApplicationService(e.appRepo, e.counterSvc).Create(strings.TrimSpace(*name))
+	if err != nil {
+		return err
+	}
+	
+	fmt.Printf("Created application %q\ntoken: %s\n", app.Name, app.Token)
+	return nil
+}
+
+func appList(e *env, args []string) error {
+	fs := flag.NewFlagSet("app list", flag.ExitOnError)
+	page := fs.Int("page", 1, "page number")
+	perPage := fs.Int("per-page", 50, "applications per page")
+	fs.Parse(args)
+	
+	if *page < 1 || *perPage < 1 {
+		return fmt.Errorf("-page and -per-page must be positive")
+	}
+	
+	apps, err := e.appRepo.List((*page-1)**perPage, *perPage)
+	if err != nil {
+		return err
+	}
+	total, err := e.appRepo.Count()
+	if err != nil {
+		return err
+	}
+	
+	tokens := make([]string, len(apps))
+	for i, app := range apps {
+		tokens[i] = app.Token
+	}
+	counters, err := e.counterSvc.GetChatCounters(tokens)
+	if err != nil {
+		return err
+	}
+	
+	w := newTable()
+	fmt.Fprintln(w, "TOKEN\tNAME\tCHATS_COUNT\tREDIS_COUNTER\tCREATED_AT")
+	for _, app := range apps {
+		counter := "-"
+		if value, ok := counters[app.Token]; ok {
+			counter = fmt.Sprint(value)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", app.Token, app.Name, app.ChatsCount, counter, formatTime(app.CreatedAt))
+	}
+	w.Flush()
+	
+	fmt.Printf("\npage %d, %d of %d applications\n", *page, len(apps), total)
+	return nil
+}
+
+func appRename(e *env, args []string) error {
+	fs := flag.NewFlagSet("app rename", flag.ExitOnError)
+	token := fs.String("token", "", "application token")
+	name := fs.String("name", "", "new application name")
+	fs.Parse(args)
+	
+	if err := services.ValidateToken(*token); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*name) == "" {
+		return fmt.Errorf("-name is required")
+	}
+	
+	app, err := e.appRepo.GetByToken(*token)
+	if err != nil {
+		return err
+	}
+	if err := e.appRepo.Rename(app.ID, strings.TrimSpace(*name)); err != nil {
+		return err
+	}
+	
+	fmt.Printf("Renamed application %s: %q -> %q\n", app.Token, app.Name, strings.TrimSpace(*name))
+	return nil
+}
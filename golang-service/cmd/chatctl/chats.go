@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/database"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+)
+
+func chatInspect(e *env, args []string) error {
+	fs := flag.NewFlagSet("chat inspect", flag.ExitOnError)
+	token := fs.String("token", "", "application token")
+	number := fs.Int("chat", 0, "chat number")
+	fs.Parse(args)
+	
+	app, chat, err := resolveChat(e, *token, *number)
+	if err != nil {
+		return err
+	}
+	
+	stats, err := e.chatRepo.MessageStats(chat.ID)
+	if err != nil {
+		return err
+	}
+	counter, exists, err := e.counterSvc.GetMessageCounter(chat.ID)
+	if err != nil {
+		return err
+	}
+	
+	w := newTable()
+	fmt.Fprintf(w, "application\t%s (%s)\n", app.Token, app.Name)
+	fmt.Fprintf(w, "chat\t#%d (id %d)\n", chat.Number, chat.ID)
+	fmt.Fprintf(w, "created_at\t%s\n", formatTime(chat.CreatedAt))
+	fmt.Fprintf(w, "updated_at\t%s\n", formatTime(chat.UpdatedAt))
+	fmt.Fprintf(w, "messages_count\t%d\n", stats.StoredCount)
+	fmt.Fprintf(w, "messages in MySQL\t%d\n", stats.ActualCount)
+	fmt.Fprintf(w, "highest number\t%d\n", stats.MaxNumber)
+	fmt.Fprintf(w, "redis counter\t%s\n", formatCounter(counter, exists))
+	w.Flush()
+	
+	if problems := counterProblems(stats, counter, exists); len(problems) > 0 {
+		fmt.Println()
+		for _, problem := range problems {
+			fmt.Println("! " + problem)
+		}
+	}
+	return nil
+}
+
+func chatPurge(e *env, args []string) error {
+	fs := flag.NewFlagSet("chat purge", flag.ExitOnError)
+	token := fs.String("token", "", "application token")
+	number := fs.Int("chat", 0, "chat number to purge")
+	all := fs.Bool("all", false, "purge every chat of the application")
+	yes := fs.Bool("yes", false, "confirm the deletion")
+	fs.Parse(args)
+	
+	if err := services.ValidateToken(*token); err != nil {
+		return err
+	}
+	if (*number > 0) == *all {
+		return fmt.Errorf("pass exactly one of -chat or -all")
+	}
+	
+	app, err := e.appRepo.GetByToken(*token)
+	if err != nil {
+		return err
+	}
+	
+	var chats []models.Chat
+	if *all {
+		for offset := 0; ; offset += 500 {
+			page, err := e.chatRepo.ListByApplication(app.ID, offset, 500)
+			if err != nil {
+				return err
+			}
+			chats = append(chats, page...)
+			if len(page) < 500 {
+				break
+			}
+		}
+	} else {
+		chat, err := e.chatRepo.GetByApplicationAndNumber(app.ID, *number)
+		if err != nil {
+			return err
+		}
+		chats = append(chats, *chat)
+	}
+	
+	if !*yes {
+		fmt.Printf("Would purge %d chat(s) of application %s; re-run with -yes to delete\n", len(chats), app.Token)
+		return nil
+	}
+	
+	index, closeIndex, err := openSearchIndex(e)
+	if err != nil {
+		return err
+	}
+	defer closeIndex()
+	
+	reactionSvc := services.NewReactionService(database.RedisClient, nil)
+	ctx := context.Background()
+	
+	messages := 0
+	for _, chat := range chats {
+		messageIDs, err := e.chatRepo.Purge(chat.ID)
+		if err != nil {
+			return fmt.Errorf("chat #%d: %w", chat.Number, err)
+		}
+		messages += len(messageIDs)
+		
+		e.quotaSvc.ChatPurged(app, &chat)
+		if err := e.counterSvc.DeleteMessageCounter(chat.ID); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		if err := reactionSvc.Forget(chat.ID, messageIDs); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		if failed := deleteFromIndex(ctx, index, chat.ID, messageIDs); failed > 0 {
+			log.Printf("Warning: %d messages of chat #%d could not be removed from the %s index", failed, chat.Number, index.Name())
+		}
+		
+		fmt.Printf("Purged chat #%d (%d messages)\n", chat.Number, len(messageIDs))
+	}
+	
+	// The chat counter is left alone so purged numbers are never reused
+	fmt.Printf("Purged %d chat(s) and %d message(s)\n", len(chats), messages)
+	return nil
+}
+
+func resolveChat(e *env, token string, number int) (*models.Application, *models.Chat, error) {
+	if err := services.ValidateToken(token); err != nil {
+		return nil, nil, err
+	}
+	if err := services.ValidateChatNumber(number); err != nil {
+		return nil, nil, err
+	}
+	
+	app, err := e.appRepo.GetByToken(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	chat, err := e.chatRepo.GetByApplicationAndNumber(app.ID, number)
+	if err != nil {
+		return nil, nil, err
+	}
+	return app, chat, nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/database"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+)
+
+func counterGet(e *env, args []string) error {
+	fs := flag.NewFlagSet("counter get", flag.ExitOnError)
+	token := fs.String("token", "", "application token")
+	number := fs.Int("chat", 0, "chat number; omit for the application's chat counter")
+	fs.Parse(args)
+	
+	key, stats, current, exists, err := lookupCounter(e, *token, *number)
+	if err != nil {
+		return err
+	}
+	
+	fmt.Printf("%s = %s (highest number in MySQL: %d)\n", key, formatCounter(current, exists), stats.MaxNumber)
+	return nil
+}
+
+func counterSet(e *env, args []string) error {
+	fs := flag.NewFlagSet("counter set", flag.ExitOnError)
+	token := fs.String("token", "", "application token")
+	number := fs.Int("chat", 0, "chat number; omit for the application's chat counter")
+	value := fs.Int64("value", -1, "new counter value")
+	force := fs.Bool("force", false, "allow a value below the highest stored number")
+	fs.Parse(args)
+	
+	if *value < 0 {
+		return fmt.Errorf("-value must be zero or positive")
+	}
+	
+	key, stats, current, exists, err := lookupCounter(e, *token, *number)
+	if err != nil {
+		return err
+	}
+	
+	// Numbers at or below the stored maximum would be handed out again
+	if *value < int64(stats.MaxNumber) && !*force {
+		return fmt.Errorf("%d is below the highest stored number %d; duplicate numbers would follow (use -force to override)", *value, stats.MaxNumber)
+	}
+	
+	if *number == 0 {
+		err = e.counterSvc.SetChatCounter(*token, *value)
+	} else {
+		err = e.counterSvc.SetMessageCounter(stats.ID, *value)
+	}
+	if err != nil {
+		return err
+	}
+	
+	fmt.Printf("%s: %s -> %d\n", key, formatCounter(current, exists), *value)
+	return nil
+}
+
+func verify(e *env, args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	fix := fs.Bool("fix", false, "repair drift with the counter reconciler")
+	fs.Parse(args)
+	
+	drift := 0
+	w := newTable()
+	fmt.Fprintln(w, "KIND\tID\tSTORED\tACTUAL\tMAX_NUMBER\tREDIS\tPROBLEM")
+	
+	var afterID int64
+	for {
+		stats, err := e.appRepo.ListChatStats(afterID, 500)
+		if err != nil {
+			return err
+		}
+		tokens := make([]string, len(stats))
+		for i, s := range stats {
+			tokens[i] = s.Token
+		}
+		counters, err := e.counterSvc.GetChatCounters(tokens)
+		if err != nil {
+			return err
+		}
+		
+		for _, s := range stats {
+			counter, exists := counters[s.Token]
+			for _, problem := range counterProblems(&s, counter, exists) {
+				drift++
+				fmt.Fprintf(w, "application\t%s\t%d\t%d\t%d\t%s\t%s\n", s.Token, s.StoredCount, s.ActualCount, s.MaxNumber, formatCounter(counter, exists), problem)
+			}
+		}
+		
+		if len(stats) < 500 {
+			break
+		}
+		afterID = stats[len(stats)-1].ID
+	}
+	
+	afterID = 0
+	for {
+		stats, err := e.chatRepo.ListMessageStats(afterID, 500)
+		if err != nil {
+			return err
+		}
+		chatIDs := make([]int64, len(stats))
+		for i, s := range stats {
+			chatIDs[i] = s.ID
+		}
+		counters, err := e.counterSvc.GetMessageCounters(chatIDs)
+		if err != nil {
+			return err
+		}
+		
+		for _, s := range stats {
+			counter, exists := counters[s.ID]
+			for _, problem := range counterProblems(&s, counter, exists) {
+				drift++
+				fmt.Fprintf(w, "chat\t%d\t%d\t%d\t%d\t%s\t%s\n", s.ID, s.StoredCount, s.ActualCount, s.MaxNumber, formatCounter(counter, exists), problem)
+			}
+		}
+		
+		if len(stats) < 500 {
+			break
+		}
+		afterID = stats[len(stats)-1].ID
+	}
+	
+	if drift == 0 {
+		fmt.Println("✅ Counters match MySQL")
+		return nil
+	}
+	w.Flush()
+	fmt.Printf("\n%d problem(s) found\n", drift)
+	
+	if !*fix {
+		return fmt.Errorf("counters have drifted; re-run with -fix to repair")
+	}
+	
+	reconciler := services.NewCounterReconciler(database.RedisClient, e.appRepo, e.chatRepo, e.counterSvc)
+	report, err := reconciler.Reconcile(context.Background())
+	if err != nil {
+		return err
+	}
+	if report == nil {
+		return fmt.Errorf("another reconciliation is running; try again later")
+	}
+	
+	fmt.Printf("Repaired %d chats_count, %d messages_count, %d chat counters and %d message counters\n",
+		report.ChatsCountFixed,
+		report.MessagesCountFixed,
+		report.ChatCountersRaised,
+		report.MessageCountersRaised,
+	)
+	return nil
+}
+
+// lookupCounter resolves the counter addressed by token and an optional
+// chat number together with the MySQL stats it must stay above
+func lookupCounter(e *env, token string, number int) (string, *models.CounterStats, int64, bool, error) {
+	if number == 0 {
+		if err := services.ValidateToken(token); err != nil {
+			return "", nil, 0, false, err
+		}
+		app, err := e.appRepo.GetByToken(token)
+		if err != nil {
+			return "", nil, 0, false, err
+		}
+		stats, err := e.appRepo.ChatStats(app.ID)
+		if err != nil {
+			return "", nil, 0, false, err
+		}
+		current, exists, err := e.counterSvc.GetChatCounter(token)
+		return fmt.Sprintf("app:%s:chat_counter", token), stats, current, exists, err
+	}
+	
+	_, chat, err := resolveChat(e, token, number)
+	if err != nil {
+		return "", nil, 0, false, err
+	}
+	stats, err := e.chatRepo.MessageStats(chat.ID)
+	if err != nil {
+		return "", nil, 0, false, err
+	}
+	current, exists, err := e.counterSvc.GetMessageCounter(chat.ID)
+	return fmt.Sprintf("chat:%d:message_counter", chat.ID), stats, current, exists, err
+}
+
+// counterProblems lists every way a parent row disagrees with MySQL
+func counterProblems(stats *models.CounterStats, counter int64, exists bool) []string {
+	var problems []string
+	if stats.StoredCount != stats.ActualCount {
+		problems = append(problems, fmt.Sprintf("stored count %d, actual %d", stats.StoredCount, stats.ActualCount))
+	}
+	if exists && counter < int64(stats.MaxNumber) {
+		problems = append(problems, fmt.Sprintf("redis counter %d behind highest number %d", counter, stats.MaxNumber))
+	}
+	if !exists && stats.MaxNumber > 0 {
+		problems = append(problems, fmt.Sprintf("redis counter missing, highest number %d", stats.MaxNumber))
+	}
+	return problems
+}
+
+func formatCounter(value int64, exists bool) string {
+	if !exists {
+		return "(missing)"
+	}
+	return fmt.Sprint(value)
+}
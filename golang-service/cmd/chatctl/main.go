@@ -0,0 +1,191 @@
+// Command chatctl is an operator tool for applications, chats and the Redis
+// counters behind chat and message numbers.
+//
+//	chatctl app create -name NAME
+//	chatctl app list [-page n] [-per-page n]
+//	chatctl app rename -token TOKEN -name NAME
+//	chatctl chat inspect -token TOKEN -chat N
+//	chatctl chat purge -token TOKEN (-chat N | -all) -yes
+//	chatctl counter get -token TOKEN [-chat N]
+//	chatctl counter set -token TOKEN [-chat N] -value V [-force]
+//	chatctl quota get -token TOKEN
+//	chatctl quota set -token TOKEN -plan PLAN [-max-chats N] [-messages-per-day N] ...
+//	chatctl quota reset -token TOKEN
+//	chatctl usage export [-from T] [-to T] [-token TOKEN] [-out FILE]
+//	chatctl verify [-fix]
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/cache"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/database"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/search"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+	"github.com/joho/godotenv"
+)
+
+const usage = `usage: chatctl <command> [flags]
+
+commands:
+  app create      create an application
+  app list        list applications
+  app rename      rename an application
+  chat inspect    show a chat with its counters
+  chat purge      delete chats and their messages
+  counter get     show a Redis number counter
+  counter set     overwrite a Redis number counter
+  quota get       show an application's plan, limits and usage
+  quota set       assign a plan, optionally overriding its limits
+  quota reset     return an application to the default plan
+  usage export    write hourly usage as CSV
+  verify          compare counters with MySQL, optionally repairing drift
+`
+
+// env bundles the connections and repositories every command needs
+type env struct {
+	cfg         *config.Config
+	appRepo     *repository.ApplicationRepository
+	chatRepo    *repository.ChatRepository
+	messageRepo *repository.MessageRepository
+	counterSvc  *services.CounterService
+	quotaRepo   *repository.QuotaRepository
+	quotaSvc    *services.QuotaService
+	usageSvc    *services.UsageService
+}
+
+func main() {
+	log.SetFlags(0)
+	
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	
+	// Load .env file (ignore error if file doesn't exist)
+	godotenv.Load()
+	
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+	
+	if err := database.InitMySQL(cfg); err != nil {
+		log.Fatal("Failed to initialize MySQL:", err)
+	}
+	defer database.CloseMySQL()
+	
+	if err := database.InitRedis(cfg); err != nil {
+		log.Fatal("Failed to initialize Redis:", err)
+	}
+	defer database.CloseRedis()
+	
+	e := &env{
+		cfg:         cfg,
+		appRepo:     repository.NewApplicationRepository(database.DB),
+		chatRepo:    repository.NewChatRepository(database.DB),
+		messageRepo: repository.NewMessageRepository(database.DB),
+		counterSvc:  services.NewCounterService(database.RedisClient),
+		quotaRepo:   repository.NewQuotaRepository(database.DB),
+		usageSvc:    services.NewUsageService(database.RedisClient, repository.NewUsageRepository(database.DB)),
+	}
+	e.quotaSvc = services.NewQuotaService(database.RedisClient, e.quotaRepo, e.chatRepo, e.messageRepo, e.counterSvc, config.NewStore(os.Getenv("CONFIG_FILE"), cfg))
+	
+	// Writes go through the lookup cache so running services drop stale entries
+	if cfg.Cache.Enabled {
+		opts := cache.OptionsFrom(cfg.Cache)
+		e.appRepo.EnableCache(cache.NewTiered[models.Application]("app", database.RedisClient, repository.ErrApplicationNotFound, opts))
+		e.chatRepo.EnableCache(cache.NewTiered[models.Chat]("chat", database.RedisClient, repository.ErrChatNotFound, opts))
+		e.quotaRepo.EnableCache(cache.NewTiered[models.ApplicationQuota]("quota", database.RedisClient, repository.ErrQuotaNotFound, opts))
+	}
+	
+	if err := run(e, os.Args[1:]); err != nil {
+		log.Printf("Error: %v", err)
+		database.CloseRedis()
+		database.CloseMySQL()
+		os.Exit(1)
+	}
+}
+
+func run(e *env, args []string) error {
+	command := args[0]
+	if command != "verify" {
+		if len(args) < 2 {
+			return fmt.Errorf("missing subcommand for %q\n%s", command, usage)
+		}
+		command += " " + args[1]
+		args = args[2:]
+	} else {
+		args = args[1:]
+	}
+	
+	switch command {
+	case "app create":
+		return appCreate(e, args)
+	case "app list":
+		return appList(e, args)
+	case "app rename":
+		return appRename(e, args)
+	case "chat inspect":
+		return chatInspect(e, args)
+	case "chat purge":
+		return chatPurge(e, args)
+	case "counter get":
+		return counterGet(e, args)
+	case "counter set":
+		return counterSet(e, args)
+	case "quota get":
+		return quotaGet(e, args)
+	case "quota set":
+		return quotaSet(e, args)
+	case "quota reset":
+		return quotaReset(e, args)
+	case "usage export":
+		return usageExport(e, args)
+	case "verify":
+		return verify(e, args)
+	default:
+		return fmt.Errorf("unknown command %q\n%s", command, usage)
+	}
+}
+
+func newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// openSearchIndex returns the configured index so purged messages can be
+// removed from it. The MySQL backend needs no cleanup.
+func openSearchIndex(e *env) (search.SearchIndex, func(), error) {
+	index, err := search.New(e.cfg.Search, e.messageRepo)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	closeFn := func() {}
+	if closer, ok := index.(interface{ Close() error }); ok {
+		closeFn = func() { closer.Close() }
+	}
+	return index, closeFn, nil
+}
+
+func deleteFromIndex(ctx context.Context, index search.SearchIndex, chatID int64, messageIDs []int64) int {
+	failed := 0
+	for _, id := range messageIDs {
+		if err := index.Delete(ctx, chatID, id); err != nil {
+			failed++
+		}
+	}
+	return failed
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+)
+
+func quotaGet(e *env, args []string) error {
+	fs := flag.NewFlagSet("quota get", flag.ExitOnError)
+	token := fs.String("token", "", "application token")
+	fs.Parse(args)
+	
+	app, err := lookupApp(e, *token)
+	if err != nil {
+		return err
+	}
+	
+	plan, limits, err := e.quotaSvc.Limits(app.ID)
+	if err != nil {
+		return err
+	}
+	chats, _, err := e.counterSvc.GetChatCounter(app.Token)
+	if err != nil {
+		return err
+	}
+	today, err := e.quotaSvc.MessagesToday(app.Token)
+	if err != nil {
+		return err
+	}
+	
+	_, err = e.quotaRepo.Get(app.ID)
+	assigned := "default"
+	if err == nil {
+		assigned = "assigned"
+	} else if !errors.Is(err, repository.ErrQuotaNotFound) {
+		return err
+	}
+	
+	fmt.Printf("Application %s is on plan %s (%s)\n\n", app.Token, plan, assigned)
+	w := newTable()
+	fmt.Fprintln(w, "QUOTA\tLIMIT\tUSED")
+	fmt.Fprintf(w, "%s\t%s\t%d\n", services.QuotaMaxChats, formatLimit(limits.MaxChats), chats)
+	fmt.Fprintf(w, "%s\t%s\t-\n", services.QuotaMaxMessagesPerChat, formatLimit(limits.MaxMessagesPerChat))
+	fmt.Fprintf(w, "%s\t%s\t%d\n", services.QuotaMessagesPerDay, formatLimit(limits.MessagesPerDay), today)
+	fmt.Fprintf(w, "%s\t%s\t-\n", services.QuotaMaxBodyBytes, formatLimit(int64(limits.MaxBodyBytes)))
+	w.Flush()
+	return nil
+}
+
+func quotaSet(e *env, args []string) error {
+	fs := flag.NewFlagSet("quota set", flag.ExitOnError)
+	token := fs.String("token", "", "application token")
+	plan := fs.String("plan", "", "plan name from the quotas config")
+	maxChats := fs.Int64("max-chats", -1, "override the plan's max_chats (0 = unlimited)")
+	maxMessages := fs.Int64("max-messages-per-chat", -1, "override the plan's max_messages_per_chat (0 = unlimited)")
+	perDay := fs.Int64("messages-per-day", -1, "override the plan's messages_per_day (0 = unlimited)")
+	maxBody := fs.Int("max-body-bytes", -1, "override the plan's max_body_bytes (0 = unlimited)")
+	fs.Parse(args)
+	
+	plans := e.cfg.Quotas.Plans
+	if _, ok := plans[*plan]; !ok {
+		names := make([]string, 0, len(plans))
+		for name := range plans {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("-plan must be one of %s", strings.Join(names, ", "))
+	}
+	
+	app, err := lookupApp(e, *token)
+	if err != nil {
+		return err
+	}
+	
+	quota := &models.ApplicationQuota{
+		ApplicationID:      app.ID,
+		Plan:               *plan,
+		MaxChats:           optionalInt64(*maxChats),
+		MaxMessagesPerChat: optionalInt64(*maxMessages),
+		MessagesPerDay:     optionalInt64(*perDay),
+	}
+	if *maxBody >= 0 {
+		quota.MaxBodyBytes = maxBody
+	}
+	if err := e.quotaRepo.Set(quota); err != nil {
+		return err
+	}
+	
+	fmt.Printf("Application %s is now on plan %s\n", app.Token, *plan)
+	return nil
+}
+
+func quotaReset(e *env, args []string) error {
+	fs := flag.NewFlagSet("quota reset", flag.ExitOnError)
+	token := fs.String("token", "", "application token")
+	fs.Parse(args)
+	
+	app, err := lookupApp(e, *token)
+	if err != nil {
+		return err
+	}
+	if err := e.quotaRepo.Delete(app.ID); err != nil {
+		return err
+	}
+	
+	fmt.Printf("Application %s is back on the default plan %s\n", app.Token, e.cfg.Quotas.DefaultPlan)
+	return nil
+}
+
+func lookupApp(e *env, token string) (*models.Application, error) {
+	if err := services.ValidateToken(token); err != nil {
+		return nil, err
+	}
+	return e.appRepo.GetByToken(token)
+}
+
+// optionalInt64 maps the -1 flag default to "use the plan's limit"
+func optionalInt64(value int64) *int64 {
+	if value < 0 {
+		return nil
+	}
+	return &value
+}
+
+func formatLimit(limit int64) string {
+	if limit == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprint(limit)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+)
+
+func usageExport(e *env, args []string) error {
+	fs := flag.NewFlagSet("usage export", flag.ExitOnError)
+	from := fs.String("from", "", "start hour, RFC 3339 or YYYY-MM-DD (default: 24h before -to)")
+	to := fs.String("to", "", "end hour, exclusive (default: end of the current hour)")
+	token := fs.String("token", "", "only export this application")
+	out := fs.String("out", "", "write to this file instead of stdout")
+	fs.Parse(args)
+	
+	start, end, err := services.ParseUsageRange(*from, *to)
+	if err != nil {
+		return err
+	}
+	
+	var appID int64
+	if *token != "" {
+		app, err := lookupApp(e, *token)
+		if err != nil {
+			return err
+		}
+		appID = app.ID
+	}
+	
+	// Persist what is still in Redis so the export includes recent hours
+	if _, err := e.usageSvc.Flush(); err != nil {
+		return err
+	}
+	
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"application_token", "application_id", "hour", "metric", "value"})
+	rows := 0
+	err = e.usageSvc.Export(start, end, appID, func(rec models.UsageRecord) error {
+		rows++
+		return cw.Write([]string{
+			rec.ApplicationToken,
+			strconv.FormatInt(rec.ApplicationID, 10),
+			rec.Hour.UTC().Format(time.RFC3339),
+			rec.Metric,
+			strconv.FormatInt(rec.Value, 10),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	
+	if *out != "" {
+		fmt.Printf("Exported %d usage rows to %s\n", rows, *out)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Command migrate manages the schema from the SQL migrations embedded in
+// the Go service.
+//
+//	migrate up [-to VERSION]
+//	migrate down [-steps n]
+//	migrate status
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/database"
+	"github.com/joho/godotenv"
+)
+
+const usage = `usage: migrate <command> [flags]
+
+commands:
+  up      apply pending migrations (-to VERSION stops after that version)
+  down    revert applied migrations (-steps n, default 1)
+  status  list migrations and whether they are applied
+`
+
+func main() {
+	log.SetFlags(0)
+	
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	
+	// Load .env file (ignore error if file doesn't exist)
+	godotenv.Load()
+	
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+	
+	if err := database.InitMySQL(cfg); err != nil {
+		log.Fatal("Failed to initialize MySQL:", err)
+	}
+	defer database.CloseMySQL()
+	
+	args := os.Args[2:]
+	switch os.Args[1] {
+	case "up":
+		fs := flag.NewFlagSet("up", flag.ExitOnError)
+		target := fs.String("to", "", "stop after this version")
+		fs.Parse(args)
+		
+		applied, err := database.MigrateUp(database.DB, *target)
+		report("Applied", len(applied), err)
+	case "down":
+		fs := flag.NewFlagSet("down", flag.ExitOnError)
+		steps := fs.Int("steps", 1, "number of migrations to revert")
+		fs.Parse(args)
+		
+		if *steps < 1 {
+			log.Fatal("-steps must be positive")
+		}
+		reverted, err := database.MigrateDown(database.DB, *steps)
+		report("Reverted", len(reverted), err)
+	case "status":
+		statuses, err := database.MigrationStatuses(database.DB)
+		if err != nil {
+			database.CloseMySQL()
+			log.Fatal("Failed to read migration status:", err)
+		}
+		
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "STATUS\tVERSION\tNAME")
+		for _, status := range statuses {
+			state := "down"
+			if status.Applied {
+				state = "up"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", state, status.Version, status.Name)
+		}
+		w.Flush()
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		database.CloseMySQL()
+		os.Exit(2)
+	}
+}
+
+func report(action string, count int, err error) {
+	if err != nil {
+		database.CloseMySQL()
+		log.Fatalf("%s %d migration(s) before failing: %v", action, count, err)
+	}
+	log.Printf("✅ %s %d migration(s)", action, count)
+}
@@ -0,0 +1,179 @@
+// Command reindex backfills the Elasticsearch messages index from MySQL.
+//
+//	reindex [-concurrency n] [-batch n] [-reset] [-dry-run]
+//
+// A missing index is created with the Message model's mapping. Messages are
+// streamed in ID order and sent with the _bulk API. Progress is
+// checkpointed in Redis, so an interrupted run resumes where it stopped;
+// -reset starts over from the first message. -dry-run indexes nothing and
+// reports how far the index has drifted from MySQL.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/database"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/search"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	// Load .env file (ignore error if file doesn't exist)
+	godotenv.Load()
+	
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+	
+	esURL := flag.String("es-url", cfg.Search.ElasticsearchURL, "Elasticsearch base URL")
+	index := flag.String("index", cfg.Search.ElasticsearchIndex, "Elasticsearch index name")
+	concurrency := flag.Int("concurrency", 4, "parallel _bulk requests")
+	batch := flag.Int("batch", 500, "messages per _bulk request")
+	retries := flag.Int("retries", 3, "retries per failed _bulk request")
+	checkpointKey := flag.String("checkpoint-key", "", "Redis key holding the resume checkpoint (default reindex:<index>:checkpoint)")
+	reset := flag.Bool("reset", false, "ignore the stored checkpoint and start from the first message")
+	dryRun := flag.Bool("dry-run", false, "report drift between MySQL and the index without indexing")
+	flag.Parse()
+	
+	if *concurrency < 1 || *batch < 1 {
+		log.Fatal("-concurrency and -batch must be positive")
+	}
+	// Each index keeps its own checkpoint, so -index never resumes from another's
+	if *checkpointKey == "" {
+		*checkpointKey = "reindex:" + *index + ":checkpoint"
+	}
+	
+	if err := database.InitMySQL(cfg); err != nil {
+		log.Fatal("Failed to initialize MySQL:", err)
+	}
+	defer database.CloseMySQL()
+	
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	
+	messageRepo := repository.NewMessageRepository(database.DB)
+	es := search.NewElasticsearchIndex(*esURL, *index)
+	
+	if *dryRun {
+		if err := reportDrift(ctx, messageRepo, es); err != nil {
+			log.Fatal("Dry run failed:", err)
+		}
+		return
+	}
+	
+	if err := database.InitRedis(cfg); err != nil {
+		log.Fatal("Failed to initialize Redis:", err)
+	}
+	defer database.CloseRedis()
+	
+	created, err := es.EnsureIndex(ctx)
+	if err != nil {
+		log.Fatal("Failed to prepare index:", err)
+	}
+	if created {
+		log.Printf("✅ Created index %s", *index)
+	}
+	
+	checkpoint := search.NewRedisCheckpoint(database.RedisClient, *checkpointKey)
+	if *reset {
+		if err := checkpoint.Clear(ctx); err != nil {
+			log.Fatal("Failed to reset checkpoint:", err)
+		}
+	}
+	
+	reindexer := &search.Reindexer{
+		Fetch: func(afterID int64, limit int) ([]search.Document, error) {
+			messages, err := messageRepo.ListAfterID(afterID, limit)
+			if err != nil {
+				return nil, err
+			}
+			docs := make([]search.Document, len(messages))
+			for i, message := range messages {
+				docs[i] = search.DocumentFromMessage(message)
+			}
+			return docs, nil
+		},
+		Target:      es,
+		Checkpoint:  checkpoint,
+		BatchSize:   *batch,
+		Concurrency: *concurrency,
+		MaxRetries:  *retries,
+	}
+	
+	stats, err := reindexer.Run(ctx)
+	if err != nil {
+		log.Fatalf("Reindex stopped after %d messages (checkpoint at ID %d, rerun to resume): %v", stats.Indexed, stats.LastID, err)
+	}
+	
+	log.Printf("✅ Indexed %d messages in %d batches (IDs %d-%d) in %s",
+		stats.Indexed, stats.Batches, stats.StartID, stats.LastID, stats.Duration.Round(1e6))
+}
+
+// maxDriftChats bounds the per-chat terms aggregation
+const maxDriftChats = 10000
+
+func reportDrift(ctx context.Context, messageRepo *repository.MessageRepository, es *search.ElasticsearchIndex) error {
+	dbTotal, err := messageRepo.Count()
+	if err != nil {
+		return err
+	}
+	
+	indexTotal, err := es.Count(ctx)
+	if err != nil {
+		return err
+	}
+	
+	dbByChat, err := messageRepo.CountByChat()
+	if err != nil {
+		return err
+	}
+	
+	indexByChat, err := es.CountByChat(ctx, maxDriftChats)
+	if err != nil {
+		return err
+	}
+	
+	chatIDs := make(map[int64]bool)
+	for id := range dbByChat {
+		chatIDs[id] = true
+	}
+	for id := range indexByChat {
+		chatIDs[id] = true
+	}
+	
+	var drifted []int64
+	for id := range chatIDs {
+		if dbByChat[id] != indexByChat[id] {
+			drifted = append(drifted, id)
+		}
+	}
+	sort.Slice(drifted, func(i, j int) bool { return drifted[i] < drifted[j] })
+	
+	fmt.Printf("MySQL messages:  %d\n", dbTotal)
+	fmt.Printf("Indexed:         %d\n", indexTotal)
+	fmt.Printf("Drift:           %+d\n", indexTotal-dbTotal)
+	fmt.Printf("Chats drifted:   %d of %d\n", len(drifted), len(chatIDs))
+	
+	if len(drifted) > 0 {
+		fmt.Printf("\n%-10s %10s %10s %8s\n", "CHAT_ID", "MYSQL", "INDEX", "DRIFT")
+		for _, id := range drifted {
+			fmt.Printf("%-10d %10d %10d %+8d\n", id, dbByChat[id], indexByChat[id], indexByChat[id]-dbByChat[id])
+		}
+	}
+	
+	if len(dbByChat) > maxDriftChats {
+		fmt.Printf("\nNote: per-chat index counts cover at most %d chats\n", maxDriftChats)
+	}
+	
+	return nil
+}
@@ -0,0 +1,71 @@
+// Command searchindex maintains the embedded search index used when
+// SEARCH_BACKEND=embedded.
+//
+//	searchindex rebuild [-dir path] [-batch n]
+//
+// Rebuild while the service is stopped: a running server keeps serving the
+// index it loaded and will not see the rebuilt one until restarted.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/database"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/search"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "rebuild" {
+		fmt.Fprintln(os.Stderr, "usage: searchindex rebuild [-dir path] [-batch n]")
+		os.Exit(2)
+	}
+	
+	// Load .env file (ignore error if file doesn't exist)
+	godotenv.Load()
+	
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+	
+	flags := flag.NewFlagSet("rebuild", flag.ExitOnError)
+	dir := flags.String("dir", cfg.Search.IndexDir, "index directory")
+	batch := flags.Int("batch", 1000, "messages fetched per query")
+	flags.Parse(os.Args[2:])
+	
+	if err := database.InitMySQL(cfg); err != nil {
+		log.Fatal("Failed to initialize MySQL:", err)
+	}
+	defer database.CloseMySQL()
+	
+	index, err := search.NewEmbeddedIndex(*dir)
+	if err != nil {
+		log.Fatal("Failed to open index:", err)
+	}
+	defer index.Close()
+	
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	
+	messageRepo := repository.NewMessageRepository(database.DB)
+	started := time.Now()
+	
+	count, err := index.Rebuild(ctx, func(yield func(search.Document) error) error {
+		return search.ScanMessages(messageRepo, 0, *batch, yield)
+	})
+	if err != nil {
+		log.Fatalf("Rebuild failed after %d messages: %v", count, err)
+	}
+	
+	log.Printf("✅ Rebuilt index in %s with %d messages (%s)", *dir, count, time.Since(started).Round(time.Millisecond))
+}
@@ -1,78 +1,287 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
+	chatv1 "github.com/AhmedAbdelbasetAli/chat-service/api/chat/v1"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/cache"
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/database"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/grpcserver"
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/handlers"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/logging"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/messaging"
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/middleware"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/search"
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
+	"google.golang.org/grpc"
 	
 )
 
+// configPollInterval is how often config and secret files are checked for
+// changes; SIGHUP reloads immediately
+const configPollInterval = 5 * time.Second
+
+// grpcShutdownTimeout bounds how long open gRPC calls may finish; streams
+// that outlive it are cut off
+const grpcShutdownTimeout = 10 * time.Second
+
 func main() {
+	logging.Install()
+	
 	// Load .env file (ignore error if file doesn't exist)
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using system environment variables")
 	}
 	
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML config file")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration with secrets redacted and exit")
+	flag.Parse()
+	
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := config.LoadServer(*configFile)
 	if err != nil {
-		log.Fatal("Failed to load config:", err)
+		log.Fatal("Failed to load config: ", err)
 	}
 	
-	// Initialize databases
-	if err := database.InitMySQL(cfg); err != nil {
+	if *printConfig {
+		out, err := cfg.Redacted().YAML()
+		if err != nil {
+			log.Fatal("Failed to render config: ", err)
+		}
+		os.Stdout.Write(out)
+		return
+	}
+	
+	// Security, logging, message and feature settings are hot-reloadable
+	store := config.NewStore(*configFile, cfg)
+	logging.SetLevel(cfg.Logging.Level)
+	store.OnReload(func(c *config.Config) {
+		logging.SetLevel(c.Logging.Level)
+	})
+	
+	// Open connection pools; connections are established by connect below
+	if err := database.OpenMySQL(cfg); err != nil {
 		log.Fatal("Failed to initialize MySQL:", err)
 	}
 	defer database.CloseMySQL()
 	
-	if err := database.InitRedis(cfg); err != nil {
-		log.Fatal("Failed to initialize Redis:", err)
-	}
+	database.OpenRedis(cfg)
 	defer database.CloseRedis()
 	
+	// Background workers stop when the service shuts down
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workers sync.WaitGroup
+	
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		store.Watch(workerCtx, configPollInterval)
+	}()
+	
 	// Initialize repositories
 	appRepo := repository.NewApplicationRepository(database.DB)
 	chatRepo := repository.NewChatRepository(database.DB)
 	messageRepo := repository.NewMessageRepository(database.DB)
+	reactionRepo := repository.NewReactionRepository(database.DB)
+	readRepo := repository.NewReadReceiptRepository(database.DB)
+	quotaRepo := repository.NewQuotaRepository(database.DB)
+	usageRepo := repository.NewUsageRepository(database.DB)
+	webhookRepo := repository.NewWebhookRepository(database.DB)
 	
 	// Initialize services
 	counterSvc := services.NewCounterService(database.RedisClient)
+	appSvc := services.NewApplicationService(appRepo, counterSvc)
+	reactionSvc := services.NewReactionService(database.RedisClient, reactionRepo)
+	hub := services.NewHub(database.RedisClient)
+	quotaSvc := services.NewQuotaService(database.RedisClient, quotaRepo, chatRepo, messageRepo, counterSvc, store)
+	usageSvc := services.NewUsageService(database.RedisClient, usageRepo)
+	webhookSvc := services.NewWebhookService(webhookRepo, cfg.Webhooks)
+	presenceSvc := services.NewPresenceService(database.RedisClient, hub)
+	
+	searchIndex, err := search.New(cfg.Search, messageRepo)
+	if err != nil {
+		log.Fatal("Failed to initialize search:", err)
+	}
+	
+	// Chat and message writes shared by the HTTP and gRPC APIs
+	messagingSvc := messaging.NewService(chatRepo, messageRepo, counterSvc, quotaSvc, usageSvc, webhookSvc, reactionSvc, hub, searchIndex)
+	
+	// Cache application and chat lookups in process and in Redis
+	var caches []interface{ Run(context.Context) }
+	if cfg.Cache.Enabled {
+		opts := cache.OptionsFrom(cfg.Cache)
+		appCache := cache.NewTiered[models.Application]("app", database.RedisClient, repository.ErrApplicationNotFound, opts)
+		chatCache := cache.NewTiered[models.Chat]("chat", database.RedisClient, repository.ErrChatNotFound, opts)
+		quotaCache := cache.NewTiered[models.ApplicationQuota]("quota", database.RedisClient, repository.ErrQuotaNotFound, opts)
+		appRepo.EnableCache(appCache)
+		chatRepo.EnableCache(chatCache)
+		quotaRepo.EnableCache(quotaCache)
+		caches = append(caches, appCache, chatCache, quotaCache)
+	}
+	
+	startWorkers := func() {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			reactionSvc.RunFlusher(workerCtx, cfg.Reactions.FlushInterval)
+		}()
+		
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			usageSvc.RunFlusher(workerCtx, cfg.Usage.FlushInterval)
+		}()
+		
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			webhookSvc.RunDispatcher(workerCtx, cfg.Webhooks.PollInterval)
+		}()
+		
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			hub.Run(workerCtx)
+		}()
+		
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			presenceSvc.RunSweeper(workerCtx, services.TypingTTL/2)
+		}()
+		
+		// Counter reconciliation replaces the Sidekiq counter workers when
+		// the Go service runs on its own
+		if cfg.Counters.ReconcileEnabled {
+			reconciler := services.NewCounterReconciler(database.RedisClient, appRepo, chatRepo, counterSvc)
+			
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				reconciler.RunLoop(workerCtx, cfg.Counters.ReconcileInterval)
+			}()
+		}
+		
+		for _, c := range caches {
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				c.Run(workerCtx)
+			}()
+		}
+	}
 	
 	// Initialize handlers
-	healthHandler := handlers.NewHealthHandler()
-	chatHandler := handlers.NewChatHandler(appRepo, chatRepo, counterSvc)
-	messageHandler := handlers.NewMessageHandler(appRepo, chatRepo, messageRepo, counterSvc)
+	if err := handlers.CheckRequestTags(); err != nil {
+		log.Fatal("Failed to check request validation tags: ", err)
+	}
+	readiness := middleware.NewReadiness()
+	healthHandler := handlers.NewHealthHandler(readiness)
+	appHandler := handlers.NewApplicationHandler(appRepo, appSvc, counterSvc)
+	chatHandler := handlers.NewChatHandler(appRepo, chatRepo, counterSvc, readRepo, messagingSvc)
+	messageHandler := handlers.NewMessageHandler(appRepo, chatRepo, quotaSvc, messagingSvc)
+	reactionHandler := handlers.NewReactionHandler(appRepo, chatRepo, messageRepo, reactionSvc)
+	wsHandler := handlers.NewWebSocketHandler(appRepo, chatRepo, hub, presenceSvc, store)
+	presenceHandler := handlers.NewPresenceHandler(appRepo, chatRepo, presenceSvc)
+	readReceiptHandler := handlers.NewReadReceiptHandler(appRepo, chatRepo, readRepo, counterSvc)
+	searchHandler := handlers.NewSearchHandler(appRepo, chatRepo, searchIndex)
+	streamHandler := handlers.NewStreamHandler(appRepo, chatRepo, messageRepo, hub)
+	streamTokenHandler := handlers.NewStreamTokenHandler()
+	usageHandler := handlers.NewUsageHandler(appRepo, usageSvc)
+	webhookHandler := handlers.NewWebhookHandler(appRepo, webhookRepo, webhookSvc)
 	
 	// Setup router
 	router := mux.NewRouter()
 	
 	// Apply security middleware (order matters!)
+	router.Use(middleware.ConfigMiddleware(store))
 	router.Use(middleware.SecurityHeadersMiddleware)
-	router.Use(middleware.CORSMiddleware)
-	router.Use(middleware.RateLimitMiddleware)
-	router.Use(middleware.AuthMiddleware)
-	router.Use(middleware.RequestSizeMiddleware)
+	router.Use(readiness.Middleware)
+	router.Use(middleware.CORSMiddleware(store))
+	router.Use(middleware.RateLimitMiddleware(store))
+	router.Use(middleware.AuthMiddleware(store))
+	router.Use(middleware.RequestSizeMiddleware(store))
+	router.Use(middleware.UsageMiddleware(usageSvc, appRepo))
 	
 	// Register handlers
-	router.Handle("/health", healthHandler).Methods("GET", "OPTIONS")
-	router.Handle("/api/v1/chats", chatHandler).Methods("POST", "OPTIONS")
-	router.Handle("/api/v1/messages", messageHandler).Methods("POST", "OPTIONS")
+	api := &routes{
+		health:      healthHandler,
+		app:         appHandler,
+		chat:        chatHandler,
+		message:     messageHandler,
+		reaction:    reactionHandler,
+		ws:          wsHandler,
+		presence:    presenceHandler,
+		readReceipt: readReceiptHandler,
+		search:      searchHandler,
+		stream:      streamHandler,
+		streamToken: streamTokenHandler,
+		usage:       usageHandler,
+		webhook:     webhookHandler,
+	}
+	api.register(router, store)
+	
+	// Connect, migrate and verify the schema before taking traffic
+	connect := func() error {
+		if err := database.WaitForMySQL(workerCtx, cfg.Startup); err != nil {
+			return err
+		}
+		
+		// Refuse to serve on a schema older than this binary expects
+		if cfg.Database.AutoMigrate {
+			if _, err := database.MigrateUp(database.DB, ""); err != nil {
+				return fmt.Errorf("failed to migrate database: %w", err)
+			}
+		}
+		if err := database.CheckSchema(database.DB); err != nil {
+			return fmt.Errorf("%w (run `migrate up` or set DB_AUTO_MIGRATE=true)", err)
+		}
+		
+		return database.WaitForRedis(workerCtx, cfg.Startup)
+	}
+	
+	startup := func() {
+		if err := connect(); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			log.Fatal("Failed to start: ", err)
+		}
+		startWorkers()
+		readiness.SetReady()
+		log.Println("✅ Service ready")
+	}
+	
+	// With STARTUP_LAZY the server listens at once and answers 503 until
+	// its dependencies are reachable
+	if cfg.Startup.Lazy {
+		log.Println("⏳ Starting in not-ready state; connecting in the background")
+		go startup()
+	} else {
+		startup()
+	}
 	
 	// Setup graceful shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
-
+	
 	
 	
 	// Start server
@@ -81,7 +290,7 @@ func main() {
 	log.Printf("🔒 Security features: Rate limiting, CORS, Request size limits")
 	
 	// Check if API key is required
-	if os.Getenv("SKIP_API_KEY_CHECK") == "true" {
+	if cfg.Security.SkipAPIKeyCheck {
 		log.Println("⚠️  API key check disabled (development mode)")
 	} else {
 		log.Println("🔐 API key authentication enabled")
@@ -89,11 +298,50 @@ func main() {
 	
 	go func() {
 		if err := http.ListenAndServe(addr, router); err != nil {
-			log.Fatal("Server error:", err)
+			log.Fatal("Error serving HTTP:", err)
 		}
 	}()
 	
+	// The gRPC API shares the services above and mirrors the HTTP middleware
+	// with interceptors
+	var grpcServer *grpc.Server
+	if cfg.Server.GRPCPort != "" {
+		listener, err := net.Listen("tcp", ":"+cfg.Server.GRPCPort)
+		if err != nil {
+			log.Fatal("Failed to listen for gRPC: ", err)
+		}
+		
+		grpcServer = grpc.NewServer(grpcserver.Interceptors(store, readiness)...)
+		chatv1.RegisterChatServiceServer(grpcServer, grpcserver.NewServer(
+			appRepo, chatRepo, messageRepo, counterSvc, usageSvc, hub, messagingSvc, store,
+		))
+		
+		log.Printf("🚀 gRPC API starting on port %s", cfg.Server.GRPCPort)
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatal("Error serving gRPC:", err)
+			}
+		}()
+	}
+	
 	// Wait for interrupt signal
 	<-stop
 	log.Println("Shutting down gracefully...")
+	if grpcServer != nil {
+		// GracefulStop waits for every stream to end, which a connected
+		// StreamMessages client never does on its own
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(grpcShutdownTimeout):
+			log.Println("Warning: gRPC calls still open after shutdown timeout, closing them")
+			grpcServer.Stop()
+		}
+	}
+	stopWorkers()
+	workers.Wait()
 }
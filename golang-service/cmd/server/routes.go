@@ -0,0 +1,75 @@
+package main
+
+import (
+	"expvar"
+	"net/http"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/docs"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/handlers"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/middleware"
+	"github.com/gorilla/mux"
+)
+
+// routes holds the handlers behind the HTTP API. Every route registered
+// here must be described in docs/routes.go; routes_test.go checks it.
+type routes struct {
+	health      *handlers.HealthHandler
+	app         *handlers.ApplicationHandler
+	chat        *handlers.ChatHandler
+	message     *handlers.MessageHandler
+	reaction    *handlers.ReactionHandler
+	ws          *handlers.WebSocketHandler
+	presence    *handlers.PresenceHandler
+	readReceipt *handlers.ReadReceiptHandler
+	search      *handlers.SearchHandler
+	stream      *handlers.StreamHandler
+	streamToken *handlers.StreamTokenHandler
+	usage       *handlers.UsageHandler
+	webhook     *handlers.WebhookHandler
+}
+
+func (rt *routes) register(router *mux.Router, store *config.Store) {
+	// Optional endpoints answer 404 while their feature flag is off
+	reactions := middleware.FeatureMiddleware(store, func(f config.FeaturesConfig) bool { return f.Reactions })
+	searching := middleware.FeatureMiddleware(store, func(f config.FeaturesConfig) bool { return f.Search })
+	streaming := middleware.FeatureMiddleware(store, func(f config.FeaturesConfig) bool { return f.Streaming })
+	presence := middleware.FeatureMiddleware(store, func(f config.FeaturesConfig) bool { return f.Presence })
+	webhooks := middleware.FeatureMiddleware(store, func(f config.FeaturesConfig) bool { return f.Webhooks })
+	
+	router.Handle("/health", rt.health).Methods("GET", "OPTIONS")
+	router.Handle("/debug/vars", expvar.Handler()).Methods("GET")
+	router.Handle("/api-docs", docs.UIHandler()).Methods("GET")
+	router.Handle("/api-docs/openapi.json", docs.SpecHandler()).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/v1/applications", rt.app.Create).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/v1/applications", rt.app.List).Methods("GET")
+	router.HandleFunc("/api/v1/applications/{token}", rt.app.Get).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/v1/applications/{token}", rt.app.Update).Methods("PUT", "PATCH")
+	router.Handle("/api/v1/applications/{token}/usage", rt.usage).Methods("GET", "OPTIONS")
+	router.Handle("/api/v1/chats", rt.chat).Methods("POST", "OPTIONS")
+	router.Handle("/api/v1/messages", rt.message).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/v1/applications/{token}/chats", rt.chat.List).Methods("GET", "OPTIONS")
+	router.Handle("/api/v1/applications/{token}/chats/{chat_number}/read", rt.readReceipt).Methods("PUT", "OPTIONS")
+	
+	messagePath := "/api/v1/applications/{token}/chats/{chat_number}/messages/{number}"
+	router.HandleFunc(messagePath, rt.message.Update).Methods("PATCH", "PUT", "OPTIONS")
+	router.HandleFunc(messagePath, rt.message.Delete).Methods("DELETE")
+	
+	reactionsPath := "/api/v1/applications/{token}/chats/{chat_number}/messages/{number}/reactions"
+	router.Handle(reactionsPath, reactions(http.HandlerFunc(rt.reaction.Add))).Methods("POST", "OPTIONS")
+	router.Handle(reactionsPath, reactions(http.HandlerFunc(rt.reaction.Remove))).Methods("DELETE")
+	
+	router.Handle("/api/v1/stream_tokens", streaming(rt.streamToken)).Methods("POST", "OPTIONS")
+	router.Handle("/api/v1/ws", streaming(rt.ws)).Methods("GET")
+	router.Handle("/api/v1/applications/{token}/chats/{chat_number}/messages/stream", streaming(rt.stream)).Methods("GET", "OPTIONS")
+	router.Handle("/api/v1/applications/{token}/chats/{chat_number}/messages/search", searching(rt.search)).Methods("GET", "OPTIONS")
+	router.Handle("/api/v1/applications/{token}/chats/{chat_number}/presence", presence(rt.presence)).Methods("GET", "OPTIONS")
+	
+	webhooksPath := "/api/v1/applications/{token}/webhooks"
+	router.Handle(webhooksPath, webhooks(http.HandlerFunc(rt.webhook.Create))).Methods("POST", "OPTIONS")
+	router.Handle(webhooksPath, webhooks(http.HandlerFunc(rt.webhook.List))).Methods("GET")
+	router.Handle(webhooksPath+"/{id}", webhooks(http.HandlerFunc(rt.webhook.Delete))).Methods("DELETE", "OPTIONS")
+	router.Handle(webhooksPath+"/{id}/deliveries", webhooks(http.HandlerFunc(rt.webhook.Deliveries))).Methods("GET", "OPTIONS")
+	router.Handle(webhooksPath+"/{id}/deliveries/{delivery_id}", webhooks(http.HandlerFunc(rt.webhook.Delivery))).Methods("GET", "OPTIONS")
+	router.Handle(webhooksPath+"/{id}/deliveries/{delivery_id}/redeliver", webhooks(http.HandlerFunc(rt.webhook.Redeliver))).Methods("POST", "OPTIONS")
+}
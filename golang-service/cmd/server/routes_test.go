@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/docs"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/gorilla/mux"
+)
+
+// TestRoutesDocumented fails when a route is registered without being
+// described in docs/routes.go, or the other way round
+func TestRoutesDocumented(t *testing.T) {
+	router := mux.NewRouter()
+	(&routes{}).register(router, config.NewStore("", config.Defaults()))
+	
+	registered := make(map[string]bool)
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			t.Errorf("%s: route has no methods", path)
+			return nil
+		}
+	
+		for _, method := range methods {
+			// Preflights are answered by CORSMiddleware
+			if method == "OPTIONS" {
+				continue
+			}
+			registered[method+" "+path] = true
+	
+			item, ok := docs.Spec().Paths[path]
+			if !ok || item[strings.ToLower(method)] == nil {
+				t.Errorf("%s %s is registered but missing from the OpenAPI document", method, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	
+	for path, item := range docs.Spec().Paths {
+		for method := range item {
+			if !registered[strings.ToUpper(method)+" "+path] {
+				t.Errorf("%s %s is documented but not registered", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
@@ -0,0 +1,427 @@
+// Package docs builds the OpenAPI 3 document for the Go service. Schemas
+// are generated from the request and response types in internal/models;
+// the operations are listed in routes.go.
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+)
+
+// Document is the subset of an OpenAPI 3.0 document the service uses
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Tags       []Tag                 `json:"tags"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lower-case HTTP methods to operations
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string              `json:"tags"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	// Security is an empty list on public operations
+	Security *[]map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Ref         string               `json:"$ref,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	Responses       map[string]*Response      `json:"responses"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Description string `json:"description,omitempty"`
+}
+
+var (
+	specOnce sync.Once
+	spec     *Document
+	specJSON []byte
+)
+
+// Spec returns the document. It is built once and must not be modified.
+func Spec() *Document {
+	specOnce.Do(func() {
+		spec = build()
+	
+		var err error
+		if specJSON, err = json.MarshalIndent(spec, "", "  "); err != nil {
+			panic(fmt.Sprintf("docs: failed to encode OpenAPI document: %v", err))
+		}
+	})
+	return spec
+}
+
+// JSON returns the encoded document
+func JSON() []byte {
+	Spec()
+	return specJSON
+}
+
+// SpecHandler serves the document at /api-docs/openapi.json
+func SpecHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(JSON())
+	})
+}
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+func build() *Document {
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "Chat Service (Go)",
+			Description: "Write path, real-time and integration API of the chat system. Send the API key in the X-API-Key header; browsers opening streams pass a token from POST /api/v1/stream_tokens as the stream_token query parameter instead.",
+			Version:     "1.0.0",
+		},
+		Tags:  tags,
+		Paths: make(map[string]PathItem),
+		Components: Components{
+			Schemas:   make(map[string]*Schema),
+			Responses: make(map[string]*Response),
+			SecuritySchemes: map[string]SecurityScheme{
+				"ApiKeyAuth": {Type: "apiKey", Name: "X-API-Key", In: "header", Description: "security.master_api_key"},
+				"StreamToken": {Type: "apiKey", Name: "stream_token", In: "query", Description: "Issued by POST /api/v1/stream_tokens; only accepted on WebSocket and event-stream requests"},
+			},
+		},
+		Security: []map[string][]string{{"ApiKeyAuth": {}}},
+	}
+	schemas := newSchemaSet(doc.Components.Schemas)
+	
+	for _, r := range errorResponses {
+		doc.Components.Responses[r.name] = &Response{
+			Description: r.description,
+			Content:     jsonContent(schemas.of(r.model)),
+		}
+	}
+	doc.Components.Responses["TooManyRequests"] = &Response{
+		Description: "Rate limit or quota exceeded. Quota errors name the quota and say when to retry.",
+		Headers: map[string]Header{
+			"Retry-After": {Description: "Seconds until the quota resets", Schema: &Schema{Type: "integer"}},
+		},
+		Content: jsonContent(&Schema{OneOf: []*Schema{
+			schemas.of(models.ErrorResponse{}),
+			schemas.of(models.QuotaErrorResponse{}),
+		}}),
+	}
+	
+	// Payloads that never appear in a response, such as webhook bodies
+	for _, model := range extraModels {
+		schemas.of(model)
+	}
+	
+	for _, op := range operations {
+		item := doc.Paths[op.path]
+		if item == nil {
+			item = make(PathItem)
+			doc.Paths[op.path] = item
+		}
+		item[strings.ToLower(op.method)] = op.build(schemas)
+	}
+	return doc
+}
+
+func (op operation) build(schemas *schemaSet) *Operation {
+	out := &Operation{
+		Tags:        []string{op.tag},
+		Summary:     op.summary,
+		Description: op.description,
+		OperationID: op.id,
+		Responses:   make(map[string]*Response),
+	}
+	if op.feature != "" {
+		out.Description = strings.TrimSpace(out.Description + fmt.Sprintf("\n\nAnswers 404 while features.%s is off.", op.feature))
+	}
+	
+	for _, match := range pathParam.FindAllStringSubmatch(op.path, -1) {
+		param := pathParams[match[1]]
+		param.Name, param.In, param.Required = match[1], "path", true
+		out.Parameters = append(out.Parameters, param)
+	}
+	out.Parameters = append(out.Parameters, op.params...)
+	
+	errs := op.errors
+	if op.request != nil {
+		// Legacy clients may post the same fields as a form, nested ones as parent[child]
+		content := jsonContent(schemas.of(op.request))
+		content["application/x-www-form-urlencoded"] = MediaType{Schema: schemas.of(op.request)}
+		out.RequestBody = &RequestBody{Required: true, Content: content}
+		errs = append(errs, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType)
+	}
+	
+	success := &Response{Description: op.responseDescription}
+	if success.Description == "" {
+		success.Description = http.StatusText(op.status)
+	}
+	switch {
+	case op.contentType != "":
+		success.Content = map[string]MediaType{op.contentType: {Schema: schemas.of(op.response)}}
+	case op.response != nil:
+		success.Content = jsonContent(schemas.of(op.response))
+	}
+	out.Responses[fmt.Sprint(op.status)] = success
+	
+	switch {
+	case op.public:
+		out.Security = &[]map[string][]string{}
+	case op.streamToken:
+		out.Security = &[]map[string][]string{{"ApiKeyAuth": {}}, {"StreamToken": {}}}
+		errs = append(errs, http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable)
+	default:
+		errs = append(errs, http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable)
+	}
+	for _, status := range errs {
+		out.Responses[fmt.Sprint(status)] = &Response{Ref: "#/components/responses/" + errorResponseName(status)}
+	}
+	return out
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+// schemaSet generates component schemas from Go types, keyed by type name
+type schemaSet struct {
+	schemas map[string]*Schema
+}
+
+func newSchemaSet(schemas map[string]*Schema) *schemaSet {
+	return &schemaSet{schemas: schemas}
+}
+
+// of returns a reference to the component schema for v's type
+func (s *schemaSet) of(v any) *Schema {
+	if v == nil {
+		return nil
+	}
+	return s.schema(reflect.TypeOf(v))
+}
+
+func (s *schemaSet) schema(t reflect.Type) *Schema {
+	switch t {
+	case reflect.TypeOf(json.RawMessage{}):
+		return &Schema{Type: "object", Description: "Arbitrary JSON"}
+	case reflect.TypeOf(time.Time{}):
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	
+	switch t.Kind() {
+	case reflect.Pointer:
+		inner := s.schema(t.Elem())
+		if inner.Ref != "" {
+			// Siblings of $ref are ignored in 3.0
+			return &Schema{OneOf: []*Schema{inner}, Nullable: true}
+		}
+		inner.Nullable = true
+		return inner
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+	case reflect.Struct:
+		if _, ok := s.schemas[t.Name()]; !ok {
+			// Reserve the name first so recursive types terminate
+			s.schemas[t.Name()] = &Schema{}
+			*s.schemas[t.Name()] = *s.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	}
+	return &Schema{}
+}
+
+// object lists the JSON fields of a struct, flattening embedded structs the
+// way encoding/json does. Fields are required when their validate tag says
+// so, or when they have no validate tag and are always encoded.
+func (s *schemaSet) object(t reflect.Type) *Schema {
+	out := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+	
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := s.object(field.Type)
+			for key, property := range embedded.Properties {
+				out.Properties[key] = property
+			}
+			out.Required = append(out.Required, embedded.Required...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+	
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer && strings.Contains(options, "omitempty") {
+			// Left out rather than sent as null
+			fieldType = fieldType.Elem()
+		}
+		property := s.schema(fieldType)
+		validate, hasRules := field.Tag.Lookup("validate")
+		required := applyRules(property, validate)
+		if !hasRules {
+			required = !strings.Contains(options, "omitempty")
+		}
+	
+		out.Properties[name] = property
+		if required {
+			out.Required = append(out.Required, name)
+		}
+	}
+	sort.Strings(out.Required)
+	return out
+}
+
+// applyRules copies validate tag rules onto a property and reports whether
+// the field is required. Limits named after a setting are described rather
+// than fixed.
+func applyRules(property *Schema, validate string) bool {
+	required := false
+	for _, rule := range strings.Split(validate, ",") {
+		name, value, _ := strings.Cut(rule, "=")
+		n, err := strconv.Atoi(value)
+		if err != nil && (name == "min" || name == "max" || name == "len") {
+			describe(property, fmt.Sprintf("Length limited by the %s setting.", value))
+			continue
+		}
+	
+		switch {
+		case name == "required":
+			required = true
+			if property.Type == "string" && property.MinLength == nil {
+				property.MinLength = intPtr(1)
+			}
+			if property.Type == "array" && property.MinItems == nil {
+				property.MinItems = intPtr(1)
+			}
+		case name == "url":
+			property.Format = "uri"
+		case name == "hex":
+			property.Pattern = "^[a-f0-9]+$"
+		case name == "nocontrol":
+			describe(property, "Control characters other than tabs and line breaks are rejected.")
+		case name == "oneof" && property.Type == "array":
+			property.Items.Enum = strings.Fields(value)
+		case name == "oneof":
+			property.Enum = strings.Fields(value)
+		case name == "len" && property.Type == "string":
+			property.MinLength, property.MaxLength = &n, &n
+		case name == "min" && property.Type == "string":
+			property.MinLength = &n
+		case name == "max" && property.Type == "string":
+			property.MaxLength = &n
+		case name == "min" && property.Type == "array":
+			property.MinItems = &n
+		case name == "min" && property.Type == "integer":
+			property.Minimum = &n
+		case name == "max" && property.Type == "integer":
+			property.Maximum = &n
+		}
+	}
+	return required
+}
+
+func describe(property *Schema, sentence string) {
+	property.Description = strings.TrimSpace(property.Description + " " + sentence)
+}
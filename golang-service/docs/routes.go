@@ -0,0 +1,351 @@
+package docs
+
+import (
+	"net/http"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+)
+
+// operation describes one route. Path parameters come from the template;
+// 401, 429, 500 and 503 are added to every operation that is not public.
+type operation struct {
+	method      string
+	path        string
+	id          string
+	tag         string
+	summary     string
+	description string
+	// feature names the features flag that turns the route on
+	feature string
+	// public routes skip API key authentication
+	public bool
+	// streamToken routes also accept a token from POST /api/v1/stream_tokens
+	// as the stream_token query parameter
+	streamToken bool
+	params      []Parameter
+	request     any
+	status      int
+	response    any
+	// contentType replaces application/json for the success response
+	contentType         string
+	responseDescription string
+	errors              []int
+}
+
+var tags = []Tag{
+	{Name: "Service", Description: "Health, metrics and documentation"},
+	{Name: "Applications"},
+	{Name: "Chats"},
+	{Name: "Messages"},
+	{Name: "Reactions"},
+	{Name: "Real-time", Description: "WebSocket and Server-Sent Events"},
+	{Name: "Search"},
+	{Name: "Usage"},
+	{Name: "Webhooks"},
+}
+
+var pathParams = map[string]Parameter{
+	"token":       {Description: "Application token", Schema: &Schema{Type: "string", MinLength: intPtr(20), MaxLength: intPtr(20)}},
+	"chat_number": {Description: "Chat number within the application", Schema: &Schema{Type: "integer", Minimum: intPtr(1)}},
+	"number":      {Description: "Message number within the chat", Schema: &Schema{Type: "integer", Minimum: intPtr(1)}},
+	"id":          {Description: "Webhook ID", Schema: &Schema{Type: "integer", Format: "int64", Minimum: intPtr(1)}},
+	"delivery_id": {Description: "Delivery ID", Schema: &Schema{Type: "integer", Format: "int64", Minimum: intPtr(1)}},
+}
+
+var pageParams = []Parameter{
+	{Name: "page", In: "query", Schema: &Schema{Type: "integer", Minimum: intPtr(1), Default: 1}},
+	{Name: "per_page", In: "query", Schema: &Schema{Type: "integer", Minimum: intPtr(1), Maximum: intPtr(100), Default: 50}},
+}
+
+var errorResponses = []struct {
+	status      int
+	name        string
+	description string
+	model       any
+}{
+	{http.StatusBadRequest, "BadRequest", "Malformed body or invalid parameter. Body validation lists every failing field in errors.", models.ErrorResponse{}},
+	{http.StatusUnauthorized, "Unauthorized", "Missing or wrong API key", models.ErrorResponse{}},
+	{http.StatusForbidden, "QuotaExceeded", "The application's plan does not allow more", models.QuotaErrorResponse{}},
+	{http.StatusNotFound, "NotFound", "Application, chat, message or webhook not found", models.ErrorResponse{}},
+	{http.StatusConflict, "Conflict", "The resource already exists", models.ErrorResponse{}},
+	{http.StatusRequestEntityTooLarge, "PayloadTooLarge", "The body is larger than security.max_body_bytes", models.ErrorResponse{}},
+	{http.StatusUnsupportedMediaType, "UnsupportedMediaType", "Content-Type is neither application/json nor application/x-www-form-urlencoded", models.ErrorResponse{}},
+	{http.StatusUnprocessableEntity, "UnprocessableEntity", "The request is well-formed but cannot be applied", models.ErrorResponse{}},
+	{http.StatusInternalServerError, "InternalError", "Unexpected database or Redis failure", models.ErrorResponse{}},
+	{http.StatusServiceUnavailable, "ServiceUnavailable", "Still connecting to MySQL and Redis, or search is down", models.ErrorResponse{}},
+}
+
+func errorResponseName(status int) string {
+	if status == http.StatusTooManyRequests {
+		return "TooManyRequests"
+	}
+	for _, r := range errorResponses {
+		if r.status == status {
+			return r.name
+		}
+	}
+	return ""
+}
+
+// extraModels are documented although no route returns them
+var extraModels = []any{
+	models.WebhookEvent{},
+	models.SubscriptionRequest{},
+}
+
+const (
+	messagePath   = "/api/v1/applications/{token}/chats/{chat_number}/messages/{number}"
+	reactionsPath = messagePath + "/reactions"
+	webhooksPath  = "/api/v1/applications/{token}/webhooks"
+)
+
+var operations = []operation{
+	{
+		method: "GET", path: "/health", id: "getHealth", tag: "Service",
+		summary:     "Check MySQL and Redis",
+		description: "Answers 503 with the same body while a dependency is down or the service is still starting.",
+		public:      true,
+		status:      http.StatusOK, response: models.HealthResponse{},
+	},
+	{
+		method: "GET", path: "/debug/vars", id: "getMetrics", tag: "Service",
+		summary: "Runtime and service metrics (expvar)",
+		status:  http.StatusOK, response: map[string]any{},
+	},
+	{
+		method: "GET", path: "/api-docs", id: "getAPIDocsUI", tag: "Service",
+		summary: "Interactive API documentation",
+		public:  true,
+		status:  http.StatusOK, response: "", contentType: "text/html",
+	},
+	{
+		method: "GET", path: "/api-docs/openapi.json", id: "getOpenAPI", tag: "Service",
+		summary: "This document",
+		public:  true,
+		status:  http.StatusOK, response: map[string]any{},
+	},
+	
+	// Applications
+	{
+		method: "POST", path: "/api/v1/applications", id: "createApplication", tag: "Applications",
+		summary:     "Create an application",
+		description: `The Rails-style body {"application": {"name": ...}} is accepted too.`,
+		request:     models.ApplicationRequest{},
+		status:      http.StatusCreated, response: models.ApplicationResponse{},
+		errors:      []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+	},
+	{
+		method: "GET", path: "/api/v1/applications", id: "listApplications", tag: "Applications",
+		summary: "List applications",
+		params:  pageParams,
+		status:  http.StatusOK, response: models.ApplicationListResponse{},
+		errors:  []int{http.StatusBadRequest},
+	},
+	{
+		method: "GET", path: "/api/v1/applications/{token}", id: "getApplication", tag: "Applications",
+		summary: "Get an application",
+		status:  http.StatusOK, response: models.ApplicationResponse{},
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: "PUT", path: "/api/v1/applications/{token}", id: "updateApplication", tag: "Applications",
+		summary: "Rename an application",
+		request: models.ApplicationRequest{},
+		status:  http.StatusOK, response: models.ApplicationResponse{},
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity},
+	},
+	{
+		method: "PATCH", path: "/api/v1/applications/{token}", id: "patchApplication", tag: "Applications",
+		summary: "Rename an application",
+		request: models.ApplicationRequest{},
+		status:  http.StatusOK, response: models.ApplicationResponse{},
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity},
+	},
+	{
+		method: "GET", path: "/api/v1/applications/{token}/usage", id: "getUsage", tag: "Usage",
+		summary:     "Hourly usage of an application",
+		description: "The range may span at most 31 days.",
+		params: []Parameter{
+			{Name: "from", In: "query", Description: "RFC 3339 time or YYYY-MM-DD; defaults to 24 hours before to", Schema: &Schema{Type: "string"}},
+			{Name: "to", In: "query", Description: "RFC 3339 time or YYYY-MM-DD, rounded up to the hour; defaults to the end of the current hour", Schema: &Schema{Type: "string"}},
+		},
+		status: http.StatusOK, response: models.UsageResponse{},
+		errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	
+	// Chats
+	{
+		method: "POST", path: "/api/v1/chats", id: "createChat", tag: "Chats",
+		summary: "Create a chat",
+		request: models.ChatCreateRequest{},
+		status:  http.StatusCreated, response: models.ChatResponse{},
+		errors:  []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: "GET", path: "/api/v1/applications/{token}/chats", id: "listChats", tag: "Chats",
+		summary: "List an application's chats",
+		params: append([]Parameter{
+			{Name: "user_id", In: "query", Description: "Adds this user's read marker and unread count to each chat", Schema: &Schema{Type: "string"}},
+		}, pageParams...),
+		status: http.StatusOK, response: models.ChatListResponse{},
+		errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: "PUT", path: "/api/v1/applications/{token}/chats/{chat_number}/read", id: "markChatRead", tag: "Chats",
+		summary: "Move a user's read marker",
+		request: models.ReadReceiptRequest{},
+		status:  http.StatusOK, response: models.ReadReceiptResponse{},
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity},
+	},
+	
+	// Messages
+	{
+		method: "POST", path: "/api/v1/messages", id: "createMessage", tag: "Messages",
+		summary: "Create a message",
+		request: models.MessageCreateRequest{},
+		status:  http.StatusCreated, response: models.MessageResponse{},
+		errors:  []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: "PATCH", path: messagePath, id: "updateMessage", tag: "Messages",
+		summary: "Edit a message",
+		request: models.MessageUpdateRequest{},
+		status:  http.StatusOK, response: models.MessageResponse{},
+		errors:  []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: "PUT", path: messagePath, id: "replaceMessage", tag: "Messages",
+		summary: "Edit a message",
+		request: models.MessageUpdateRequest{},
+		status:  http.StatusOK, response: models.MessageResponse{},
+		errors:  []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: "DELETE", path: messagePath, id: "deleteMessage", tag: "Messages",
+		summary: "Delete a message",
+		status:  http.StatusNoContent,
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	
+	// Reactions
+	{
+		method: "POST", path: reactionsPath, id: "addReaction", tag: "Reactions",
+		summary: "React to a message",
+		feature: "reactions",
+		request: models.ReactionRequest{},
+		status:  http.StatusCreated, response: models.MessageResponse{},
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+	},
+	{
+		method: "DELETE", path: reactionsPath, id: "removeReaction", tag: "Reactions",
+		summary: "Remove a reaction",
+		feature: "reactions",
+		request: models.ReactionRequest{},
+		status:  http.StatusOK, response: models.MessageResponse{},
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	
+	// Real-time
+	{
+		method: "POST", path: "/api/v1/stream_tokens", id: "createStreamToken", tag: "Real-time",
+		summary:     "Issue a token for opening a stream",
+		description: "Browsers cannot send X-API-Key when opening a WebSocket or EventSource, so they pass this token as the stream_token query parameter instead. It expires after a minute; an open stream stays open.",
+		feature:     "streaming",
+		status:      http.StatusCreated, response: models.StreamTokenResponse{},
+	},
+	{
+		method: "GET", path: "/api/v1/ws", id: "openWebSocket", tag: "Real-time",
+		summary:             "Subscribe to chats over a WebSocket",
+		description:         "Send SubscriptionRequest frames (action subscribe, unsubscribe, heartbeat, typing, stop_typing or leave) and receive ChatEvent frames for the subscribed chats.",
+		feature:             "streaming",
+		streamToken:         true,
+		status:              http.StatusSwitchingProtocols,
+		responseDescription: "Upgraded to a WebSocket",
+	},
+	{
+		method: "GET", path: "/api/v1/applications/{token}/chats/{chat_number}/messages/stream", id: "streamMessages", tag: "Real-time",
+		summary:     "Stream a chat's messages as Server-Sent Events",
+		description: "Each event's data is a ChatEvent. Message creations carry the message number as the event ID, so reconnecting clients resume after the last one seen. Without a resume point the stream starts from now.",
+		feature:     "streaming",
+		streamToken: true,
+		params: []Parameter{
+			{Name: "Last-Event-ID", In: "header", Description: "Replay messages after this number; omit to receive only new events", Schema: &Schema{Type: "integer", Minimum: intPtr(0)}},
+			{Name: "last_event_id", In: "query", Description: "Same as Last-Event-ID, for clients that cannot set headers", Schema: &Schema{Type: "integer", Minimum: intPtr(0)}},
+		},
+		status: http.StatusOK, response: models.ChatEvent{}, contentType: "text/event-stream",
+		errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: "GET", path: "/api/v1/applications/{token}/chats/{chat_number}/presence", id: "getPresence", tag: "Real-time",
+		summary: "Users online and typing in a chat",
+		feature: "presence",
+		status:  http.StatusOK, response: models.PresenceResponse{},
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	
+	// Search
+	{
+		method: "GET", path: "/api/v1/applications/{token}/chats/{chat_number}/messages/search", id: "searchMessages", tag: "Search",
+		summary: "Search a chat's messages",
+		feature: "search",
+		params: append([]Parameter{
+			{Name: "q", In: "query", Required: true, Schema: &Schema{Type: "string"}},
+			{Name: "mode", In: "query", Schema: &Schema{Type: "string", Enum: []string{"natural", "boolean"}, Default: "natural"}},
+		}, pageParams...),
+		status: http.StatusOK, response: models.SearchResponse{},
+		errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	
+	// Webhooks
+	{
+		method: "POST", path: webhooksPath, id: "createWebhook", tag: "Webhooks",
+		summary:     "Register a webhook endpoint",
+		description: "The response carries the signing secret; it is not returned again. Deliveries are WebhookEvent bodies signed with X-Webhook-Signature.",
+		feature:     "webhooks",
+		request:     models.WebhookEndpointRequest{},
+		status:      http.StatusCreated, response: models.WebhookEndpointResponse{},
+		errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: "GET", path: webhooksPath, id: "listWebhooks", tag: "Webhooks",
+		summary: "List webhook endpoints",
+		feature: "webhooks",
+		status:  http.StatusOK, response: models.WebhookEndpointListResponse{},
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: "DELETE", path: webhooksPath + "/{id}", id: "deleteWebhook", tag: "Webhooks",
+		summary: "Delete a webhook endpoint",
+		feature: "webhooks",
+		status:  http.StatusNoContent,
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: "GET", path: webhooksPath + "/{id}/deliveries", id: "listWebhookDeliveries", tag: "Webhooks",
+		summary: "List an endpoint's deliveries, newest first",
+		feature: "webhooks",
+		params:  pageParams,
+		status:  http.StatusOK, response: models.WebhookDeliveryListResponse{},
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: "GET", path: webhooksPath + "/{id}/deliveries/{delivery_id}", id: "getWebhookDelivery", tag: "Webhooks",
+		summary:     "Get a delivery",
+		description: "Includes the payload and the log of every attempt.",
+		feature:     "webhooks",
+		status:      http.StatusOK, response: models.WebhookDeliveryResponse{},
+		errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: "POST", path: webhooksPath + "/{id}/deliveries/{delivery_id}/redeliver", id: "redeliverWebhook", tag: "Webhooks",
+		summary:     "Queue a delivery again",
+		description: "Creates a new delivery of the same event, with the same event ID.",
+		feature:     "webhooks",
+		status:      http.StatusAccepted, response: models.WebhookDeliveryResponse{},
+		errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+}
+
+func intPtr(n int) *int {
+	return &n
+}
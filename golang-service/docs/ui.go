@@ -0,0 +1,48 @@
+package docs
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+)
+
+// swaggerUI is loaded from a CDN so the binary carries no frontend assets
+const swaggerUI = "https://unpkg.com/swagger-ui-dist@5.17.14"
+
+const uiPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Chat Service (Go) API</title>
+  <link rel="stylesheet" href="%[1]s/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="%[1]s/swagger-ui-bundle.js"></script>
+  <script nonce="%[2]s">
+    SwaggerUIBundle({url: "/api-docs/openapi.json", dom_id: "#swagger-ui", persistAuthorization: true});
+  </script>
+</body>
+</html>
+`
+
+// UIHandler serves Swagger UI for the document at /api-docs
+func UIHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			http.Error(w, "failed to render page", http.StatusInternalServerError)
+			return
+		}
+		nonce := base64.StdEncoding.EncodeToString(buf)
+	
+		// Relax the default-src 'self' policy just enough for the UI
+		w.Header().Set("Content-Security-Policy", fmt.Sprintf(
+			"default-src 'self'; script-src 'nonce-%s' %s/; style-src %s/; img-src 'self' data:",
+			nonce, swaggerUI, swaggerUI,
+		))
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, uiPage, swaggerUI, nonce)
+	})
+}
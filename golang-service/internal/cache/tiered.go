@@ -0,0 +1,233 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/metrics"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// invalidationChannel carries keys dropped on one replica to all others
+const invalidationChannel = "cache:invalidate"
+
+// notFoundMarker is what Redis holds for a negatively cached key
+const notFoundMarker = "!"
+
+// Options tunes a Tiered cache
+type Options struct {
+	LocalSize   int
+	LocalTTL    time.Duration
+	RedisTTL    time.Duration
+	NegativeTTL time.Duration
+}
+
+// localEntry is a cached value or a remembered miss
+type localEntry[V any] struct {
+	value V
+	found bool
+}
+
+// Tiered caches lookups in process and in Redis. Concurrent misses for the
+// same key share one load, and loads that fail with the notFound error are
+// cached for NegativeTTL so unknown keys cannot hammer MySQL.
+type Tiered[V any] struct {
+	name     string
+	redis    *redis.Client
+	local    *LRU[localEntry[V]]
+	opts     Options
+	notFound error
+	group    singleflight.Group
+	
+	// generation moves on every invalidation so a load that raced one
+	// does not write its stale result back
+	generation atomic.Uint64
+}
+
+func NewTiered[V any](name string, redisClient *redis.Client, notFound error, opts Options) *Tiered[V] {
+	return &Tiered[V]{
+		name:     name,
+		redis:    redisClient,
+		local:    NewLRU[localEntry[V]](opts.LocalSize),
+		opts:     opts,
+		notFound: notFound,
+	}
+}
+
+func (t *Tiered[V]) redisKey(key string) string {
+	return fmt.Sprintf("cache:%s:%s", t.name, key)
+}
+
+// Get returns the value for key, calling load only when neither tier has it
+func (t *Tiered[V]) Get(ctx context.Context, key string, load func() (V, error)) (V, error) {
+	if entry, ok := t.local.Get(key); ok {
+		t.count("local_hit")
+		return t.result(entry)
+	}
+	
+	v, err, _ := t.group.Do(key, func() (interface{}, error) {
+		generation := t.generation.Load()
+		
+		if entry, ok := t.fromRedis(ctx, key); ok {
+			t.count("redis_hit")
+			t.store(key, entry, generation, false)
+			return entry, nil
+		}
+		
+		t.count("miss")
+		value, err := load()
+		if errors.Is(err, t.notFound) {
+			entry := localEntry[V]{}
+			t.store(key, entry, generation, true)
+			return entry, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		
+		entry := localEntry[V]{value: value, found: true}
+		t.store(key, entry, generation, true)
+		return entry, nil
+	})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	
+	return t.result(v.(localEntry[V]))
+}
+
+func (t *Tiered[V]) result(entry localEntry[V]) (V, error) {
+	if !entry.found {
+		var zero V
+		return zero, t.notFound
+	}
+	return entry.value, nil
+}
+
+func (t *Tiered[V]) fromRedis(ctx context.Context, key string) (localEntry[V], bool) {
+	raw, err := t.redis.Get(ctx, t.redisKey(key)).Result()
+	if err != nil {
+		if err != redis.Nil {
+			log.Printf("Warning: Cache read failed for %s: %v", t.redisKey(key), err)
+		}
+		return localEntry[V]{}, false
+	}
+	
+	if raw == notFoundMarker {
+		return localEntry[V]{}, true
+	}
+	
+	var value V
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		log.Printf("Warning: Discarding undecodable cache entry %s: %v", t.redisKey(key), err)
+		return localEntry[V]{}, false
+	}
+	return localEntry[V]{value: value, found: true}, true
+}
+
+// store fills the local tier and, for freshly loaded entries, Redis. It is
+// skipped when an invalidation happened after the load started.
+func (t *Tiered[V]) store(key string, entry localEntry[V], generation uint64, writeRedis bool) {
+	if t.generation.Load() != generation {
+		return
+	}
+	
+	localTTL, redisTTL := t.opts.LocalTTL, t.opts.RedisTTL
+	if !entry.found {
+		localTTL, redisTTL = t.opts.NegativeTTL, t.opts.NegativeTTL
+	}
+	t.local.Set(key, entry, localTTL)
+	
+	if !writeRedis {
+		return
+	}
+	
+	payload := notFoundMarker
+	if entry.found {
+		encoded, err := json.Marshal(entry.value)
+		if err != nil {
+			log.Printf("Warning: Failed to encode cache entry %s: %v", t.redisKey(key), err)
+			return
+		}
+		payload = string(encoded)
+	}
+	
+	if err := t.redis.Set(context.Background(), t.redisKey(key), payload, redisTTL).Err(); err != nil {
+		log.Printf("Warning: Cache write failed for %s: %v", t.redisKey(key), err)
+	}
+}
+
+// Invalidate drops keys from both tiers here and from the local tier of
+// every other replica
+func (t *Tiered[V]) Invalidate(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	
+	t.generation.Add(1)
+	redisKeys := make([]string, len(keys))
+	for i, key := range keys {
+		t.local.Delete(key)
+		redisKeys[i] = t.redisKey(key)
+	}
+	
+	if err := t.redis.Del(ctx, redisKeys...).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate cache: %w", err)
+	}
+	
+	pipe := t.redis.Pipeline()
+	for _, redisKey := range redisKeys {
+		pipe.Publish(ctx, invalidationChannel, redisKey)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to broadcast cache invalidation: %w", err)
+	}
+	
+	return nil
+}
+
+// Run evicts keys invalidated by other replicas until ctx is cancelled
+func (t *Tiered[V]) Run(ctx context.Context) {
+	pubsub := t.redis.Subscribe(ctx, invalidationChannel)
+	defer pubsub.Close()
+	
+	prefix := t.redisKey("")
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			if key, ok := strings.CutPrefix(msg.Payload, prefix); ok {
+				t.generation.Add(1)
+				t.local.Delete(key)
+			}
+		}
+	}
+}
+
+func (t *Tiered[V]) count(outcome string) {
+	metrics.CacheLookups.Add(t.name+"."+outcome, 1)
+}
+
+// OptionsFrom maps the service configuration onto cache options
+func OptionsFrom(cfg config.CacheConfig) Options {
+	return Options{
+		LocalSize:   cfg.LocalSize,
+		LocalTTL:    cfg.LocalTTL,
+		RedisTTL:    cfg.RedisTTL,
+		NegativeTTL: cfg.NegativeTTL,
+	}
+}
@@ -1,65 +1,384 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
-// Config holds all configuration for the service
+// Config holds all configuration for the service. Values are layered:
+// built-in defaults, then the YAML file named by CONFIG_FILE (or -config),
+// then environment variables, then *_FILE secret files.
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Redis     RedisConfig     `yaml:"redis"`
+	Security  SecurityConfig  `yaml:"security"`
+	CORS      CORSConfig      `yaml:"cors"`
+	Reactions ReactionsConfig `yaml:"reactions"`
+	Usage     UsageConfig     `yaml:"usage"`
+	Webhooks  WebhooksConfig  `yaml:"webhooks"`
+	Search    SearchConfig    `yaml:"search"`
+	Counters  CountersConfig  `yaml:"counters"`
+	Cache     CacheConfig     `yaml:"cache"`
+	Startup   StartupConfig   `yaml:"startup"`
+	Logging   LoggingConfig   `yaml:"logging"`
+	Messages  MessagesConfig  `yaml:"messages"`
+	Features  FeaturesConfig  `yaml:"features"`
+	Quotas    QuotasConfig    `yaml:"quotas"`
 }
 
 type ServerConfig struct {
-	Port string
-	Env  string
+	Port string `yaml:"port"`
+	// GRPCPort serves the gRPC API; empty turns it off
+	GRPCPort string `yaml:"grpc_port"`
+	Env      string `yaml:"env"`
 }
 
 type DatabaseConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	Database string
-	MaxConns int
+	Host        string `yaml:"host"`
+	Port        int    `yaml:"port"`
+	User        string `yaml:"user"`
+	Password    string `yaml:"password"`
+	Database    string `yaml:"name"`
+	MaxConns    int    `yaml:"max_conns"`
+	AutoMigrate bool   `yaml:"auto_migrate"`
 }
 
 type RedisConfig struct {
-	Host     string
-	Port     int
-	Password string
-	DB       int
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Password string `yaml:"password"`
+	DB       int    `yaml:"db"`
 }
 
-// Load reads configuration from environment variables
-func Load() (*Config, error) {
-	cfg := &Config{
+// SecurityConfig drives the HTTP middleware
+type SecurityConfig struct {
+	MasterAPIKey    string  `yaml:"master_api_key"`
+	SkipAPIKeyCheck bool    `yaml:"skip_api_key_check"`
+	RateLimit       float64 `yaml:"rate_limit"`
+	RateBurst       int     `yaml:"rate_burst"`
+	MaxBodyBytes    int64   `yaml:"max_body_bytes"`
+	// StrictJSON rejects request bodies with fields the endpoint does not know
+	StrictJSON bool `yaml:"strict_json"`
+}
+
+// CORSPolicy says which cross-origin requests a route accepts. Origins are
+// exact (https://app.example.com), * for any, or a wildcard subdomain
+// pattern (https://*.example.com).
+type CORSPolicy struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins"`
+	AllowedMethods   []string      `yaml:"allowed_methods"`
+	AllowedHeaders   []string      `yaml:"allowed_headers"`
+	ExposedHeaders   []string      `yaml:"exposed_headers"`
+	AllowCredentials bool          `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age"`
+}
+
+// CORSConfig is the default policy plus per-route overrides
+type CORSConfig struct {
+	CORSPolicy `yaml:",inline"`
+	Routes     []CORSRoute `yaml:"routes"`
+}
+
+// CORSRoute overrides the default policy for paths under PathPrefix. Empty
+// lists and a zero max_age inherit the default policy.
+type CORSRoute struct {
+	PathPrefix string `yaml:"path_prefix"`
+	CORSPolicy `yaml:",inline"`
+}
+
+// RoutePolicy returns the policy route applies: its own policy with the
+// empty fields filled from the default policy
+func (c CORSConfig) RoutePolicy(route CORSRoute) CORSPolicy {
+	policy := route.CORSPolicy
+	if len(policy.AllowedOrigins) == 0 {
+		policy.AllowedOrigins = c.AllowedOrigins
+	}
+	if len(policy.AllowedMethods) == 0 {
+		policy.AllowedMethods = c.AllowedMethods
+	}
+	if len(policy.AllowedHeaders) == 0 {
+		policy.AllowedHeaders = c.AllowedHeaders
+	}
+	if len(policy.ExposedHeaders) == 0 {
+		policy.ExposedHeaders = c.ExposedHeaders
+	}
+	if policy.MaxAge == 0 {
+		policy.MaxAge = c.MaxAge
+	}
+	return policy
+}
+
+type ReactionsConfig struct {
+	FlushInterval time.Duration `yaml:"flush_interval"`
+}
+
+type UsageConfig struct {
+	FlushInterval time.Duration `yaml:"flush_interval"`
+}
+
+// WebhooksConfig tunes webhook delivery. Failed attempts are retried with
+// exponential backoff from RetryInitial up to RetryMax until MaxAttempts.
+type WebhooksConfig struct {
+	MaxAttempts  int           `yaml:"max_attempts"`
+	RetryInitial time.Duration `yaml:"retry_initial"`
+	RetryMax     time.Duration `yaml:"retry_max"`
+	Timeout      time.Duration `yaml:"timeout"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+	Concurrency  int           `yaml:"concurrency"`
+	// AllowPrivateTargets lets endpoints resolve to loopback and private
+	// addresses, for local development only
+	AllowPrivateTargets bool `yaml:"allow_private_targets"`
+}
+
+type CountersConfig struct {
+	ReconcileEnabled  bool          `yaml:"reconcile_enabled"`
+	ReconcileInterval time.Duration `yaml:"reconcile_interval"`
+}
+
+// StartupConfig controls how long the service waits for MySQL and Redis
+type StartupConfig struct {
+	RetryInitial time.Duration `yaml:"retry_initial"`
+	RetryMax     time.Duration `yaml:"retry_max"`
+	MaxWait      time.Duration `yaml:"max_wait"`
+	Lazy         bool          `yaml:"lazy"`
+}
+
+type CacheConfig struct {
+	Enabled     bool          `yaml:"enabled"`
+	LocalSize   int           `yaml:"local_size"`
+	LocalTTL    time.Duration `yaml:"local_ttl"`
+	RedisTTL    time.Duration `yaml:"redis_ttl"`
+	NegativeTTL time.Duration `yaml:"negative_ttl"`
+}
+
+type LoggingConfig struct {
+	Level string `yaml:"level"`
+}
+
+type MessagesConfig struct {
+	MaxBodyLength int `yaml:"max_body_length"`
+}
+
+// FeaturesConfig switches optional endpoints on and off
+type FeaturesConfig struct {
+	Reactions bool `yaml:"reactions"`
+	Search    bool `yaml:"search"`
+	Streaming bool `yaml:"streaming"`
+	Presence  bool `yaml:"presence"`
+	Webhooks  bool `yaml:"webhooks"`
+}
+
+// PlanLimits are the quotas of one tier. Zero means unlimited.
+type PlanLimits struct {
+	MaxChats           int64 `yaml:"max_chats"`
+	MaxMessagesPerChat int64 `yaml:"max_messages_per_chat"`
+	MessagesPerDay     int64 `yaml:"messages_per_day"`
+	MaxBodyBytes       int   `yaml:"max_body_bytes"`
+}
+
+// QuotasConfig names the plans applications can be assigned to. Apps
+// without an assignment get DefaultPlan.
+type QuotasConfig struct {
+	DefaultPlan string                `yaml:"default_plan"`
+	Plans       map[string]PlanLimits `yaml:"plans"`
+}
+
+type SearchConfig struct {
+	Backend            string `yaml:"backend"`
+	ElasticsearchURL   string `yaml:"elasticsearch_url"`
+	ElasticsearchIndex string `yaml:"elasticsearch_index"`
+	IndexDir           string `yaml:"index_dir"`
+}
+
+// Defaults returns the configuration used when nothing overrides it
+func Defaults() *Config {
+	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
-			Env:  getEnv("ENV", "development"),
+			Port:     "8080",
+			GRPCPort: "9090",
+			Env:      "development",
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnvInt("DB_PORT", 3307),
-			User:     getEnv("DB_USER", "root"),
-			Password: getEnv("DB_PASSWORD", "rootpassword"),
-			Database: getEnv("DB_NAME", "chat_system_development"),
-			MaxConns: getEnvInt("DB_MAX_CONNS", 25),
+			Host:        "localhost",
+			Port:        3307,
+			User:        "root",
+			Password:    "rootpassword",
+			Database:    "chat_system_development",
+			MaxConns:    25,
+			AutoMigrate: false,
 		},
 		Redis: RedisConfig{
-			Host:     getEnv("REDIS_HOST", "localhost"),
-			Port:     getEnvInt("REDIS_PORT", 6379),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       getEnvInt("REDIS_DB", 0),
+			Host:     "localhost",
+			Port:     6379,
+			Password: "",
+			DB:       0,
+		},
+		Security: SecurityConfig{
+			RateLimit:    1,
+			RateBurst:    10,
+			MaxBodyBytes: 1 << 20,
+		},
+		CORS: CORSConfig{
+			CORSPolicy: CORSPolicy{
+				AllowedOrigins: []string{"*"},
+				AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+				AllowedHeaders: []string{"Content-Type", "X-API-Key", "Authorization", "Idempotency-Key", "Last-Event-ID"},
+				MaxAge:         24 * time.Hour,
+			},
+		},
+		Reactions: ReactionsConfig{
+			FlushInterval: time.Minute,
+		},
+		Usage: UsageConfig{
+			FlushInterval: time.Minute,
+		},
+		Webhooks: WebhooksConfig{
+			MaxAttempts:  8,
+			RetryInitial: 30 * time.Second,
+			RetryMax:     6 * time.Hour,
+			Timeout:      10 * time.Second,
+			PollInterval: 5 * time.Second,
+			Concurrency:  8,
+		},
+		Search: SearchConfig{
+			Backend:            "mysql",
+			ElasticsearchURL:   "http://localhost:9200",
+			ElasticsearchIndex: "messages",
+			IndexDir:           "data/search-index",
+		},
+		Counters: CountersConfig{
+			ReconcileEnabled:  false,
+			ReconcileInterval: time.Hour,
+		},
+		Cache: CacheConfig{
+			Enabled:     true,
+			LocalSize:   10000,
+			LocalTTL:    30 * time.Second,
+			RedisTTL:    5 * time.Minute,
+			NegativeTTL: 5 * time.Second,
+		},
+		Startup: StartupConfig{
+			RetryInitial: 500 * time.Millisecond,
+			RetryMax:     10 * time.Second,
+			MaxWait:      2 * time.Minute,
+			Lazy:         false,
+		},
+		Logging: LoggingConfig{
+			Level: "info",
+		},
+		Messages: MessagesConfig{
+			MaxBodyLength: 5000,
+		},
+		Features: FeaturesConfig{
+			Reactions: true,
+			Search:    true,
+			Streaming: true,
+			Presence:  true,
+			Webhooks:  true,
+		},
+		Quotas: QuotasConfig{
+			DefaultPlan: "unlimited",
+			Plans: map[string]PlanLimits{
+				"unlimited": {},
+				"free": {
+					MaxChats:           10,
+					MaxMessagesPerChat: 1000,
+					MessagesPerDay:     1000,
+					MaxBodyBytes:       2000,
+				},
+				"pro": {
+					MaxChats:           1000,
+					MaxMessagesPerChat: 100000,
+					MessagesPerDay:     100000,
+					MaxBodyBytes:       5000,
+				},
+			},
 		},
 	}
+}
+
+// Load reads the file named by CONFIG_FILE, if any, and the environment
+func Load() (*Config, error) {
+	return LoadFile(os.Getenv("CONFIG_FILE"))
+}
+
+// LoadFile layers path (optional), environment variables and secret files
+// over the defaults. Every problem found is reported in one error.
+func LoadFile(path string) (*Config, error) {
+	return load(path, false)
+}
+
+// LoadServer is LoadFile plus the checks that only matter to the HTTP
+// server, such as requiring an API key
+func LoadServer(path string) (*Config, error) {
+	return load(path, true)
+}
 
+func load(path string, serving bool) (*Config, error) {
+	cfg := Defaults()
+	
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		
+		// Unknown keys are almost always typos, so reject them
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	}
+	
+	env := &envLoader{}
+	env.apply(cfg)
+	
+	errs := append(env.errs, cfg.Validate()...)
+	if serving {
+		errs = append(errs, cfg.ValidateServer()...)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	
 	return cfg, nil
 }
 
+// Redacted returns a copy safe to print, with secrets masked
+func (c *Config) Redacted() *Config {
+	copied := *c
+	copied.Database.Password = redact(c.Database.Password)
+	copied.Redis.Password = redact(c.Redis.Password)
+	copied.Security.MasterAPIKey = redact(c.Security.MasterAPIKey)
+	return &copied
+}
+
+// YAML renders the configuration in the config file format
+func (c *Config) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), encoder.Close()
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "[REDACTED]"
+}
+
 // DSN returns MySQL Data Source Name
 func (c *Config) DSN() string {
 	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&charset=utf8mb4",
@@ -75,19 +394,3 @@ func (c *Config) DSN() string {
 func (c *Config) RedisAddr() string {
 	return fmt.Sprintf("%s:%d", c.Redis.Host, c.Redis.Port)
 }
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intVal, err := strconv.Atoi(value); err == nil {
-			return intVal
-		}
-	}
-	return defaultValue
-}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envLoader overrides config values from environment variables, collecting
+// parse errors instead of silently keeping the previous value
+type envLoader struct {
+	errs []error
+}
+
+func (l *envLoader) apply(cfg *Config) {
+	l.string("PORT", &cfg.Server.Port)
+	l.string("GRPC_PORT", &cfg.Server.GRPCPort)
+	l.string("ENV", &cfg.Server.Env)
+	
+	l.string("DB_HOST", &cfg.Database.Host)
+	l.int("DB_PORT", &cfg.Database.Port)
+	l.string("DB_USER", &cfg.Database.User)
+	l.secret("DB_PASSWORD", &cfg.Database.Password)
+	l.string("DB_NAME", &cfg.Database.Database)
+	l.int("DB_MAX_CONNS", &cfg.Database.MaxConns)
+	l.bool("DB_AUTO_MIGRATE", &cfg.Database.AutoMigrate)
+	
+	l.string("REDIS_HOST", &cfg.Redis.Host)
+	l.int("REDIS_PORT", &cfg.Redis.Port)
+	l.secret("REDIS_PASSWORD", &cfg.Redis.Password)
+	l.int("REDIS_DB", &cfg.Redis.DB)
+	
+	l.secret("MASTER_API_KEY", &cfg.Security.MasterAPIKey)
+	l.bool("SKIP_API_KEY_CHECK", &cfg.Security.SkipAPIKeyCheck)
+	l.float("RATE_LIMIT", &cfg.Security.RateLimit)
+	l.int("RATE_BURST", &cfg.Security.RateBurst)
+	l.int64("MAX_BODY_BYTES", &cfg.Security.MaxBodyBytes)
+	l.bool("STRICT_JSON", &cfg.Security.StrictJSON)
+	
+	l.list("ALLOWED_ORIGINS", &cfg.CORS.AllowedOrigins)
+	l.list("CORS_ALLOWED_METHODS", &cfg.CORS.AllowedMethods)
+	l.list("CORS_ALLOWED_HEADERS", &cfg.CORS.AllowedHeaders)
+	l.list("CORS_EXPOSED_HEADERS", &cfg.CORS.ExposedHeaders)
+	l.bool("CORS_ALLOW_CREDENTIALS", &cfg.CORS.AllowCredentials)
+	l.duration("CORS_MAX_AGE", &cfg.CORS.MaxAge)
+	
+	l.duration("REACTION_FLUSH_INTERVAL", &cfg.Reactions.FlushInterval)
+	
+	l.duration("USAGE_FLUSH_INTERVAL", &cfg.Usage.FlushInterval)
+	
+	l.int("WEBHOOK_MAX_ATTEMPTS", &cfg.Webhooks.MaxAttempts)
+	l.duration("WEBHOOK_RETRY_INITIAL", &cfg.Webhooks.RetryInitial)
+	l.duration("WEBHOOK_RETRY_MAX", &cfg.Webhooks.RetryMax)
+	l.duration("WEBHOOK_TIMEOUT", &cfg.Webhooks.Timeout)
+	l.duration("WEBHOOK_POLL_INTERVAL", &cfg.Webhooks.PollInterval)
+	l.int("WEBHOOK_CONCURRENCY", &cfg.Webhooks.Concurrency)
+	l.bool("WEBHOOK_ALLOW_PRIVATE_TARGETS", &cfg.Webhooks.AllowPrivateTargets)
+	
+	l.string("SEARCH_BACKEND", &cfg.Search.Backend)
+	l.string("ELASTICSEARCH_URL", &cfg.Search.ElasticsearchURL)
+	l.string("ELASTICSEARCH_INDEX", &cfg.Search.ElasticsearchIndex)
+	l.string("SEARCH_INDEX_DIR", &cfg.Search.IndexDir)
+	
+	l.bool("COUNTER_RECONCILE_ENABLED", &cfg.Counters.ReconcileEnabled)
+	l.duration("COUNTER_RECONCILE_INTERVAL", &cfg.Counters.ReconcileInterval)
+	
+	l.bool("CACHE_ENABLED", &cfg.Cache.Enabled)
+	l.int("CACHE_LOCAL_SIZE", &cfg.Cache.LocalSize)
+	l.duration("CACHE_LOCAL_TTL", &cfg.Cache.LocalTTL)
+	l.duration("CACHE_REDIS_TTL", &cfg.Cache.RedisTTL)
+	l.duration("CACHE_NEGATIVE_TTL", &cfg.Cache.NegativeTTL)
+	
+	l.duration("STARTUP_RETRY_INITIAL", &cfg.Startup.RetryInitial)
+	l.duration("STARTUP_RETRY_MAX", &cfg.Startup.RetryMax)
+	l.duration("STARTUP_MAX_WAIT", &cfg.Startup.MaxWait)
+	l.bool("STARTUP_LAZY", &cfg.Startup.Lazy)
+	
+	l.string("LOG_LEVEL", &cfg.Logging.Level)
+	
+	l.int("MESSAGE_MAX_LENGTH", &cfg.Messages.MaxBodyLength)
+	
+	l.bool("FEATURE_REACTIONS", &cfg.Features.Reactions)
+	l.bool("FEATURE_SEARCH", &cfg.Features.Search)
+	l.bool("FEATURE_STREAMING", &cfg.Features.Streaming)
+	l.bool("FEATURE_PRESENCE", &cfg.Features.Presence)
+	l.bool("FEATURE_WEBHOOKS", &cfg.Features.Webhooks)
+	
+	l.string("QUOTA_DEFAULT_PLAN", &cfg.Quotas.DefaultPlan)
+}
+
+func (l *envLoader) fail(key, value, want string) {
+	l.errs = append(l.errs, fmt.Errorf("%s: %q is not a valid %s", key, value, want))
+}
+
+func (l *envLoader) string(key string, dst *string) {
+	if value := os.Getenv(key); value != "" {
+		*dst = value
+	}
+}
+
+func (l *envLoader) int(key string, dst *int) {
+	if value := os.Getenv(key); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			l.fail(key, value, "integer")
+			return
+		}
+		*dst = parsed
+	}
+}
+
+func (l *envLoader) int64(key string, dst *int64) {
+	if value := os.Getenv(key); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			l.fail(key, value, "integer")
+			return
+		}
+		*dst = parsed
+	}
+}
+
+func (l *envLoader) float(key string, dst *float64) {
+	if value := os.Getenv(key); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			l.fail(key, value, "number")
+			return
+		}
+		*dst = parsed
+	}
+}
+
+func (l *envLoader) bool(key string, dst *bool) {
+	if value := os.Getenv(key); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			l.fail(key, value, "boolean")
+			return
+		}
+		*dst = parsed
+	}
+}
+
+func (l *envLoader) duration(key string, dst *time.Duration) {
+	if value := os.Getenv(key); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			l.fail(key, value, "duration (e.g. 30s, 5m)")
+			return
+		}
+		*dst = parsed
+	}
+}
+
+// list reads a comma-separated value, dropping empty entries
+func (l *envLoader) list(key string, dst *[]string) {
+	if value := os.Getenv(key); value != "" {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*dst = items
+	}
+}
+
+// secret reads key from the environment, then lets key_FILE override it
+// with the contents of a mounted secret file
+func (l *envLoader) secret(key string, dst *string) {
+	l.string(key, dst)
+	
+	path := os.Getenv(key + "_FILE")
+	if path == "" {
+		return
+	}
+	
+	data, err := os.ReadFile(path)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s_FILE: %w", key, err))
+		return
+	}
+	*dst = strings.TrimRight(string(data), "\r\n")
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// secretFileVars name the secret files a reload re-reads, so rotating a
+// mounted secret is picked up like a config file edit
+var secretFileVars = []string{"MASTER_API_KEY_FILE", "DB_PASSWORD_FILE", "REDIS_PASSWORD_FILE"}
+
+// Store holds the live server configuration. Reload swaps in a new
+// snapshot atomically, so a request that calls Current once sees one
+// consistent set of values even while a reload happens.
+//
+// Only the tunable sections (security, cors, logging, messages, features,
+// quotas) change on reload. Everything else is wired at startup and needs a
+// restart.
+type Store struct {
+	path     string
+	current  atomic.Pointer[Config]
+	mu       sync.Mutex
+	onReload []func(*Config)
+}
+
+// NewStore wraps the configuration loaded from path at startup
+func NewStore(path string, cfg *Config) *Store {
+	s := &Store{path: path}
+	s.current.Store(cfg)
+	return s
+}
+
+// Current returns the configuration in effect. Treat it as read-only.
+func (s *Store) Current() *Config {
+	return s.current.Load()
+}
+
+// For returns the snapshot pinned to ctx, falling back to Current
+func (s *Store) For(ctx context.Context) *Config {
+	if cfg := FromContext(ctx); cfg != nil {
+		return cfg
+	}
+	return s.Current()
+}
+
+// OnReload registers fn to run after every successful reload
+func (s *Store) OnReload(fn func(*Config)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onReload = append(s.onReload, fn)
+}
+
+// Reload loads the configuration again and applies its tunable sections.
+// A configuration that fails validation is rejected and the previous one
+// stays in effect.
+func (s *Store) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	loaded, err := LoadServer(s.path)
+	if err != nil {
+		return err
+	}
+	
+	previous := s.current.Load()
+	next := *previous
+	next.Security = loaded.Security
+	next.CORS = loaded.CORS
+	next.Logging = loaded.Logging
+	next.Messages = loaded.Messages
+	next.Features = loaded.Features
+	next.Quotas = loaded.Quotas
+	
+	for _, section := range restartOnly(previous, loaded) {
+		log.Printf("Warning: %s changed but only takes effect after a restart", section)
+	}
+	
+	s.current.Store(&next)
+	for _, fn := range s.onReload {
+		fn(&next)
+	}
+	
+	log.Println("✅ Configuration reloaded")
+	return nil
+}
+
+// Watch reloads on SIGHUP and whenever the config file or a mounted secret
+// file changes, checking every interval. It returns when ctx is cancelled.
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	
+	files := s.watchedFiles()
+	seen := fileVersions(files)
+	
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Println("Received SIGHUP, reloading configuration")
+		case <-ticker.C:
+			versions := fileVersions(files)
+			if reflect.DeepEqual(versions, seen) {
+				continue
+			}
+			log.Println("Configuration files changed, reloading")
+		}
+	
+		seen = fileVersions(files)
+		if err := s.Reload(); err != nil {
+			log.Printf("Error reloading configuration, keeping previous settings: %v", err)
+		}
+	}
+}
+
+func (s *Store) watchedFiles() []string {
+	var files []string
+	if s.path != "" {
+		files = append(files, s.path)
+	}
+	for _, key := range secretFileVars {
+		if path := os.Getenv(key); path != "" {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// fileVersions fingerprints files by size and modification time. Missing
+// files get an empty entry so that recreating them counts as a change.
+func fileVersions(files []string) map[string]string {
+	versions := make(map[string]string, len(files))
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			versions[path] = ""
+			continue
+		}
+		versions[path] = fmt.Sprintf("%d:%d", info.Size(), info.ModTime().UnixNano())
+	}
+	return versions
+}
+
+// restartOnly lists the sections that differ but are not hot-reloadable
+func restartOnly(previous, loaded *Config) []string {
+	var changed []string
+	sections := []struct {
+		name       string
+		prev, next interface{}
+	}{
+		{"server", previous.Server, loaded.Server},
+		{"database", previous.Database, loaded.Database},
+		{"redis", previous.Redis, loaded.Redis},
+		{"reactions", previous.Reactions, loaded.Reactions},
+		{"usage", previous.Usage, loaded.Usage},
+		{"webhooks", previous.Webhooks, loaded.Webhooks},
+		{"search", previous.Search, loaded.Search},
+		{"counters", previous.Counters, loaded.Counters},
+		{"cache", previous.Cache, loaded.Cache},
+		{"startup", previous.Startup, loaded.Startup},
+	}
+	for _, section := range sections {
+		if !reflect.DeepEqual(section.prev, section.next) {
+			changed = append(changed, section.name)
+		}
+	}
+	return changed
+}
+
+type contextKey struct{}
+
+// WithConfig pins cfg to ctx so everything serving one request reads the
+// same snapshot
+func WithConfig(ctx context.Context, cfg *Config) context.Context {
+	return context.WithValue(ctx, contextKey{}, cfg)
+}
+
+// FromContext returns the snapshot pinned by WithConfig, or nil
+func FromContext(ctx context.Context) *Config {
+	cfg, _ := ctx.Value(contextKey{}).(*Config)
+	return cfg
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Validate checks the whole configuration and returns every problem found
+func (c *Config) Validate() []error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	
+	check(c.Database.Host != "", "database.host is required")
+	check(validPort(c.Database.Port), "database.port: %d is not a valid port", c.Database.Port)
+	check(c.Database.User != "", "database.user is required")
+	check(c.Database.Database != "", "database.name is required")
+	check(c.Database.MaxConns >= 1, "database.max_conns must be at least 1")
+	
+	check(c.Redis.Host != "", "redis.host is required")
+	check(validPort(c.Redis.Port), "redis.port: %d is not a valid port", c.Redis.Port)
+	check(c.Redis.DB >= 0, "redis.db must not be negative")
+	
+	check(c.Security.RateLimit > 0, "security.rate_limit must be positive")
+	check(c.Security.RateBurst >= 1, "security.rate_burst must be at least 1")
+	check(c.Security.MaxBodyBytes > 0, "security.max_body_bytes must be positive")
+	
+	check(len(c.CORS.AllowedOrigins) > 0, "cors.allowed_origins must list at least one origin (use * for any)")
+	check(len(c.CORS.AllowedMethods) > 0, "cors.allowed_methods must list at least one method")
+	errs = append(errs, validateCORSPolicy("cors", c.CORS.CORSPolicy)...)
+	for i, route := range c.CORS.Routes {
+		name := fmt.Sprintf("cors.routes[%d]", i)
+		check(strings.HasPrefix(route.PathPrefix, "/"), "%s.path_prefix: %q must start with /", name, route.PathPrefix)
+		// Check what the route applies, since it inherits from the default
+		errs = append(errs, validateCORSPolicy(name, c.CORS.RoutePolicy(route))...)
+	}
+	
+	check(c.Reactions.FlushInterval > 0, "reactions.flush_interval must be positive")
+	check(c.Usage.FlushInterval > 0, "usage.flush_interval must be positive")
+	
+	check(c.Webhooks.MaxAttempts >= 1, "webhooks.max_attempts must be at least 1")
+	check(c.Webhooks.RetryInitial > 0, "webhooks.retry_initial must be positive")
+	check(c.Webhooks.RetryMax >= c.Webhooks.RetryInitial, "webhooks.retry_max must be at least webhooks.retry_initial")
+	check(c.Webhooks.Timeout > 0, "webhooks.timeout must be positive")
+	check(c.Webhooks.PollInterval > 0, "webhooks.poll_interval must be positive")
+	check(c.Webhooks.Concurrency >= 1, "webhooks.concurrency must be at least 1")
+	
+	switch c.Search.Backend {
+	case "mysql", "embedded", "memory":
+	case "elasticsearch":
+		u, err := url.Parse(c.Search.ElasticsearchURL)
+		check(err == nil && u.Scheme != "" && u.Host != "", "search.elasticsearch_url: %q is not a valid URL", c.Search.ElasticsearchURL)
+		check(c.Search.ElasticsearchIndex != "", "search.elasticsearch_index is required")
+	default:
+		errs = append(errs, fmt.Errorf("search.backend: %q is not one of mysql, elasticsearch, embedded, memory", c.Search.Backend))
+	}
+	if c.Search.Backend == "embedded" {
+		check(c.Search.IndexDir != "", "search.index_dir is required for the embedded backend")
+	}
+	
+	check(c.Counters.ReconcileInterval > 0, "counters.reconcile_interval must be positive")
+	
+	if c.Cache.Enabled {
+		check(c.Cache.LocalSize >= 0, "cache.local_size must not be negative")
+		check(c.Cache.LocalTTL > 0, "cache.local_ttl must be positive")
+		check(c.Cache.RedisTTL > 0, "cache.redis_ttl must be positive")
+		check(c.Cache.NegativeTTL > 0, "cache.negative_ttl must be positive")
+	}
+	
+	check(c.Startup.RetryInitial > 0, "startup.retry_initial must be positive")
+	check(c.Startup.RetryMax >= c.Startup.RetryInitial, "startup.retry_max must be at least startup.retry_initial")
+	check(c.Startup.MaxWait > 0, "startup.max_wait must be positive")
+	
+	switch c.Logging.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("logging.level: %q is not one of debug, info, warn, error", c.Logging.Level))
+	}
+	
+	check(c.Messages.MaxBodyLength >= 1, "messages.max_body_length must be at least 1")
+	
+	_, ok := c.Quotas.Plans[c.Quotas.DefaultPlan]
+	check(ok, "quotas.default_plan: %q is not one of the plans", c.Quotas.DefaultPlan)
+	names := make([]string, 0, len(c.Quotas.Plans))
+	for name := range c.Quotas.Plans {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		plan := c.Quotas.Plans[name]
+		check(plan.MaxChats >= 0 && plan.MaxMessagesPerChat >= 0 && plan.MessagesPerDay >= 0 && plan.MaxBodyBytes >= 0,
+			"quotas.plans.%s: limits must not be negative (0 means unlimited)", name)
+	}
+	
+	return errs
+}
+
+// ValidateServer checks settings only the HTTP and gRPC servers depend on
+func (c *Config) ValidateServer() []error {
+	var errs []error
+	
+	port, err := strconv.Atoi(c.Server.Port)
+	if err != nil || !validPort(port) {
+		errs = append(errs, fmt.Errorf("server.port: %q is not a valid port", c.Server.Port))
+	}
+	if c.Server.GRPCPort != "" {
+		port, err := strconv.Atoi(c.Server.GRPCPort)
+		if err != nil || !validPort(port) {
+			errs = append(errs, fmt.Errorf("server.grpc_port: %q is not a valid port", c.Server.GRPCPort))
+		} else if c.Server.GRPCPort == c.Server.Port {
+			errs = append(errs, fmt.Errorf("server.grpc_port must differ from server.port"))
+		}
+	}
+	if !c.Security.SkipAPIKeyCheck && c.Security.MasterAPIKey == "" {
+		errs = append(errs, fmt.Errorf("security.master_api_key is required unless security.skip_api_key_check is true"))
+	}
+	
+	return errs
+}
+
+func validateCORSPolicy(name string, policy CORSPolicy) []error {
+	var errs []error
+	for _, origin := range policy.AllowedOrigins {
+		if origin == "*" {
+			if policy.AllowCredentials {
+				errs = append(errs, fmt.Errorf("%s.allow_credentials cannot be combined with the * origin", name))
+			}
+			continue
+		}
+		if !validOrigin(origin) {
+			errs = append(errs, fmt.Errorf("%s.allowed_origins: %q is not an origin like https://app.example.com or https://*.example.com", name, origin))
+		}
+	}
+	if policy.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("%s.max_age must not be negative", name))
+	}
+	return errs
+}
+
+// validOrigin accepts scheme://host[:port], where host may start with a
+// "*." wildcard label
+func validOrigin(origin string) bool {
+	u, err := url.Parse(strings.Replace(origin, "://*.", "://wildcard.", 1))
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" &&
+		u.Path == "" && u.RawQuery == "" && u.Fragment == "" && u.User == nil
+}
+
+func validPort(port int) bool {
+	return port > 0 && port < 65536
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// A route that allows credentials but lists no origins inherits the
+// default *, which must be rejected like the default itself would be
+func TestValidateChecksInheritedCORSPolicy(t *testing.T) {
+	cfg := Defaults()
+	cfg.CORS.Routes = []CORSRoute{{
+		PathPrefix: "/api/v1/applications/",
+		CORSPolicy: CORSPolicy{AllowCredentials: true},
+	}}
+	
+	var found bool
+	for _, err := range cfg.Validate() {
+		if strings.Contains(err.Error(), "cors.routes[0].allow_credentials") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Validate() = %v, want the route's credentials with the inherited * origin rejected", cfg.Validate())
+	}
+	
+	cfg.CORS.Routes[0].AllowedOrigins = []string{"https://admin.example.com"}
+	if errs := cfg.Validate(); len(errs) != 0 {
+		t.Errorf("Validate() = %v, want no errors", errs)
+	}
+}
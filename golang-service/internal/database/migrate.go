@@ -0,0 +1,310 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migrations live in migrations/<version>_<name>.up.sql with a matching
+// .down.sql. Versions share Rails' timestamp format and its schema_migrations
+// table, so a database migrated by either side is recognised by the other.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migratorLockSalt is ActiveRecord::Migrator::MIGRATOR_SALT. Using the same
+// advisory lock as Rails keeps both migrators from running at once.
+const migratorLockSalt = 2053462845
+
+// migrationLockTimeout bounds how long we wait for another migrator
+const migrationLockTimeout = 60 * time.Second
+
+// ErrSchemaOutdated is returned when embedded migrations have not been applied
+var ErrSchemaOutdated = errors.New("database schema is out of date")
+
+type Migration struct {
+	Version string
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// LoadMigrations returns the embedded migrations ordered by version
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	
+	byVersion := make(map[string]*Migration)
+	for _, entry := range entries {
+		file := entry.Name()
+		base, direction, ok := strings.Cut(strings.TrimSuffix(file, ".sql"), ".")
+		version, name, found := strings.Cut(base, "_")
+		if !ok || !found || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("invalid migration file name %q", file)
+		}
+		if _, err := strconv.ParseUint(version, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q", file)
+		}
+		
+		body, err := migrationFiles.ReadFile(path.Join("migrations", file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+	
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %s_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	
+	return migrations, nil
+}
+
+// MigrationStatuses reports which embedded migrations have been applied
+func MigrationStatuses(db *sql.DB) ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	
+	applied, err := appliedVersions(context.Background(), db)
+	if err != nil {
+		return nil, err
+	}
+	
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		statuses[i] = MigrationStatus{Migration: m, Applied: applied[m.Version]}
+	}
+	
+	return statuses, nil
+}
+
+// CheckSchema fails with ErrSchemaOutdated when any embedded migration is
+// missing from schema_migrations
+func CheckSchema(db *sql.DB) error {
+	statuses, err := MigrationStatuses(db)
+	if err != nil {
+		return err
+	}
+	
+	var pending []string
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, status.Version+"_"+status.Name)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: pending migrations %s", ErrSchemaOutdated, strings.Join(pending, ", "))
+	}
+	
+	return nil
+}
+
+// MigrateUp applies pending migrations in order, stopping after target when
+// it is not empty. It returns the migrations that were applied.
+func MigrateUp(db *sql.DB, target string) ([]Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	
+	var done []Migration
+	err = withMigrationLock(db, func(ctx context.Context, conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		
+		for _, m := range migrations {
+			if target != "" && m.Version > target {
+				break
+			}
+			if applied[m.Version] {
+				continue
+			}
+			
+			log.Printf("Migrating up %s_%s", m.Version, m.Name)
+			if err := execStatements(ctx, conn, m.Up); err != nil {
+				return fmt.Errorf("migration %s_%s failed: %w", m.Version, m.Name, err)
+			}
+			if _, err := conn.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (?)`, m.Version); err != nil {
+				return fmt.Errorf("failed to record migration %s: %w", m.Version, err)
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	
+	return done, err
+}
+
+// MigrateDown reverts the most recently applied embedded migrations, up to
+// steps of them. It returns the migrations that were reverted.
+func MigrateDown(db *sql.DB, steps int) ([]Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	
+	var done []Migration
+	err = withMigrationLock(db, func(ctx context.Context, conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		
+		for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			m := migrations[i]
+			if !applied[m.Version] {
+				continue
+			}
+			if m.Down == "" {
+				return fmt.Errorf("migration %s_%s is irreversible", m.Version, m.Name)
+			}
+			
+			log.Printf("Migrating down %s_%s", m.Version, m.Name)
+			if err := execStatements(ctx, conn, m.Down); err != nil {
+				return fmt.Errorf("migration %s_%s failed: %w", m.Version, m.Name, err)
+			}
+			if _, err := conn.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, m.Version); err != nil {
+				return fmt.Errorf("failed to unrecord migration %s: %w", m.Version, err)
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	
+	return done, err
+}
+
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// appliedVersions creates schema_migrations the way Rails does if needed
+// and returns the recorded versions
+func appliedVersions(ctx context.Context, q queryer) (map[string]bool, error) {
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+	            version varchar(255) NOT NULL,
+	            PRIMARY KEY (version)
+	          )`
+	if _, err := q.ExecContext(ctx, query); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	
+	rows, err := q.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		applied[version] = true
+	}
+	
+	return applied, rows.Err()
+}
+
+// withMigrationLock runs fn on one connection while holding the advisory
+// lock Rails takes for db:migrate. MySQL DDL is not transactional, so the
+// lock is the only thing preventing two migrators from interleaving.
+func withMigrationLock(db *sql.DB, fn func(ctx context.Context, conn *sql.Conn) error) error {
+	ctx := context.Background()
+	
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer conn.Close()
+	
+	var database string
+	if err := conn.QueryRowContext(ctx, `SELECT DATABASE()`).Scan(&database); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	lockName := strconv.FormatUint(migratorLockSalt*uint64(crc32.ChecksumIEEE([]byte(database))), 10)
+	
+	var acquired sql.NullInt64
+	err = conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, lockName, int(migrationLockTimeout.Seconds())).Scan(&acquired)
+	if err != nil {
+		return fmt.Errorf("failed to take migration lock: %w", err)
+	}
+	if acquired.Int64 != 1 {
+		return fmt.Errorf("another migration is running")
+	}
+	defer conn.ExecContext(ctx, `SELECT RELEASE_LOCK(?)`, lockName)
+	
+	return fn(ctx, conn)
+}
+
+// execStatements runs a migration file one statement at a time. Statements
+// end with a semicolon at the end of a line; lines starting with -- are
+// comments.
+func execStatements(ctx context.Context, conn *sql.Conn, script string) error {
+	var statement strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		
+		statement.WriteString(line)
+		statement.WriteString("\n")
+		
+		if strings.HasSuffix(trimmed, ";") {
+			if _, err := conn.ExecContext(ctx, strings.TrimSuffix(strings.TrimSpace(statement.String()), ";")); err != nil {
+				return err
+			}
+			statement.Reset()
+		}
+	}
+	
+	if rest := strings.TrimSpace(statement.String()); rest != "" {
+		if _, err := conn.ExecContext(ctx, rest); err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
@@ -0,0 +1,11 @@
+CREATE TABLE applications (
+  id bigint NOT NULL AUTO_INCREMENT,
+  token varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  chats_count int NOT NULL DEFAULT 0,
+  created_at datetime(6) NOT NULL,
+  updated_at datetime(6) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY index_applications_on_token (token),
+  KEY index_applications_on_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,13 @@
+CREATE TABLE chats (
+  id bigint NOT NULL AUTO_INCREMENT,
+  application_id bigint NOT NULL,
+  number int NOT NULL,
+  messages_count int NOT NULL DEFAULT 0,
+  created_at datetime(6) NOT NULL,
+  updated_at datetime(6) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY index_chats_on_app_and_number (application_id, number),
+  KEY index_chats_on_application_id (application_id),
+  KEY index_chats_on_created_at (created_at),
+  CONSTRAINT fk_chats_application_id FOREIGN KEY (application_id) REFERENCES applications (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
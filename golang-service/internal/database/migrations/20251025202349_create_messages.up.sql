@@ -0,0 +1,16 @@
+CREATE TABLE messages (
+  id bigint NOT NULL AUTO_INCREMENT,
+  chat_id bigint NOT NULL,
+  number int NOT NULL,
+  body text NOT NULL,
+  created_at datetime(6) NOT NULL,
+  updated_at datetime(6) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY index_messages_on_chat_and_number (chat_id, number),
+  KEY index_messages_on_chat_id (chat_id),
+  KEY index_messages_on_created_at (created_at),
+  CONSTRAINT fk_messages_chat_id FOREIGN KEY (chat_id) REFERENCES chats (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- Full-text search index for MySQL
+ALTER TABLE messages ADD FULLTEXT INDEX idx_messages_body (body);
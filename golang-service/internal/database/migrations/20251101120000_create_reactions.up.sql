@@ -0,0 +1,27 @@
+-- Individual reactions; one per user per emoji
+CREATE TABLE reactions (
+  id bigint NOT NULL AUTO_INCREMENT,
+  message_id bigint NOT NULL,
+  user_id varchar(255) NOT NULL,
+  emoji varchar(32) NOT NULL,
+  created_at datetime(6) NOT NULL,
+  updated_at datetime(6) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY index_reactions_on_message_user_emoji (message_id, user_id, emoji),
+  KEY index_reactions_on_message_id (message_id),
+  CONSTRAINT fk_reactions_message_id FOREIGN KEY (message_id) REFERENCES messages (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- Aggregated counts flushed from Redis
+CREATE TABLE reaction_counts (
+  id bigint NOT NULL AUTO_INCREMENT,
+  message_id bigint NOT NULL,
+  emoji varchar(32) NOT NULL,
+  count int NOT NULL DEFAULT 0,
+  created_at datetime(6) NOT NULL,
+  updated_at datetime(6) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY index_reaction_counts_on_message_and_emoji (message_id, emoji),
+  KEY index_reaction_counts_on_message_id (message_id),
+  CONSTRAINT fk_reaction_counts_message_id FOREIGN KEY (message_id) REFERENCES messages (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
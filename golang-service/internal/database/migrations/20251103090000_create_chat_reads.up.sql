@@ -0,0 +1,14 @@
+-- Highest message number each user has read; only ever advances
+CREATE TABLE chat_reads (
+  id bigint NOT NULL AUTO_INCREMENT,
+  chat_id bigint NOT NULL,
+  user_id varchar(255) NOT NULL,
+  last_read_number int NOT NULL DEFAULT 0,
+  created_at datetime(6) NOT NULL,
+  updated_at datetime(6) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY index_chat_reads_on_chat_and_user (chat_id, user_id),
+  KEY index_chat_reads_on_chat_id (chat_id),
+  KEY index_chat_reads_on_user_id (user_id),
+  CONSTRAINT fk_chat_reads_chat_id FOREIGN KEY (chat_id) REFERENCES chats (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1 @@
+DROP TABLE application_quotas;
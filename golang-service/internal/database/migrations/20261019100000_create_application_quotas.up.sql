@@ -0,0 +1,13 @@
+-- Plan assignment per application; NULL limits fall back to the plan
+CREATE TABLE application_quotas (
+  application_id bigint NOT NULL,
+  plan varchar(50) NOT NULL,
+  max_chats bigint DEFAULT NULL,
+  max_messages_per_chat bigint DEFAULT NULL,
+  messages_per_day bigint DEFAULT NULL,
+  max_body_bytes int DEFAULT NULL,
+  created_at datetime(6) NOT NULL,
+  updated_at datetime(6) NOT NULL,
+  PRIMARY KEY (application_id),
+  CONSTRAINT fk_application_quotas_application_id FOREIGN KEY (application_id) REFERENCES applications (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,12 @@
+-- Metered usage per application and hour, flushed from Redis buckets.
+-- No foreign key: billing history outlives the application.
+CREATE TABLE usage_hourly (
+  application_id bigint NOT NULL,
+  hour datetime NOT NULL,
+  metric varchar(191) NOT NULL,
+  value bigint NOT NULL DEFAULT 0,
+  created_at datetime(6) NOT NULL,
+  updated_at datetime(6) NOT NULL,
+  PRIMARY KEY (application_id, hour, metric),
+  KEY index_usage_hourly_on_hour (hour)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
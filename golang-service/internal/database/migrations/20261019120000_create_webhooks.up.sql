@@ -0,0 +1,48 @@
+-- Webhook endpoints registered per application. events is a comma
+-- separated list of subscribed event types.
+CREATE TABLE webhook_endpoints (
+  id bigint NOT NULL AUTO_INCREMENT,
+  application_id bigint NOT NULL,
+  url varchar(2048) NOT NULL,
+  secret varchar(255) NOT NULL,
+  events varchar(255) NOT NULL,
+  created_at datetime(6) NOT NULL,
+  updated_at datetime(6) NOT NULL,
+  PRIMARY KEY (id),
+  KEY index_webhook_endpoints_on_application_id (application_id),
+  CONSTRAINT fk_webhook_endpoints_application_id FOREIGN KEY (application_id) REFERENCES applications (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- Delivery queue: one row per event and endpoint. Pending rows are claimed
+-- once next_attempt_at has passed.
+CREATE TABLE webhook_deliveries (
+  id bigint NOT NULL AUTO_INCREMENT,
+  endpoint_id bigint NOT NULL,
+  event_id varchar(64) NOT NULL,
+  event_type varchar(50) NOT NULL,
+  payload mediumtext NOT NULL,
+  status varchar(20) NOT NULL,
+  attempts int NOT NULL DEFAULT 0,
+  next_attempt_at datetime(6) NOT NULL,
+  last_error text,
+  created_at datetime(6) NOT NULL,
+  updated_at datetime(6) NOT NULL,
+  PRIMARY KEY (id),
+  KEY index_webhook_deliveries_on_status_and_next_attempt_at (status, next_attempt_at),
+  KEY index_webhook_deliveries_on_endpoint_id (endpoint_id),
+  CONSTRAINT fk_webhook_deliveries_endpoint_id FOREIGN KEY (endpoint_id) REFERENCES webhook_endpoints (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- One row per HTTP attempt; status_code is NULL when no response arrived
+CREATE TABLE webhook_attempts (
+  id bigint NOT NULL AUTO_INCREMENT,
+  delivery_id bigint NOT NULL,
+  attempt int NOT NULL,
+  status_code int DEFAULT NULL,
+  error text,
+  duration_ms int NOT NULL,
+  created_at datetime(6) NOT NULL,
+  PRIMARY KEY (id),
+  KEY index_webhook_attempts_on_delivery_id (delivery_id),
+  CONSTRAINT fk_webhook_attempts_delivery_id FOREIGN KEY (delivery_id) REFERENCES webhook_deliveries (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
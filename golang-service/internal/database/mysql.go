@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -12,8 +13,16 @@ import (
 
 var DB *sql.DB
 
-// InitMySQL initializes MySQL connection
+// InitMySQL opens the MySQL pool and waits until the server answers
 func InitMySQL(cfg *config.Config) error {
+	if err := OpenMySQL(cfg); err != nil {
+		return err
+	}
+	return WaitForMySQL(context.Background(), cfg.Startup)
+}
+
+// OpenMySQL configures the connection pool without connecting
+func OpenMySQL(cfg *config.Config) error {
 	var err error
 	
 	DB, err = sql.Open("mysql", cfg.DSN())
@@ -27,8 +36,15 @@ func InitMySQL(cfg *config.Config) error {
 	DB.SetConnMaxLifetime(5 * time.Minute)
 	DB.SetConnMaxIdleTime(1 * time.Minute)
 
-	// Test connection
-	if err = DB.Ping(); err != nil {
+	return nil
+}
+
+// WaitForMySQL pings MySQL with backoff until it answers or cfg.MaxWait passes
+func WaitForMySQL(ctx context.Context, cfg config.StartupConfig) error {
+	err := waitFor(ctx, "MySQL", cfg, func(ctx context.Context) error {
+		return DB.PingContext(ctx)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
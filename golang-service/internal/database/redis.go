@@ -14,16 +14,27 @@ var (
 	Ctx         = context.Background()
 )
 
-// InitRedis initializes Redis connection
+// InitRedis creates the Redis client and waits until the server answers
 func InitRedis(cfg *config.Config) error {
+	OpenRedis(cfg)
+	return WaitForRedis(Ctx, cfg.Startup)
+}
+
+// OpenRedis creates the Redis client without connecting
+func OpenRedis(cfg *config.Config) {
 	RedisClient = redis.NewClient(&redis.Options{
 		Addr:     cfg.RedisAddr(),
 		Password: cfg.Redis.Password,
 		DB:       cfg.Redis.DB,
 	})
+}
 
-	// Test connection
-	if err := RedisClient.Ping(Ctx).Err(); err != nil {
+// WaitForRedis pings Redis with backoff until it answers or cfg.MaxWait passes
+func WaitForRedis(ctx context.Context, cfg config.StartupConfig) error {
+	err := waitFor(ctx, "Redis", cfg, func(ctx context.Context) error {
+		return RedisClient.Ping(ctx).Err()
+	})
+	if err != nil {
 		return fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+)
+
+// waitFor calls ping until it succeeds, sleeping with exponential backoff
+// and jitter between attempts. It gives up once cfg.MaxWait has passed.
+func waitFor(ctx context.Context, name string, cfg config.StartupConfig, ping func(ctx context.Context) error) error {
+	deadline := time.Now().Add(cfg.MaxWait)
+	backoff := cfg.RetryInitial
+	
+	for attempt := 1; ; attempt++ {
+		err := ping(ctx)
+		if err == nil {
+			if attempt > 1 {
+				log.Printf("%s reachable after %d attempts", name, attempt)
+			}
+			return nil
+		}
+		
+		// Equal jitter: sleep between half and all of the current backoff
+		// so replicas restarted together do not retry in lockstep
+		sleep := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		if remaining := time.Until(deadline); sleep > remaining {
+			if remaining <= 0 {
+				return fmt.Errorf("%s unreachable after %d attempts over %s: %w", name, attempt, cfg.MaxWait, err)
+			}
+			sleep = remaining
+		}
+		
+		log.Printf("Warning: Waiting for %s (attempt %d failed: %v), retrying in %s", name, attempt, err, sleep.Round(time.Millisecond))
+		
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(sleep):
+		}
+		
+		backoff *= 2
+		if backoff > cfg.RetryMax {
+			backoff = cfg.RetryMax
+		}
+	}
+}
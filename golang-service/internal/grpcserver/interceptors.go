@@ -0,0 +1,160 @@
+package grpcserver
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/middleware"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// apiKeyMetadata carries the API key, like the X-API-Key header
+const apiKeyMetadata = "x-api-key"
+
+// guard is the check shared by the unary and stream interceptors. It may
+// return a new context for the handler.
+type guard func(ctx context.Context, method string) (context.Context, error)
+
+// Interceptors returns the server options that chain the gRPC equivalents
+// of the HTTP middleware: logging, a pinned config snapshot, readiness,
+// rate limiting and API key auth, in that order.
+func Interceptors(store *config.Store, readiness *middleware.Readiness) []grpc.ServerOption {
+	guards := []guard{
+		configGuard(store),
+		readinessGuard(readiness),
+		rateLimitGuard(store),
+		authGuard(store),
+	}
+	
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryLogging, unaryGuards(guards)),
+		grpc.ChainStreamInterceptor(streamLogging, streamGuards(guards)),
+	}
+}
+
+func unaryGuards(guards []guard) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		for _, g := range guards {
+			var err error
+			if ctx, err = g(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamGuards(guards []guard) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		for _, g := range guards {
+			var err error
+			if ctx, err = g(ctx, info.FullMethod); err != nil {
+				return err
+			}
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// contextStream swaps the context seen by stream handlers
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// configGuard pins the current configuration to the call, like
+// middleware.ConfigMiddleware
+func configGuard(store *config.Store) guard {
+	return func(ctx context.Context, method string) (context.Context, error) {
+		return config.WithConfig(ctx, store.Current()), nil
+	}
+}
+
+// readinessGuard answers Unavailable until MySQL and Redis are connected
+func readinessGuard(readiness *middleware.Readiness) guard {
+	return func(ctx context.Context, method string) (context.Context, error) {
+		if !readiness.Ready() {
+			return nil, status.Error(codes.Unavailable, "Waiting for MySQL and Redis")
+		}
+		return ctx, nil
+	}
+}
+
+// rateLimitGuard limits calls per peer address to security.rate_limit per
+// second with bursts of security.rate_burst, like RateLimitMiddleware
+func rateLimitGuard(store *config.Store) guard {
+	rateLimiter := middleware.NewRateLimiter(rate.Limit(store.Current().Security.RateLimit), store.Current().Security.RateBurst)
+	
+	return func(ctx context.Context, method string) (context.Context, error) {
+		cfg := store.For(ctx).Security
+		rateLimiter.SetRate(rate.Limit(cfg.RateLimit), cfg.RateBurst)
+		
+		addr := "unknown"
+		if p, ok := peer.FromContext(ctx); ok {
+			addr = p.Addr.String()
+		}
+		
+		if !rateLimiter.GetLimiter(addr).Allow() {
+			return nil, status.Error(codes.ResourceExhausted, "Too many requests")
+		}
+		return ctx, nil
+	}
+}
+
+// authGuard checks the x-api-key metadata against the master key, like
+// AuthMiddleware
+func authGuard(store *config.Store) guard {
+	return func(ctx context.Context, method string) (context.Context, error) {
+		cfg := store.For(ctx).Security
+		
+		// Skip in development if configured
+		if cfg.SkipAPIKeyCheck {
+			return ctx, nil
+		}
+		
+		md, _ := metadata.FromIncomingContext(ctx)
+		keys := md.Get(apiKeyMetadata)
+		if len(keys) == 0 || keys[0] == "" || keys[0] != cfg.MasterAPIKey {
+			return nil, status.Error(codes.Unauthenticated, "Invalid or missing API key")
+		}
+		return ctx, nil
+	}
+}
+
+// unaryLogging logs every call at debug level, and server-side failures as
+// errors
+func unaryLogging(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logCall(info.FullMethod, start, err)
+	return resp, err
+}
+
+func streamLogging(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	logCall(info.FullMethod, start, err)
+	return err
+}
+
+func logCall(method string, start time.Time, err error) {
+	code := status.Code(err)
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss:
+		log.Printf("Error in gRPC %s: %v", method, err)
+	default:
+		log.Printf("Debug: gRPC %s %s in %s", method, code, time.Since(start).Round(time.Microsecond))
+	}
+}
@@ -0,0 +1,329 @@
+// Package grpcserver implements the chatv1.ChatService gRPC API on top of
+// the same repositories and services as the HTTP handlers
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	chatv1 "github.com/AhmedAbdelbasetAli/chat-service/api/chat/v1"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/messaging"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/validation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 100
+	
+	// Messages fetched per query while replaying history
+	streamReplayPageSize = 100
+)
+
+type Server struct {
+	chatv1.UnimplementedChatServiceServer
+	
+	appRepo      *repository.ApplicationRepository
+	chatRepo     *repository.ChatRepository
+	messageRepo  *repository.MessageRepository
+	counterSvc   *services.CounterService
+	usageSvc     *services.UsageService
+	hub          *services.Hub
+	messagingSvc *messaging.Service
+	store        *config.Store
+}
+
+func NewServer(
+	appRepo *repository.ApplicationRepository,
+	chatRepo *repository.ChatRepository,
+	messageRepo *repository.MessageRepository,
+	counterSvc *services.CounterService,
+	usageSvc *services.UsageService,
+	hub *services.Hub,
+	messagingSvc *messaging.Service,
+	store *config.Store,
+) *Server {
+	return &Server{
+		appRepo:      appRepo,
+		chatRepo:     chatRepo,
+		messageRepo:  messageRepo,
+		counterSvc:   counterSvc,
+		usageSvc:     usageSvc,
+		hub:          hub,
+		messagingSvc: messagingSvc,
+		store:        store,
+	}
+}
+
+// CreateChat mirrors POST /api/v1/chats
+func (s *Server) CreateChat(ctx context.Context, req *chatv1.CreateChatRequest) (*chatv1.Chat, error) {
+	app, err := s.application(ctx, req.ApplicationToken)
+	if err != nil {
+		return nil, err
+	}
+	
+	chat, err := s.messagingSvc.CreateChat(app)
+	if err != nil {
+		return nil, createStatus(err, "chat")
+	}
+	
+	return chatProto(chat), nil
+}
+
+// CreateMessage mirrors POST /api/v1/messages
+func (s *Server) CreateMessage(ctx context.Context, req *chatv1.CreateMessageRequest) (*chatv1.Message, error) {
+	// Same tag rules as POST /api/v1/messages, every failure at once
+	errs, err := validation.Struct(&models.MessageCreateRequest{
+		ApplicationToken: req.ApplicationToken,
+		ChatNumber:       int(req.ChatNumber),
+		Body:             req.Body,
+	}, services.ValidationParams(s.store.For(ctx)))
+	if err != nil {
+		log.Printf("Error validating request: %v", err)
+		return nil, status.Error(codes.Internal, "Failed to validate request")
+	}
+	if errs != nil {
+		return nil, status.Error(codes.InvalidArgument, errs.Error())
+	}
+	
+	app, chat, err := s.chat(ctx, req.ApplicationToken, req.ChatNumber)
+	if err != nil {
+		return nil, err
+	}
+	
+	message, err := s.messagingSvc.CreateMessage(app, chat, req.Body)
+	if err != nil {
+		return nil, createStatus(err, "message")
+	}
+	
+	return messageProto(message), nil
+}
+
+// GetChat returns one chat of an application
+func (s *Server) GetChat(ctx context.Context, req *chatv1.GetChatRequest) (*chatv1.Chat, error) {
+	_, chat, err := s.chat(ctx, req.ApplicationToken, req.ChatNumber)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Prefer the Redis counter, which is ahead of the periodically synced
+	// messages_count column, like the HTTP chat listing does
+	response := chatProto(chat)
+	counter, ok, err := s.counterSvc.GetMessageCounter(chat.ID)
+	if err != nil {
+		log.Printf("Warning: Falling back to messages_count: %v", err)
+	} else if ok && int32(counter) > response.MessagesCount {
+		response.MessagesCount = int32(counter)
+	}
+	return response, nil
+}
+
+// ListMessages returns up to limit messages numbered above after_number
+func (s *Server) ListMessages(ctx context.Context, req *chatv1.ListMessagesRequest) (*chatv1.ListMessagesResponse, error) {
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	if limit < 1 || limit > maxListLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxListLimit)
+	}
+	if req.AfterNumber < 0 {
+		return nil, status.Error(codes.InvalidArgument, "after_number must not be negative")
+	}
+	
+	_, chat, err := s.chat(ctx, req.ApplicationToken, req.ChatNumber)
+	if err != nil {
+		return nil, err
+	}
+	
+	messages, err := s.messageRepo.ListAfter(chat.ID, int(req.AfterNumber), limit)
+	if err != nil {
+		log.Printf("Error listing messages: %v", err)
+		return nil, status.Error(codes.Internal, "Failed to list messages")
+	}
+	
+	response := &chatv1.ListMessagesResponse{
+		Messages: make([]*chatv1.Message, 0, len(messages)),
+	}
+	for i := range messages {
+		response.Messages = append(response.Messages, messageProto(&messages[i]))
+	}
+	if len(messages) == limit {
+		response.NextAfterNumber = int32(messages[len(messages)-1].Number)
+	}
+	
+	return response, nil
+}
+
+// StreamMessages mirrors the SSE stream: it replays messages after a
+// non-zero after_number, then forwards live message events until the
+// client leaves
+func (s *Server) StreamMessages(req *chatv1.StreamMessagesRequest, stream grpc.ServerStreamingServer[chatv1.MessageEvent]) error {
+	ctx := stream.Context()
+	if req.AfterNumber < 0 {
+		return status.Error(codes.InvalidArgument, "after_number must not be negative")
+	}
+	
+	_, chat, err := s.chat(ctx, req.ApplicationToken, req.ChatNumber)
+	if err != nil {
+		return err
+	}
+	
+	// Subscribe before replaying so nothing created in between is lost
+	events := make(chan models.ChatEvent, 256)
+	if err := s.hub.Subscribe(chat.ID, events); err != nil {
+		log.Printf("Error subscribing to chat %d: %v", chat.ID, err)
+		return status.Error(codes.Internal, "Failed to subscribe")
+	}
+	defer s.hub.Unsubscribe(chat.ID, events)
+	
+	// A resuming client gets the messages it missed; after_number 0 starts
+	// from now. Replayed numbers are remembered so their live events are
+	// not sent twice, while messages committed out of number order still are.
+	replayed := make(map[int]bool)
+	for after := int(req.AfterNumber); after > 0; {
+		messages, err := s.messageRepo.ListAfter(chat.ID, after, streamReplayPageSize)
+		if err != nil {
+			log.Printf("Error replaying messages for chat %d: %v", chat.ID, err)
+			return status.Error(codes.Internal, "Failed to replay messages")
+		}
+	
+		for i := range messages {
+			event := &chatv1.MessageEvent{
+				Type:       models.EventMessageCreated,
+				ChatNumber: int32(chat.Number),
+				Message:    messageProto(&messages[i]),
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+			replayed[messages[i].Number] = true
+			after = messages[i].Number
+		}
+	
+		if len(messages) < streamReplayPageSize {
+			break
+		}
+	}
+	
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-events:
+			// Presence and typing events are not part of this stream
+			if event.Message == nil {
+				continue
+			}
+			// Skip creations already delivered by the replay
+			if event.Type == models.EventMessageCreated && replayed[event.Message.Number] {
+				delete(replayed, event.Message.Number)
+				continue
+			}
+	
+			err := stream.Send(&chatv1.MessageEvent{
+				Type:       event.Type,
+				ChatNumber: int32(event.ChatNumber),
+				Message: &chatv1.Message{
+					Number:    int32(event.Message.Number),
+					Body:      event.Message.Body,
+					CreatedAt: timestamppb.New(event.Message.CreatedAt),
+					UpdatedAt: timestamppb.New(event.Message.UpdatedAt),
+				},
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// application validates a token, loads its application and meters the
+// call against it, like UsageMiddleware does for REST routes
+func (s *Server) application(ctx context.Context, token string) (*models.Application, error) {
+	if err := services.ValidateToken(token); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	
+	app, err := s.appRepo.GetByToken(token)
+	if errors.Is(err, repository.ErrApplicationNotFound) {
+		return nil, status.Error(codes.NotFound, "Application not found")
+	}
+	if err != nil {
+		log.Printf("Error loading application: %v", err)
+		return nil, status.Error(codes.Internal, "Failed to load application")
+	}
+	
+	if method, ok := grpc.Method(ctx); ok {
+		s.usageSvc.RecordAPICall(app.ID, "gRPC "+method)
+	}
+	return app, nil
+}
+
+// chat loads an application and one of its chats
+func (s *Server) chat(ctx context.Context, token string, number int32) (*models.Application, *models.Chat, error) {
+	if err := services.ValidateChatNumber(int(number)); err != nil {
+		return nil, nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	
+	app, err := s.application(ctx, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	chat, err := s.chatRepo.GetByApplicationAndNumber(app.ID, int(number))
+	if errors.Is(err, repository.ErrChatNotFound) {
+		return nil, nil, status.Error(codes.NotFound, "Chat not found")
+	}
+	if err != nil {
+		log.Printf("Error loading chat: %v", err)
+		return nil, nil, status.Error(codes.Internal, "Failed to load chat")
+	}
+	
+	return app, chat, nil
+}
+
+// createStatus maps errors from creating a chat or message like the HTTP
+// handlers do: quotas that reset are ResourceExhausted, hard plan limits
+// PermissionDenied. Anything else is logged and Internal.
+func createStatus(err error, what string) error {
+	var quotaErr *services.QuotaError
+	if errors.As(err, &quotaErr) {
+		if quotaErr.RetryAfter > 0 {
+			return status.Error(codes.ResourceExhausted, quotaErr.Error())
+		}
+		return status.Error(codes.PermissionDenied, quotaErr.Error())
+	}
+	if errors.Is(err, messaging.ErrNumber) {
+		log.Printf("Error getting %s number: %v", what, err)
+		return status.Errorf(codes.Internal, "Failed to generate %s number", what)
+	}
+	log.Printf("Error creating %s: %v", what, err)
+	return status.Errorf(codes.Internal, "Failed to create %s", what)
+}
+
+func chatProto(chat *models.Chat) *chatv1.Chat {
+	return &chatv1.Chat{
+		Number:        int32(chat.Number),
+		MessagesCount: int32(chat.MessagesCount),
+		CreatedAt:     timestamppb.New(chat.CreatedAt),
+		UpdatedAt:     timestamppb.New(chat.UpdatedAt),
+	}
+}
+
+func messageProto(message *models.Message) *chatv1.Message {
+	return &chatv1.Message{
+		Number:    int32(message.Number),
+		Body:      message.Body,
+		CreatedAt: timestamppb.New(message.CreatedAt),
+		UpdatedAt: timestamppb.New(message.UpdatedAt),
+	}
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+	"github.com/gorilla/mux"
+)
+
+type ApplicationHandler struct {
+	appRepo    *repository.ApplicationRepository
+	appSvc     *services.ApplicationService
+	counterSvc *services.CounterService
+}
+
+func NewApplicationHandler(
+	appRepo *repository.ApplicationRepository,
+	appSvc *services.ApplicationService,
+	counterSvc *services.CounterService,
+) *ApplicationHandler {
+	return &ApplicationHandler{
+		appRepo:    appRepo,
+		appSvc:     appSvc,
+		counterSvc: counterSvc,
+	}
+}
+
+// Create handles POST /api/v1/applications
+func (h *ApplicationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	// Parse request
+	name, ok := decodeApplicationName(w, r)
+	if !ok {
+		return
+	}
+	
+	// Validate inputs
+	if !validateRequest(w, r, http.StatusUnprocessableEntity, "Failed to create application", &models.ApplicationRequest{Name: name}) {
+		return
+	}
+	
+	app, err := h.appSvc.Create(strings.TrimSpace(name))
+	if err != nil {
+		log.Printf("Error creating application: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to create application", err.Error())
+		return
+	}
+	services.TagUsage(r.Context(), app.ID)
+	
+	log.Printf("✅ Created application %s", app.Token)
+	respondJSON(w, http.StatusCreated, applicationResponse(*app, nil))
+}
+
+// Get handles GET /api/v1/applications/{token}
+func (h *ApplicationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	token := mux.Vars(r)["token"]
+	
+	// Validate token
+	if err := services.ValidateToken(token); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid token", err.Error())
+		return
+	}
+	
+	// Get application
+	app, err := h.appRepo.GetByToken(token)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Application not found", err.Error())
+		return
+	}
+	
+	respondJSON(w, http.StatusOK, applicationResponse(*app, h.chatCounters(*app)))
+}
+
+// Update handles PUT /api/v1/applications/{token}; only the name can change
+func (h *ApplicationHandler) Update(w http.ResponseWriter, r *http.Request) {
+	token := mux.Vars(r)["token"]
+	
+	// Parse request
+	name, ok := decodeApplicationName(w, r)
+	if !ok {
+		return
+	}
+	
+	// Validate inputs
+	if err := services.ValidateToken(token); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid token", err.Error())
+		return
+	}
+	
+	if !validateRequest(w, r, http.StatusUnprocessableEntity, "Failed to update application", &models.ApplicationRequest{Name: name}) {
+		return
+	}
+	
+	// Get application
+	app, err := h.appRepo.GetByToken(token)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Application not found", err.Error())
+		return
+	}
+	
+	if err := h.appRepo.Rename(app.ID, strings.TrimSpace(name)); err != nil {
+		log.Printf("Error renaming application: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update application", err.Error())
+		return
+	}
+	
+	// Re-read so updated_at comes from the database
+	if app, err = h.appRepo.GetByToken(token); err != nil {
+		log.Printf("Error reloading application: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update application", err.Error())
+		return
+	}
+	
+	respondJSON(w, http.StatusOK, applicationResponse(*app, h.chatCounters(*app)))
+}
+
+// List handles GET /api/v1/applications, newest first
+func (h *ApplicationHandler) List(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := parsePagination(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid pagination", err.Error())
+		return
+	}
+	
+	total, err := h.appRepo.Count()
+	if err != nil {
+		log.Printf("Error counting applications: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to list applications", err.Error())
+		return
+	}
+	
+	apps, err := h.appRepo.List((page-1)*perPage, perPage)
+	if err != nil {
+		log.Printf("Error listing applications: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to list applications", err.Error())
+		return
+	}
+	
+	tokens := make([]string, len(apps))
+	for i, app := range apps {
+		tokens[i] = app.Token
+	}
+	
+	counters, err := h.counterSvc.GetChatCounters(tokens)
+	if err != nil {
+		log.Printf("Warning: Falling back to chats_count: %v", err)
+	}
+	
+	response := models.ApplicationListResponse{
+		Applications: make([]models.ApplicationResponse, 0, len(apps)),
+		Pagination:   paginationMeta(page, perPage, total),
+	}
+	
+	for _, app := range apps {
+		response.Applications = append(response.Applications, applicationResponse(app, counters))
+	}
+	
+	respondJSON(w, http.StatusOK, response)
+}
+
+// chatCounters loads one application's Redis chat counter, logging and
+// falling back to the column when Redis is unavailable
+func (h *ApplicationHandler) chatCounters(app models.Application) map[string]int64 {
+	counters, err := h.counterSvc.GetChatCounters([]string{app.Token})
+	if err != nil {
+		log.Printf("Warning: Falling back to chats_count: %v", err)
+	}
+	return counters
+}
+
+// applicationResponse reports the Redis chat counter when it is ahead of the
+// periodically synced chats_count column, the same value Rails reports once
+// its sync worker has run
+func applicationResponse(app models.Application, counters map[string]int64) models.ApplicationResponse {
+	chatsCount := app.ChatsCount
+	if counter, ok := counters[app.Token]; ok && int(counter) > chatsCount {
+		chatsCount = int(counter)
+	}
+	
+	return models.ApplicationResponse{
+		Token:      app.Token,
+		Name:       app.Name,
+		ChatsCount: chatsCount,
+		CreatedAt:  app.CreatedAt,
+		UpdatedAt:  app.UpdatedAt,
+	}
+}
+
+// decodeApplicationName accepts both {"name": ...} and the Rails-style
+// {"application": {"name": ...}} body, or application[name] in a form
+func decodeApplicationName(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var body struct {
+		models.ApplicationRequest
+		Application *models.ApplicationRequest `json:"application"`
+	}
+	if !decodeRequest(w, r, &body) {
+		return "", false
+	}
+	
+	if body.Application != nil {
+		return body.Application.Name, true
+	}
+	return body.Name, true
+}
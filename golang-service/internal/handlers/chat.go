@@ -1,44 +1,50 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/messaging"
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+	"github.com/gorilla/mux"
 )
 
 type ChatHandler struct {
 	appRepo      *repository.ApplicationRepository
 	chatRepo     *repository.ChatRepository
 	counterSvc   *services.CounterService
+	readRepo     *repository.ReadReceiptRepository
+	messagingSvc *messaging.Service
 }
 
 func NewChatHandler(
 	appRepo *repository.ApplicationRepository,
 	chatRepo *repository.ChatRepository,
 	counterSvc *services.CounterService,
+	readRepo *repository.ReadReceiptRepository,
+	messagingSvc *messaging.Service,
 ) *ChatHandler {
 	return &ChatHandler{
-		appRepo:    appRepo,
-		chatRepo:   chatRepo,
-		counterSvc: counterSvc,
+		appRepo:      appRepo,
+		chatRepo:     chatRepo,
+		counterSvc:   counterSvc,
+		readRepo:     readRepo,
+		messagingSvc: messagingSvc,
 	}
 }
 
 func (h *ChatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Parse request
 	var req models.ChatCreateRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 	
-	// Validate token
-	if err := services.ValidateToken(req.ApplicationToken); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid token", err.Error())
+	// Validate inputs
+	if !validateRequest(w, r, http.StatusBadRequest, "Invalid request", &req) {
 		return
 	}
 	
@@ -48,36 +54,127 @@ func (h *ChatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusNotFound, "Application not found", err.Error())
 		return
 	}
+	services.TagUsage(r.Context(), app.ID)
 	
-	// Get next chat number (atomic)
-	chatNumber, err := h.counterSvc.GetNextChatNumber(app.Token)
-	if err != nil {
+	// Allocate the number within the chat quota and store the chat
+	chat, err := h.messagingSvc.CreateChat(app)
+	var quotaErr *services.QuotaError
+	if errors.As(err, &quotaErr) {
+		respondQuotaError(w, quotaErr)
+		return
+	}
+	if errors.Is(err, messaging.ErrNumber) {
 		log.Printf("Error getting chat number: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to generate chat number", err.Error())
 		return
 	}
-	
-	// Create chat in database
-	chat, err := h.chatRepo.Create(app.ID, int(chatNumber))
 	if err != nil {
 		log.Printf("Error creating chat: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to create chat", err.Error())
 		return
 	}
 	
-	// Initialize message counter for this chat
-	if err := h.counterSvc.InitializeMessageCounter(chat.ID); err != nil {
-		log.Printf("Warning: Failed to initialize message counter: %v", err)
+	respondJSON(w, http.StatusCreated, messaging.ChatResponse(chat))
+}
+
+// List handles GET /api/v1/applications/{token}/chats. When user_id is given,
+// each chat carries that user's read marker and unread count.
+func (h *ChatHandler) List(w http.ResponseWriter, r *http.Request) {
+	token := mux.Vars(r)["token"]
+	userID := r.URL.Query().Get("user_id")
+	
+	// Validate inputs
+	if err := services.ValidateToken(token); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid token", err.Error())
+		return
+	}
+	
+	page, perPage, err := parsePagination(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid pagination", err.Error())
+		return
+	}
+	
+	if userID != "" {
+		if err := services.ValidateUserID(userID); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid user", err.Error())
+			return
+		}
 	}
 	
-	// Respond
-	response := models.ChatResponse{
-		Number:        chat.Number,
-		MessagesCount: chat.MessagesCount,
-		CreatedAt:     chat.CreatedAt,
-		UpdatedAt:     chat.UpdatedAt,
+	// Get application
+	app, err := h.appRepo.GetByToken(token)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Application not found", err.Error())
+		return
 	}
 	
-	log.Printf("✅ Created chat #%d for app %s", chatNumber, app.Token)
-	respondJSON(w, http.StatusCreated, response)
+	total, err := h.chatRepo.CountByApplication(app.ID)
+	if err != nil {
+		log.Printf("Error counting chats: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to list chats", err.Error())
+		return
+	}
+	
+	chats, err := h.chatRepo.ListByApplication(app.ID, (page-1)*perPage, perPage)
+	if err != nil {
+		log.Printf("Error listing chats: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to list chats", err.Error())
+		return
+	}
+	
+	chatIDs := make([]int64, len(chats))
+	for i, chat := range chats {
+		chatIDs[i] = chat.ID
+	}
+	
+	var markers, unread map[int64]int
+	if userID != "" {
+		if markers, err = h.readRepo.GetForChats(userID, chatIDs); err != nil {
+			log.Printf("Error loading read receipts: %v", err)
+			respondError(w, http.StatusInternalServerError, "Failed to list chats", err.Error())
+			return
+		}
+		
+		if unread, err = h.readRepo.UnreadCounts(userID, chatIDs); err != nil {
+			log.Printf("Error counting unread messages: %v", err)
+			respondError(w, http.StatusInternalServerError, "Failed to list chats", err.Error())
+			return
+		}
+	}
+	
+	response := models.ChatListResponse{
+		Chats:      make([]models.ChatResponse, 0, len(chats)),
+		Pagination: paginationMeta(page, perPage, total),
+	}
+	
+	for _, chat := range chats {
+		item := models.ChatResponse{
+			Number:        chat.Number,
+			MessagesCount: chat.MessagesCount,
+			CreatedAt:     chat.CreatedAt,
+			UpdatedAt:     chat.UpdatedAt,
+		}
+		
+		if userID != "" {
+			lastRead := markers[chat.ID]
+			unreadCount := unread[chat.ID]
+			item.LastReadNumber = &lastRead
+			item.UnreadCount = &unreadCount
+		}
+		
+		response.Chats = append(response.Chats, item)
+	}
+	
+	respondJSON(w, http.StatusOK, response)
+}
+
+// newestNumber prefers the Redis counter, which is ahead of the periodically
+// synced messages_count column
+func newestNumber(chat models.Chat, latest map[int64]int64) int {
+	newest := chat.MessagesCount
+	if counter, ok := latest[chat.ID]; ok && int(counter) > newest {
+		newest = int(counter)
+	}
+	return newest
 }
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+)
+
+// decodeRequest reads a JSON or form-encoded body into req and answers the
+// request itself when it cannot: 415 for other content types, 413 past
+// security.max_body_bytes and 400 for malformed bodies. With
+// security.strict_json, fields req does not declare are rejected.
+func decodeRequest(w http.ResponseWriter, r *http.Request, req any) bool {
+	strict := false
+	if cfg := config.FromContext(r.Context()); cfg != nil {
+		strict = cfg.Security.StrictJSON
+	}
+	
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		mediaType = ""
+	}
+	
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		err = decodeJSON(r.Body, req, strict)
+	case mediaType == "application/x-www-form-urlencoded":
+		// Legacy clients post forms; Rails-style keys like application[name] work
+		err = r.ParseForm()
+		if err == nil {
+			err = decodeForm(r.PostForm, reflect.ValueOf(req).Elem(), strict)
+		}
+	default:
+		respondError(w, http.StatusUnsupportedMediaType, "Unsupported media type",
+			"Content-Type must be application/json or application/x-www-form-urlencoded")
+		return false
+	}
+	if err == nil {
+		return true
+	}
+	
+	var tooLarge *http.MaxBytesError
+	var fieldErr *bodyError
+	switch {
+	case errors.As(err, &tooLarge):
+		respondError(w, http.StatusRequestEntityTooLarge, "Request body too large",
+			fmt.Sprintf("request body must be at most %d bytes", tooLarge.Limit))
+	case errors.As(err, &fieldErr):
+		respondFieldError(w, http.StatusBadRequest, "Invalid request body", fieldErr.FieldError)
+	default:
+		respondError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+	return false
+}
+
+// decodeJSON decodes exactly one JSON value
+func decodeJSON(body io.Reader, req any, strict bool) error {
+	decoder := json.NewDecoder(body)
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	
+	if err := decoder.Decode(req); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.Is(err, io.EOF):
+			return fmt.Errorf("request body is empty")
+		case errors.As(err, &typeErr) && typeErr.Field != "":
+			return &bodyError{models.FieldError{
+				Field:   typeErr.Field,
+				Code:    "type",
+				Message: fmt.Sprintf("%s must be %s", typeErr.Field, jsonKind(typeErr.Type)),
+			}}
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+			return unknownField(field)
+		}
+		return err
+	}
+	
+	// A second value, even whitespace-separated, means the body was not one object
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return err
+		}
+		return fmt.Errorf("request body must contain a single JSON value")
+	}
+	return nil
+}
+
+// bodyError is a decoding failure that names the field at fault
+type bodyError struct {
+	models.FieldError
+}
+
+func (e *bodyError) Error() string {
+	return e.Message
+}
+
+func unknownField(field string) *bodyError {
+	return &bodyError{models.FieldError{Field: field, Code: "unknown", Message: field + " is not a known field"}}
+}
+
+// jsonKind names the JSON type a Go type decodes from
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct, reflect.Pointer:
+		return "an object"
+	}
+	return "a number"
+}
+
+// decodeForm sets struct fields from form values keyed by their JSON names.
+// Nested structs use parent[child] keys; slices take every value of a key,
+// with or without a trailing [].
+func decodeForm(form url.Values, v reflect.Value, strict bool) error {
+	for _, key := range slices.Sorted(maps.Keys(form)) {
+		values := form[key]
+		field, ok := formField(v, strings.TrimSuffix(key, "[]"))
+		if !ok {
+			if strict {
+				return unknownField(key)
+			}
+			continue
+		}
+	
+		if err := setFormValue(field, values); err != nil {
+			return &bodyError{models.FieldError{Field: key, Code: "type", Message: fmt.Sprintf("%s %s", key, err)}}
+		}
+	}
+	return nil
+}
+
+// formField finds the field named by a key such as "name" or
+// "application[name]", allocating nested structs on the way
+func formField(v reflect.Value, key string) (reflect.Value, bool) {
+	name, rest, nested := strings.Cut(key, "[")
+	field, ok := jsonField(v, name)
+	if !ok || !nested {
+		return field, ok
+	}
+	
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = field.Elem()
+	}
+	if field.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	
+	child, after, ok := strings.Cut(rest, "]")
+	if !ok {
+		return reflect.Value{}, false
+	}
+	return formField(field, child+after)
+}
+
+// jsonField finds a struct field by its JSON name, looking through
+// embedded structs the way encoding/json does
+func jsonField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+	
+		tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			if found, ok := jsonField(v.Field(i), name); ok {
+				return found, true
+			}
+			continue
+		}
+		if tag == "" {
+			tag = field.Name
+		}
+		if tag == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func setFormValue(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String {
+		field.Set(reflect.ValueOf(append([]string(nil), values...)))
+		return nil
+	}
+	
+	value := values[len(values)-1]
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		field.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be a boolean")
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("cannot be sent as a form value")
+	}
+	return nil
+}
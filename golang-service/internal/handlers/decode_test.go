@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/middleware"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+)
+
+type decodeTarget struct {
+	Name        string   `json:"name"`
+	Count       int      `json:"count"`
+	Events      []string `json:"events"`
+	Application *struct {
+		Name string `json:"name"`
+	} `json:"application"`
+}
+
+// decodeServer runs decodeRequest behind the same config and body size
+// middleware as the real routes, echoing what it decoded
+func decodeServer(t *testing.T, strict bool) *httptest.Server {
+	t.Helper()
+	cfg := config.Defaults()
+	cfg.Security.StrictJSON = strict
+	cfg.Security.MaxBodyBytes = 128
+	store := config.NewStore("", cfg)
+	
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req decodeTarget
+		if !decodeRequest(w, r, &req) {
+			return
+		}
+		respondJSON(w, http.StatusOK, req)
+	})
+	server := httptest.NewServer(middleware.ConfigMiddleware(store)(middleware.RequestSizeMiddleware(store)(handler)))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func post(t *testing.T, server *httptest.Server, contentType, body string) (int, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		t.Fatalf("response is not JSON: %v", err)
+	}
+	return resp.StatusCode, raw
+}
+
+func TestDecodeRequestContentType(t *testing.T) {
+	server := decodeServer(t, false)
+	
+	tests := []struct {
+		contentType string
+		want        int
+	}{
+		{"application/json", http.StatusOK},
+		{"application/json; charset=utf-8", http.StatusOK},
+		{"application/merge-patch+json", http.StatusOK},
+		{"", http.StatusUnsupportedMediaType},
+		{"text/plain", http.StatusUnsupportedMediaType},
+		{"multipart/form-data; boundary=x", http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			if status, body := post(t, server, tt.contentType, `{"name":"chat"}`); status != tt.want {
+				t.Errorf("status = %d, want %d (%s)", status, tt.want, body)
+			}
+		})
+	}
+}
+
+func TestDecodeRequestBodyTooLarge(t *testing.T) {
+	server := decodeServer(t, false)
+	long := strings.Repeat("x", 200)
+	
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"json", "application/json", `{"name":"` + long + `"}`},
+		{"json past the first value", "application/json", `{"name":"a"}` + strings.Repeat(" ", 200)},
+		{"form", "application/x-www-form-urlencoded", "name=" + long},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := post(t, server, tt.contentType, tt.body)
+			if status != http.StatusRequestEntityTooLarge {
+				t.Errorf("status = %d, want 413 (%s)", status, body)
+			}
+		})
+	}
+}
+
+func TestDecodeRequestUnknownFields(t *testing.T) {
+	tests := []struct {
+		name        string
+		strict      bool
+		contentType string
+		body        string
+		want        int
+		field       string
+	}{
+		{"json lenient", false, "application/json", `{"name":"a","extra":1}`, http.StatusOK, ""},
+		{"json strict", true, "application/json", `{"name":"a","extra":1}`, http.StatusBadRequest, "extra"},
+		{"form lenient", false, "application/x-www-form-urlencoded", "name=a&extra=1", http.StatusOK, ""},
+		{"form strict", true, "application/x-www-form-urlencoded", "name=a&extra=1", http.StatusBadRequest, "extra"},
+		{"form strict nested", true, "application/x-www-form-urlencoded", "application[extra]=1", http.StatusBadRequest, "application[extra]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := post(t, decodeServer(t, tt.strict), tt.contentType, tt.body)
+			if status != tt.want {
+				t.Fatalf("status = %d, want %d (%s)", status, tt.want, body)
+			}
+			if tt.field == "" {
+				return
+			}
+	
+			var resp models.ErrorResponse
+			json.Unmarshal(body, &resp)
+			if len(resp.Errors) != 1 || resp.Errors[0].Field != tt.field || resp.Errors[0].Code != "unknown" {
+				t.Errorf("errors = %+v, want unknown field %s", resp.Errors, tt.field)
+			}
+		})
+	}
+}
+
+func TestDecodeRequestForm(t *testing.T) {
+	server := decodeServer(t, true)
+	
+	status, body := post(t, server, "application/x-www-form-urlencoded",
+		"application%5Bname%5D=Legacy&events%5B%5D=a&events%5B%5D=b&count=3&name=x")
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", status, body)
+	}
+	
+	var got decodeTarget
+	json.Unmarshal(body, &got)
+	if got.Application == nil || got.Application.Name != "Legacy" {
+		t.Errorf("application[name] decoded as %+v", got.Application)
+	}
+	if !reflect.DeepEqual(got.Events, []string{"a", "b"}) || got.Count != 3 || got.Name != "x" {
+		t.Errorf("decoded %+v", got)
+	}
+}
+
+func TestDecodeRequestMalformed(t *testing.T) {
+	server := decodeServer(t, false)
+	
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		field       string
+	}{
+		{"empty", "application/json", "", ""},
+		{"two values", "application/json", `{"name":"a"} {}`, ""},
+		{"json wrong type", "application/json", `{"count":"three"}`, "count"},
+		{"form wrong type", "application/x-www-form-urlencoded", "count=three", "count"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := post(t, server, tt.contentType, tt.body)
+			if status != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400 (%s)", status, body)
+			}
+	
+			var resp models.ErrorResponse
+			json.Unmarshal(body, &resp)
+			if tt.field != "" && (len(resp.Errors) != 1 || resp.Errors[0].Field != tt.field || resp.Errors[0].Code != "type") {
+				t.Errorf("errors = %+v, want a type error on %s", resp.Errors, tt.field)
+			}
+		})
+	}
+}
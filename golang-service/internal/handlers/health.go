@@ -1,25 +1,34 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"time"
 	
 
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/database"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/middleware"
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
 )
 
-type HealthHandler struct{}
+type HealthHandler struct {
+	readiness *middleware.Readiness
+}
 
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+func NewHealthHandler(readiness *middleware.Readiness) *HealthHandler {
+	return &HealthHandler{readiness: readiness}
 }
 
 func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	checks := make(map[string]string)
 	status := "healthy"
 	
+	// Bound the pings so an unreachable dependency cannot hang the check
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+	
 	// Check MySQL
-	if err := database.DB.Ping(); err != nil {
+	if err := database.DB.PingContext(ctx); err != nil {
 		checks["mysql"] = "unhealthy"
 		status = "unhealthy"
 	} else {
@@ -27,13 +36,18 @@ func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	// Check Redis
-	if err := database.RedisClient.Ping(database.Ctx).Err(); err != nil {
+	if err := database.RedisClient.Ping(ctx).Err(); err != nil {
 		checks["redis"] = "unhealthy"
 		status = "unhealthy"
 	} else {
 		checks["redis"] = "ok"
 	}
 	
+	// Still connecting in the background
+	if !h.readiness.Ready() {
+		status = "starting"
+	}
+	
 	response := models.HealthResponse{
 		Status:  status,
 		Service: "golang-chat-service",
@@ -42,7 +56,7 @@ func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	statusCode := http.StatusOK
-	if status == "unhealthy" {
+	if status != "healthy" {
 		statusCode = http.StatusServiceUnavailable
 	}
 	
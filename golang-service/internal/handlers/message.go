@@ -1,58 +1,48 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	
 
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/messaging"
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+	"github.com/gorilla/mux"
 )
 
 type MessageHandler struct {
-	appRepo     *repository.ApplicationRepository
-	chatRepo    *repository.ChatRepository
-	messageRepo *repository.MessageRepository
-	counterSvc  *services.CounterService
+	appRepo      *repository.ApplicationRepository
+	chatRepo     *repository.ChatRepository
+	quotaSvc     *services.QuotaService
+	messagingSvc *messaging.Service
 }
 
 func NewMessageHandler(
 	appRepo *repository.ApplicationRepository,
 	chatRepo *repository.ChatRepository,
-	messageRepo *repository.MessageRepository,
-	counterSvc *services.CounterService,
+	quotaSvc *services.QuotaService,
+	messagingSvc *messaging.Service,
 ) *MessageHandler {
 	return &MessageHandler{
-		appRepo:     appRepo,
-		chatRepo:    chatRepo,
-		messageRepo: messageRepo,
-		counterSvc:  counterSvc,
+		appRepo:      appRepo,
+		chatRepo:     chatRepo,
+		quotaSvc:     quotaSvc,
+		messagingSvc: messagingSvc,
 	}
 }
 
 func (h *MessageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Parse request
 	var req models.MessageCreateRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+	if !decodeRequest(w, r, &req) {
 		return
 	}
 	
 	// Validate inputs
-	if err := services.ValidateToken(req.ApplicationToken); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid token", err.Error())
-		return
-	}
-	
-	if err := services.ValidateChatNumber(req.ChatNumber); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid chat number", err.Error())
-		return
-	}
-	
-	if err := services.ValidateMessageBody(req.Body); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid message body", err.Error())
+	if !validateRequest(w, r, http.StatusBadRequest, "Invalid request", &req) {
 		return
 	}
 	
@@ -62,6 +52,7 @@ func (h *MessageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusNotFound, "Application not found", err.Error())
 		return
 	}
+	services.TagUsage(r.Context(), app.ID)
 	
 	// Get chat
 	chat, err := h.chatRepo.GetByApplicationAndNumber(app.ID, req.ChatNumber)
@@ -70,30 +61,130 @@ func (h *MessageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// Get next message number (atomic)
-	messageNumber, err := h.counterSvc.GetNextMessageNumber(chat.ID)
-	if err != nil {
+	// Allocate the number within quotas, store, index and announce
+	message, err := h.messagingSvc.CreateMessage(app, chat, req.Body)
+	var quotaErr *services.QuotaError
+	if errors.As(err, &quotaErr) {
+		respondQuotaError(w, quotaErr)
+		return
+	}
+	if errors.Is(err, messaging.ErrNumber) {
 		log.Printf("Error getting message number: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to generate message number", err.Error())
 		return
 	}
-	
-	// Create message in database
-	message, err := h.messageRepo.Create(chat.ID, int(messageNumber), req.Body)
 	if err != nil {
 		log.Printf("Error creating message: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to create message", err.Error())
 		return
 	}
 	
-	// Respond
-	response := models.MessageResponse{
-		Number:    message.Number,
-		Body:      message.Body,
-		CreatedAt: message.CreatedAt,
-		UpdatedAt: message.UpdatedAt,
+	respondJSON(w, http.StatusCreated, messaging.MessageResponse(message))
+}
+
+// Update handles PATCH/PUT /api/v1/applications/{token}/chats/{chat_number}/messages/{number}
+func (h *MessageHandler) Update(w http.ResponseWriter, r *http.Request) {
+	// Parse request
+	var req models.MessageUpdateRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	
+	// Validate inputs
+	if !validateRequest(w, r, http.StatusBadRequest, "Invalid request", &req) {
+		return
+	}
+	
+	app, chat, number, ok := h.resolve(w, r)
+	if !ok {
+		return
+	}
+	
+	err := h.quotaSvc.CheckMessageBody(app, req.Body)
+	var quotaErr *services.QuotaError
+	if errors.As(err, &quotaErr) {
+		respondQuotaError(w, quotaErr)
+		return
+	}
+	if err != nil {
+		log.Printf("Error checking message quota: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update message", err.Error())
+		return
+	}
+	
+	message, err := h.messagingSvc.UpdateMessage(app, chat, number, req.Body)
+	if errors.Is(err, repository.ErrMessageNotFound) {
+		respondError(w, http.StatusNotFound, "Message not found", err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating message: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update message", err.Error())
+		return
+	}
+	
+	respondJSON(w, http.StatusOK, messaging.MessageResponse(message))
+}
+
+// Delete handles DELETE /api/v1/applications/{token}/chats/{chat_number}/messages/{number}
+func (h *MessageHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	app, chat, number, ok := h.resolve(w, r)
+	if !ok {
+		return
+	}
+	
+	_, err := h.messagingSvc.DeleteMessage(app, chat, number)
+	if errors.Is(err, repository.ErrMessageNotFound) {
+		respondError(w, http.StatusNotFound, "Message not found", err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting message: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to delete message", err.Error())
+		return
+	}
+	
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// resolve validates the route variables and loads the application and chat
+func (h *MessageHandler) resolve(w http.ResponseWriter, r *http.Request) (*models.Application, *models.Chat, int, bool) {
+	vars := mux.Vars(r)
+	
+	// Validate inputs
+	if err := services.ValidateToken(vars["token"]); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid token", err.Error())
+		return nil, nil, 0, false
+	}
+	
+	chatNumber, err := pathInt(vars, "chat_number")
+	if err == nil {
+		err = services.ValidateChatNumber(chatNumber)
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid chat number", err.Error())
+		return nil, nil, 0, false
+	}
+	
+	messageNumber, err := pathInt(vars, "number")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid message number", err.Error())
+		return nil, nil, 0, false
+	}
+	
+	// Get application
+	app, err := h.appRepo.GetByToken(vars["token"])
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Application not found", err.Error())
+		return nil, nil, 0, false
+	}
+	
+	// Get chat
+	chat, err := h.chatRepo.GetByApplicationAndNumber(app.ID, chatNumber)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Chat not found", err.Error())
+		return nil, nil, 0, false
 	}
 	
-	log.Printf("✅ Created message #%d for chat %d", messageNumber, chat.ID)
-	respondJSON(w, http.StatusCreated, response)
+	return app, chat, messageNumber, true
 }
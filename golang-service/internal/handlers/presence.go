@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+	"github.com/gorilla/mux"
+)
+
+type PresenceHandler struct {
+	appRepo     *repository.ApplicationRepository
+	chatRepo    *repository.ChatRepository
+	presenceSvc *services.PresenceService
+}
+
+func NewPresenceHandler(
+	appRepo *repository.ApplicationRepository,
+	chatRepo *repository.ChatRepository,
+	presenceSvc *services.PresenceService,
+) *PresenceHandler {
+	return &PresenceHandler{
+		appRepo:     appRepo,
+		chatRepo:    chatRepo,
+		presenceSvc: presenceSvc,
+	}
+}
+
+// ServeHTTP handles GET .../chats/{chat_number}/presence
+func (h *PresenceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	
+	// Validate inputs
+	if err := services.ValidateToken(vars["token"]); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid token", err.Error())
+		return
+	}
+	
+	chatNumber, err := pathInt(vars, "chat_number")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid chat number", err.Error())
+		return
+	}
+	
+	// Get application
+	app, err := h.appRepo.GetByToken(vars["token"])
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Application not found", err.Error())
+		return
+	}
+	
+	// Get chat
+	chat, err := h.chatRepo.GetByApplicationAndNumber(app.ID, chatNumber)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Chat not found", err.Error())
+		return
+	}
+	
+	snapshot, err := h.presenceSvc.Snapshot(chat.ID)
+	if err != nil {
+		log.Printf("Error reading presence: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to read presence", err.Error())
+		return
+	}
+	
+	respondJSON(w, http.StatusOK, snapshot)
+}
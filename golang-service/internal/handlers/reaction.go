@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+	"github.com/gorilla/mux"
+)
+
+type ReactionHandler struct {
+	appRepo     *repository.ApplicationRepository
+	chatRepo    *repository.ChatRepository
+	messageRepo *repository.MessageRepository
+	reactionSvc *services.ReactionService
+}
+
+func NewReactionHandler(
+	appRepo *repository.ApplicationRepository,
+	chatRepo *repository.ChatRepository,
+	messageRepo *repository.MessageRepository,
+	reactionSvc *services.ReactionService,
+) *ReactionHandler {
+	return &ReactionHandler{
+		appRepo:     appRepo,
+		chatRepo:    chatRepo,
+		messageRepo: messageRepo,
+		reactionSvc: reactionSvc,
+	}
+}
+
+// Add handles POST .../messages/{number}/reactions
+func (h *ReactionHandler) Add(w http.ResponseWriter, r *http.Request) {
+	chat, message, req, ok := h.resolve(w, r)
+	if !ok {
+		return
+	}
+	
+	err := h.reactionSvc.Add(chat.ID, message.ID, req.UserID, req.Emoji)
+	if errors.Is(err, repository.ErrReactionExists) {
+		respondError(w, http.StatusConflict, "Reaction already exists", err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error adding reaction: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to add reaction", err.Error())
+		return
+	}
+	
+	h.respondMessage(w, http.StatusCreated, chat, message)
+}
+
+// Remove handles DELETE .../messages/{number}/reactions
+func (h *ReactionHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	chat, message, req, ok := h.resolve(w, r)
+	if !ok {
+		return
+	}
+	
+	err := h.reactionSvc.Remove(chat.ID, message.ID, req.UserID, req.Emoji)
+	if errors.Is(err, repository.ErrReactionNotFound) {
+		respondError(w, http.StatusNotFound, "Reaction not found", err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error removing reaction: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to remove reaction", err.Error())
+		return
+	}
+	
+	h.respondMessage(w, http.StatusOK, chat, message)
+}
+
+// resolve parses and validates the request and loads the target message
+func (h *ReactionHandler) resolve(w http.ResponseWriter, r *http.Request) (*models.Chat, *models.Message, *models.ReactionRequest, bool) {
+	vars := mux.Vars(r)
+	
+	// Parse request
+	var req models.ReactionRequest
+	if !decodeRequest(w, r, &req) {
+		return nil, nil, nil, false
+	}
+	
+	// Validate inputs
+	if err := services.ValidateToken(vars["token"]); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid token", err.Error())
+		return nil, nil, nil, false
+	}
+	
+	chatNumber, err := pathInt(vars, "chat_number")
+	if err == nil {
+		err = services.ValidateChatNumber(chatNumber)
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid chat number", err.Error())
+		return nil, nil, nil, false
+	}
+	
+	messageNumber, err := pathInt(vars, "number")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid message number", err.Error())
+		return nil, nil, nil, false
+	}
+	
+	if !validateRequest(w, r, http.StatusBadRequest, "Invalid request", &req) {
+		return nil, nil, nil, false
+	}
+	
+	// Get application
+	app, err := h.appRepo.GetByToken(vars["token"])
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Application not found", err.Error())
+		return nil, nil, nil, false
+	}
+	
+	// Get chat
+	chat, err := h.chatRepo.GetByApplicationAndNumber(app.ID, chatNumber)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Chat not found", err.Error())
+		return nil, nil, nil, false
+	}
+	
+	// Get message
+	message, err := h.messageRepo.GetByChatAndNumber(chat.ID, messageNumber)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Message not found", err.Error())
+		return nil, nil, nil, false
+	}
+	
+	return chat, message, &req, true
+}
+
+func (h *ReactionHandler) respondMessage(w http.ResponseWriter, status int, chat *models.Chat, message *models.Message) {
+	counts, err := h.reactionSvc.Counts(chat.ID, message.ID)
+	if err != nil {
+		log.Printf("Warning: Failed to load reaction counts: %v", err)
+	}
+	
+	response := models.MessageResponse{
+		Number:    message.Number,
+		Body:      message.Body,
+		Reactions: counts,
+		CreatedAt: message.CreatedAt,
+		UpdatedAt: message.UpdatedAt,
+	}
+	
+	respondJSON(w, status, response)
+}
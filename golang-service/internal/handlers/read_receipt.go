@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+	"github.com/gorilla/mux"
+)
+
+type ReadReceiptHandler struct {
+	appRepo    *repository.ApplicationRepository
+	chatRepo   *repository.ChatRepository
+	readRepo   *repository.ReadReceiptRepository
+	counterSvc *services.CounterService
+}
+
+func NewReadReceiptHandler(
+	appRepo *repository.ApplicationRepository,
+	chatRepo *repository.ChatRepository,
+	readRepo *repository.ReadReceiptRepository,
+	counterSvc *services.CounterService,
+) *ReadReceiptHandler {
+	return &ReadReceiptHandler{
+		appRepo:    appRepo,
+		chatRepo:   chatRepo,
+		readRepo:   readRepo,
+		counterSvc: counterSvc,
+	}
+}
+
+// ServeHTTP handles PUT .../chats/{chat_number}/read
+func (h *ReadReceiptHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	
+	// Parse request
+	var req models.ReadReceiptRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	
+	// Validate inputs
+	if err := services.ValidateToken(vars["token"]); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid token", err.Error())
+		return
+	}
+	
+	chatNumber, err := pathInt(vars, "chat_number")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid chat number", err.Error())
+		return
+	}
+	
+	if !validateRequest(w, r, http.StatusBadRequest, "Invalid request", &req) {
+		return
+	}
+	
+	// Get application
+	app, err := h.appRepo.GetByToken(vars["token"])
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Application not found", err.Error())
+		return
+	}
+	
+	// Get chat
+	chat, err := h.chatRepo.GetByApplicationAndNumber(app.ID, chatNumber)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Chat not found", err.Error())
+		return
+	}
+	
+	// Clients cannot read past the newest message
+	latest, err := h.counterSvc.GetMessageCounters([]int64{chat.ID})
+	if err != nil {
+		log.Printf("Warning: Falling back to messages_count: %v", err)
+	}
+	newest := newestNumber(*chat, latest)
+	if req.LastReadNumber > newest {
+		respondError(w, http.StatusUnprocessableEntity, "Invalid read marker", "last_read_number is beyond the newest message")
+		return
+	}
+	
+	lastRead, err := h.readRepo.Advance(chat.ID, req.UserID, req.LastReadNumber)
+	if err != nil {
+		log.Printf("Error updating read receipt: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update read receipt", err.Error())
+		return
+	}
+	
+	unread, err := h.readRepo.UnreadCounts(req.UserID, []int64{chat.ID})
+	if err != nil {
+		log.Printf("Error counting unread messages: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update read receipt", err.Error())
+		return
+	}
+	
+	response := models.ReadReceiptResponse{
+		ChatNumber:     chat.Number,
+		UserID:         req.UserID,
+		LastReadNumber: lastRead,
+		UnreadCount:    unread[chat.ID],
+	}
+	
+	respondJSON(w, http.StatusOK, response)
+}
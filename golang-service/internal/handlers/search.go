@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/search"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+	"github.com/gorilla/mux"
+)
+
+type SearchHandler struct {
+	appRepo     *repository.ApplicationRepository
+	chatRepo    *repository.ChatRepository
+	searchIndex search.SearchIndex
+}
+
+func NewSearchHandler(
+	appRepo *repository.ApplicationRepository,
+	chatRepo *repository.ChatRepository,
+	searchIndex search.SearchIndex,
+) *SearchHandler {
+	return &SearchHandler{
+		appRepo:     appRepo,
+		chatRepo:    chatRepo,
+		searchIndex: searchIndex,
+	}
+}
+
+// ServeHTTP handles GET .../chats/{chat_number}/messages/search?q=&mode=
+func (h *SearchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	query := r.URL.Query().Get("q")
+	
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = search.ModeNatural
+	}
+	
+	// Validate inputs
+	if err := services.ValidateToken(vars["token"]); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid token", err.Error())
+		return
+	}
+	
+	chatNumber, err := pathInt(vars, "chat_number")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid chat number", err.Error())
+		return
+	}
+	
+	if err := services.ValidateSearchQuery(query); err != nil {
+		respondError(w, http.StatusBadRequest, "Query parameter required", err.Error())
+		return
+	}
+	
+	if mode != search.ModeNatural && mode != search.ModeBoolean {
+		respondError(w, http.StatusBadRequest, "Invalid search mode", "mode must be natural or boolean")
+		return
+	}
+	
+	page, perPage, err := parsePagination(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid pagination", err.Error())
+		return
+	}
+	
+	// Get application
+	app, err := h.appRepo.GetByToken(vars["token"])
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Application not found", err.Error())
+		return
+	}
+	
+	// Get chat
+	chat, err := h.chatRepo.GetByApplicationAndNumber(app.ID, chatNumber)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Chat not found", err.Error())
+		return
+	}
+	
+	result, err := h.searchIndex.Query(r.Context(), search.Query{
+		ChatID: chat.ID,
+		Text:   query,
+		Mode:   mode,
+		Offset: (page - 1) * perPage,
+		Limit:  perPage,
+	})
+	if err != nil {
+		log.Printf("Error searching messages: %v", err)
+		respondError(w, http.StatusServiceUnavailable, "Search service error", err.Error())
+		return
+	}
+	
+	terms := services.SearchTerms(query)
+	response := models.SearchResponse{
+		Results:      make([]models.SearchResult, 0, len(result.Hits)),
+		Query:        query,
+		Total:        result.Total,
+		SearchEngine: h.searchIndex.Name(),
+		Pagination:   paginationMeta(page, perPage, result.Total),
+	}
+	
+	for _, hit := range result.Hits {
+		snippet := hit.Highlight
+		if snippet == "" {
+			snippet = services.Snippet(hit.Body, terms)
+		}
+		
+		// Documents indexed before updated_at was added only carry created_at
+		updatedAt := hit.UpdatedAt
+		if updatedAt.IsZero() {
+			updatedAt = hit.CreatedAt
+		}
+		response.Results = append(response.Results, models.SearchResult{
+			MessageResponse: models.MessageResponse{
+				Number:    hit.Number,
+				Body:      hit.Body,
+				CreatedAt: hit.CreatedAt,
+				UpdatedAt: updatedAt,
+			},
+			Snippet: snippet,
+		})
+	}
+	
+	respondJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// Comment frames keep proxies from closing idle connections
+	sseHeartbeatInterval = 15 * time.Second
+	
+	// Messages fetched per query while replaying history
+	sseReplayPageSize = 100
+	
+	// Reconnect delay suggested to EventSource clients
+	sseRetryMillis = 3000
+)
+
+type StreamHandler struct {
+	appRepo     *repository.ApplicationRepository
+	chatRepo    *repository.ChatRepository
+	messageRepo *repository.MessageRepository
+	hub         *services.Hub
+}
+
+func NewStreamHandler(
+	appRepo *repository.ApplicationRepository,
+	chatRepo *repository.ChatRepository,
+	messageRepo *repository.MessageRepository,
+	hub *services.Hub,
+) *StreamHandler {
+	return &StreamHandler{
+		appRepo:     appRepo,
+		chatRepo:    chatRepo,
+		messageRepo: messageRepo,
+		hub:         hub,
+	}
+}
+
+// ServeHTTP handles GET .../chats/{chat_number}/messages/stream
+func (h *StreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondError(w, http.StatusInternalServerError, "Streaming unsupported", "response writer cannot flush")
+		return
+	}
+	
+	// Validate inputs
+	if err := services.ValidateToken(vars["token"]); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid token", err.Error())
+		return
+	}
+	
+	chatNumber, err := pathInt(vars, "chat_number")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid chat number", err.Error())
+		return
+	}
+	
+	lastEventID, resuming, err := parseLastEventID(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid Last-Event-ID", err.Error())
+		return
+	}
+	
+	// Get application
+	app, err := h.appRepo.GetByToken(vars["token"])
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Application not found", err.Error())
+		return
+	}
+	
+	// Get chat
+	chat, err := h.chatRepo.GetByApplicationAndNumber(app.ID, chatNumber)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Chat not found", err.Error())
+		return
+	}
+	
+	// Subscribe before replaying so nothing created in between is lost
+	events := make(chan models.ChatEvent, 256)
+	if err := h.hub.Subscribe(chat.ID, events); err != nil {
+		log.Printf("Error subscribing to chat %d: %v", chat.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to subscribe", err.Error())
+		return
+	}
+	defer h.hub.Unsubscribe(chat.ID, events)
+	
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetryMillis)
+	flusher.Flush()
+	
+	// A reconnecting client gets the messages it missed; a new one starts
+	// from now. Replayed numbers are remembered so their live events are
+	// not sent twice, while messages committed out of number order still are.
+	replayed := make(map[int]bool)
+	for after := lastEventID; resuming; {
+		messages, err := h.messageRepo.ListAfter(chat.ID, after, sseReplayPageSize)
+		if err != nil {
+			log.Printf("Error replaying messages for chat %d: %v", chat.ID, err)
+			return
+		}
+		
+		for _, message := range messages {
+			event := models.ChatEvent{
+				Type:             models.EventMessageCreated,
+				ApplicationToken: app.Token,
+				ChatNumber:       chat.Number,
+				Message: &models.MessageResponse{
+					Number:    message.Number,
+					Body:      message.Body,
+					CreatedAt: message.CreatedAt,
+					UpdatedAt: message.UpdatedAt,
+				},
+			}
+			if err := writeSSE(w, event); err != nil {
+				return
+			}
+			replayed[message.Number] = true
+			after = message.Number
+		}
+		flusher.Flush()
+		
+		if len(messages) < sseReplayPageSize {
+			break
+		}
+	}
+	
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+	
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			// Skip creations already delivered by the replay
+			if event.Type == models.EventMessageCreated && event.Message != nil && replayed[event.Message.Number] {
+				delete(replayed, event.Message.Number)
+				continue
+			}
+			if err := writeSSE(w, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// parseLastEventID reads the resume point from the header set by EventSource,
+// falling back to a query parameter for clients that cannot set headers. ok
+// is false on a first connection, which has no resume point.
+func parseLastEventID(r *http.Request) (id int, ok bool, err error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, false, nil
+	}
+	
+	id, err = strconv.Atoi(value)
+	if err != nil || id < 0 {
+		return 0, false, fmt.Errorf("last event ID must be a non-negative integer")
+	}
+	return id, true, nil
+}
+
+// writeSSE writes one event. Only creations carry an ID, so edits of older
+// messages never move a client's resume point backwards.
+func writeSSE(w http.ResponseWriter, event models.ChatEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	
+	if event.Type == models.EventMessageCreated && event.Message != nil {
+		if _, err := fmt.Fprintf(w, "id: %d\n", event.Message.Number); err != nil {
+			return err
+		}
+	}
+	
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
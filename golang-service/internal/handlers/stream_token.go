@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+)
+
+// StreamTokenHandler issues short-lived tokens for opening WebSocket and
+// event-stream connections, which browsers cannot send the API key header on
+type StreamTokenHandler struct{}
+
+func NewStreamTokenHandler() *StreamTokenHandler {
+	return &StreamTokenHandler{}
+}
+
+// ServeHTTP handles POST /api/v1/stream_tokens
+func (h *StreamTokenHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cfg := config.FromContext(r.Context())
+	token, expiresAt := services.IssueStreamToken(cfg.Security.MasterAPIKey, time.Now())
+	
+	respondJSON(w, http.StatusCreated, models.StreamTokenResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+	"github.com/gorilla/mux"
+)
+
+// maxUsageRange bounds one usage request; use the CSV export for more
+const maxUsageRange = 31 * 24 * time.Hour
+
+type UsageHandler struct {
+	appRepo  *repository.ApplicationRepository
+	usageSvc *services.UsageService
+}
+
+func NewUsageHandler(
+	appRepo *repository.ApplicationRepository,
+	usageSvc *services.UsageService,
+) *UsageHandler {
+	return &UsageHandler{
+		appRepo:  appRepo,
+		usageSvc: usageSvc,
+	}
+}
+
+// ServeHTTP handles GET /api/v1/applications/{token}/usage?from=&to=
+func (h *UsageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := mux.Vars(r)["token"]
+	query := r.URL.Query()
+	
+	// Validate inputs
+	if err := services.ValidateToken(token); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid token", err.Error())
+		return
+	}
+	
+	from, to, err := services.ParseUsageRange(query.Get("from"), query.Get("to"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid range", err.Error())
+		return
+	}
+	if to.Sub(from) > maxUsageRange {
+		respondError(w, http.StatusBadRequest, "Invalid range", "range must be at most 31 days")
+		return
+	}
+	
+	// Get application
+	app, err := h.appRepo.GetByToken(token)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Application not found", err.Error())
+		return
+	}
+	
+	hours, totals, err := h.usageSvc.Report(app.ID, from, to)
+	if err != nil {
+		log.Printf("Error reading usage: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to read usage", err.Error())
+		return
+	}
+	if hours == nil {
+		hours = []models.UsageHour{}
+	}
+	
+	respondJSON(w, http.StatusOK, models.UsageResponse{
+		ApplicationToken: app.Token,
+		From:             from,
+		To:               to,
+		Totals:           totals,
+		Hours:            hours,
+	})
+}
@@ -2,9 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
 
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/validation"
 )
 
 func respondJSON(w http.ResponseWriter, statusCode int, data interface{}) {
@@ -21,3 +27,138 @@ func respondError(w http.ResponseWriter, statusCode int, message, details string
 	}
 	respondJSON(w, statusCode, response)
 }
+
+// requestTypes are the request bodies validateRequest checks
+var requestTypes = []any{
+	models.ApplicationRequest{},
+	models.ChatCreateRequest{},
+	models.MessageCreateRequest{},
+	models.MessageUpdateRequest{},
+	models.WebhookEndpointRequest{},
+	models.ReadReceiptRequest{},
+	models.ReactionRequest{},
+}
+
+// CheckRequestTags parses the validate tags of every request body, so a
+// mistake stops the service at startup instead of failing requests
+func CheckRequestTags() error {
+	for _, req := range requestTypes {
+		if err := validation.Check(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRequest checks req against its validate tags and, when fields
+// fail, answers with every failure in the errors array
+func validateRequest(w http.ResponseWriter, r *http.Request, status int, message string, req any) bool {
+	cfg := config.FromContext(r.Context())
+	if cfg == nil {
+		cfg = config.Defaults()
+	}
+	
+	errs, err := validation.Struct(req, services.ValidationParams(cfg))
+	if err != nil {
+		log.Printf("Error validating request: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to validate request", err.Error())
+		return false
+	}
+	if errs == nil {
+		return true
+	}
+	
+	respondJSON(w, status, models.ErrorResponse{
+		Error:   message,
+		Message: errs.Error(),
+		Status:  status,
+		Errors:  errs,
+	})
+	return false
+}
+
+// respondFieldError reports a single failing field in the validation
+// error shape
+func respondFieldError(w http.ResponseWriter, status int, message string, fieldErr models.FieldError) {
+	respondJSON(w, status, models.ErrorResponse{
+		Error:   message,
+		Message: fieldErr.Message,
+		Status:  status,
+		Errors:  []models.FieldError{fieldErr},
+	})
+}
+
+// respondQuotaError names the quota that rejected a request. Quotas that
+// reset answer 429 with Retry-After; hard plan limits answer 403.
+func respondQuotaError(w http.ResponseWriter, err *services.QuotaError) {
+	status := http.StatusForbidden
+	if err.RetryAfter > 0 {
+		status = http.StatusTooManyRequests
+		w.Header().Set("Retry-After", strconv.Itoa(int(err.RetryAfter.Seconds())+1))
+	}
+	
+	response := models.QuotaErrorResponse{
+		ErrorResponse: models.ErrorResponse{
+			Error:   "Quota exceeded",
+			Message: err.Error(),
+			Status:  status,
+		},
+		Quota: err.Quota,
+		Limit: err.Limit,
+		Plan:  err.Plan,
+	}
+	respondJSON(w, status, response)
+}
+
+// pathInt parses a positive integer route variable
+func pathInt(vars map[string]string, name string) (int, error) {
+	value, err := strconv.Atoi(vars[name])
+	if err != nil || value < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+	return value, nil
+}
+
+// pathID parses a positive row ID route variable
+func pathID(vars map[string]string, name string) (int64, error) {
+	value, err := strconv.ParseInt(vars[name], 10, 64)
+	if err != nil || value < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+	return value, nil
+}
+
+const (
+	defaultPerPage = 50
+	maxPerPage     = 100
+)
+
+// parsePagination reads page/per_page query parameters with Rails-compatible defaults
+func parsePagination(r *http.Request) (page, perPage int, err error) {
+	page, perPage = 1, defaultPerPage
+	query := r.URL.Query()
+	
+	if value := query.Get("page"); value != "" {
+		if page, err = strconv.Atoi(value); err != nil || page < 1 {
+			return 0, 0, fmt.Errorf("page must be a positive integer")
+		}
+	}
+	
+	if value := query.Get("per_page"); value != "" {
+		if perPage, err = strconv.Atoi(value); err != nil || perPage < 1 || perPage > maxPerPage {
+			return 0, 0, fmt.Errorf("per_page must be between 1 and %d", maxPerPage)
+		}
+	}
+	
+	return page, perPage, nil
+}
+
+// paginationMeta builds the pagination block returned by list endpoints
+func paginationMeta(page, perPage, total int) models.Pagination {
+	return models.Pagination{
+		CurrentPage: page,
+		PerPage:     perPage,
+		TotalPages:  (total + perPage - 1) / perPage,
+		TotalCount:  total,
+	}
+}
@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+	"github.com/gorilla/mux"
+)
+
+type WebhookHandler struct {
+	appRepo     *repository.ApplicationRepository
+	webhookRepo *repository.WebhookRepository
+	webhookSvc  *services.WebhookService
+}
+
+func NewWebhookHandler(
+	appRepo *repository.ApplicationRepository,
+	webhookRepo *repository.WebhookRepository,
+	webhookSvc *services.WebhookService,
+) *WebhookHandler {
+	return &WebhookHandler{
+		appRepo:     appRepo,
+		webhookRepo: webhookRepo,
+		webhookSvc:  webhookSvc,
+	}
+}
+
+// Create handles POST /api/v1/applications/{token}/webhooks. The signing
+// secret is only ever returned here.
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	// Parse request
+	var req models.WebhookEndpointRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	
+	// Validate inputs
+	if !validateRequest(w, r, http.StatusBadRequest, "Invalid request", &req) {
+		return
+	}
+	if err := h.webhookSvc.CheckURL(r.Context(), req.URL); err != nil {
+		respondFieldError(w, http.StatusBadRequest, "Invalid request", models.FieldError{Field: "url", Code: "url", Message: err.Error()})
+		return
+	}
+	
+	app, ok := h.application(w, r)
+	if !ok {
+		return
+	}
+	
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = services.GenerateWebhookSecret(); err != nil {
+			log.Printf("Error generating webhook secret: %v", err)
+			respondError(w, http.StatusInternalServerError, "Failed to create webhook", err.Error())
+			return
+		}
+	}
+	
+	endpoint, err := h.webhookRepo.CreateEndpoint(app.ID, req.URL, secret, req.Events)
+	if err != nil {
+		log.Printf("Error creating webhook: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to create webhook", err.Error())
+		return
+	}
+	
+	response := endpointResponse(endpoint)
+	response.Secret = endpoint.Secret
+	
+	log.Printf("✅ Created webhook %d for app %s", endpoint.ID, app.Token)
+	respondJSON(w, http.StatusCreated, response)
+}
+
+// List handles GET /api/v1/applications/{token}/webhooks
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.application(w, r)
+	if !ok {
+		return
+	}
+	
+	endpoints, err := h.webhookRepo.ListEndpoints(app.ID)
+	if err != nil {
+		log.Printf("Error listing webhooks: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to list webhooks", err.Error())
+		return
+	}
+	
+	response := models.WebhookEndpointListResponse{
+		Webhooks: make([]models.WebhookEndpointResponse, 0, len(endpoints)),
+	}
+	for i := range endpoints {
+		response.Webhooks = append(response.Webhooks, endpointResponse(&endpoints[i]))
+	}
+	
+	respondJSON(w, http.StatusOK, response)
+}
+
+// Delete handles DELETE /api/v1/applications/{token}/webhooks/{id}
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	app, ok := h.application(w, r)
+	if !ok {
+		return
+	}
+	
+	id, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid webhook id", err.Error())
+		return
+	}
+	
+	err = h.webhookRepo.DeleteEndpoint(app.ID, id)
+	if errors.Is(err, repository.ErrWebhookNotFound) {
+		respondError(w, http.StatusNotFound, "Webhook not found", err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting webhook: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to delete webhook", err.Error())
+		return
+	}
+	
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries handles GET /api/v1/applications/{token}/webhooks/{id}/deliveries
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := parsePagination(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid pagination", err.Error())
+		return
+	}
+	
+	endpoint, ok := h.endpoint(w, r)
+	if !ok {
+		return
+	}
+	
+	total, err := h.webhookRepo.CountDeliveries(endpoint.ID)
+	if err != nil {
+		log.Printf("Error counting webhook deliveries: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to list deliveries", err.Error())
+		return
+	}
+	
+	deliveries, err := h.webhookRepo.ListDeliveries(endpoint.ID, (page-1)*perPage, perPage)
+	if err != nil {
+		log.Printf("Error listing webhook deliveries: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to list deliveries", err.Error())
+		return
+	}
+	
+	response := models.WebhookDeliveryListResponse{
+		Deliveries: make([]models.WebhookDeliveryResponse, 0, len(deliveries)),
+		Pagination: paginationMeta(page, perPage, total),
+	}
+	for i := range deliveries {
+		response.Deliveries = append(response.Deliveries, deliveryResponse(&deliveries[i]))
+	}
+	
+	respondJSON(w, http.StatusOK, response)
+}
+
+// Delivery handles GET .../webhooks/{id}/deliveries/{delivery_id} and
+// includes the payload and every attempt made
+func (h *WebhookHandler) Delivery(w http.ResponseWriter, r *http.Request) {
+	delivery, ok := h.delivery(w, r)
+	if !ok {
+		return
+	}
+	
+	attempts, err := h.webhookRepo.Attempts(delivery.ID)
+	if err != nil {
+		log.Printf("Error loading webhook attempts: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to load delivery", err.Error())
+		return
+	}
+	
+	response := deliveryResponse(delivery)
+	response.Payload = json.RawMessage(delivery.Payload)
+	response.AttemptLog = make([]models.WebhookAttemptResponse, 0, len(attempts))
+	for _, attempt := range attempts {
+		item := models.WebhookAttemptResponse{
+			Attempt:    attempt.Attempt,
+			Error:      attempt.Error,
+			DurationMS: attempt.Duration.Milliseconds(),
+			CreatedAt:  attempt.CreatedAt,
+		}
+		if attempt.StatusCode != 0 {
+			statusCode := attempt.StatusCode
+			item.StatusCode = &statusCode
+		}
+		response.AttemptLog = append(response.AttemptLog, item)
+	}
+	
+	respondJSON(w, http.StatusOK, response)
+}
+
+// Redeliver handles POST .../webhooks/{id}/deliveries/{delivery_id}/redeliver
+// by queueing the same event again as a new delivery
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	delivery, ok := h.delivery(w, r)
+	if !ok {
+		return
+	}
+	
+	redelivery, err := h.webhookSvc.Redeliver(delivery)
+	if err != nil {
+		log.Printf("Error redelivering webhook: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to redeliver", err.Error())
+		return
+	}
+	
+	respondJSON(w, http.StatusAccepted, deliveryResponse(redelivery))
+}
+
+// application validates the token route variable and loads the application
+func (h *WebhookHandler) application(w http.ResponseWriter, r *http.Request) (*models.Application, bool) {
+	token := mux.Vars(r)["token"]
+	
+	if err := services.ValidateToken(token); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid token", err.Error())
+		return nil, false
+	}
+	
+	app, err := h.appRepo.GetByToken(token)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Application not found", err.Error())
+		return nil, false
+	}
+	
+	return app, true
+}
+
+// endpoint loads the application's webhook named by the id route variable
+func (h *WebhookHandler) endpoint(w http.ResponseWriter, r *http.Request) (*models.WebhookEndpoint, bool) {
+	id, err := pathID(mux.Vars(r), "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid webhook id", err.Error())
+		return nil, false
+	}
+	
+	app, ok := h.application(w, r)
+	if !ok {
+		return nil, false
+	}
+	
+	endpoint, err := h.webhookRepo.GetEndpoint(app.ID, id)
+	if errors.Is(err, repository.ErrWebhookNotFound) {
+		respondError(w, http.StatusNotFound, "Webhook not found", err.Error())
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error loading webhook: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to load webhook", err.Error())
+		return nil, false
+	}
+	
+	return endpoint, true
+}
+
+// delivery loads the endpoint's delivery named by the delivery_id route variable
+func (h *WebhookHandler) delivery(w http.ResponseWriter, r *http.Request) (*models.WebhookDelivery, bool) {
+	deliveryID, err := pathID(mux.Vars(r), "delivery_id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid delivery id", err.Error())
+		return nil, false
+	}
+	
+	endpoint, ok := h.endpoint(w, r)
+	if !ok {
+		return nil, false
+	}
+	
+	delivery, err := h.webhookRepo.GetDelivery(endpoint.ID, deliveryID)
+	if errors.Is(err, repository.ErrDeliveryNotFound) {
+		respondError(w, http.StatusNotFound, "Delivery not found", err.Error())
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error loading webhook delivery: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to load delivery", err.Error())
+		return nil, false
+	}
+	
+	return delivery, true
+}
+
+func endpointResponse(endpoint *models.WebhookEndpoint) models.WebhookEndpointResponse {
+	return models.WebhookEndpointResponse{
+		ID:        endpoint.ID,
+		URL:       endpoint.URL,
+		Events:    endpoint.Events,
+		CreatedAt: endpoint.CreatedAt,
+		UpdatedAt: endpoint.UpdatedAt,
+	}
+}
+
+func deliveryResponse(delivery *models.WebhookDelivery) models.WebhookDeliveryResponse {
+	response := models.WebhookDeliveryResponse{
+		ID:        delivery.ID,
+		EventID:   delivery.EventID,
+		Event:     delivery.EventType,
+		Status:    delivery.Status,
+		Attempts:  delivery.Attempts,
+		LastError: delivery.LastError,
+		CreatedAt: delivery.CreatedAt,
+		UpdatedAt: delivery.UpdatedAt,
+	}
+	if delivery.Status == models.WebhookPending {
+		next := delivery.NextAttemptAt
+		response.NextAttemptAt = &next
+	}
+	return response
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/middleware"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// Time allowed to write a frame to the client
+	wsWriteWait = 10 * time.Second
+	
+	// Time allowed to read the next pong from the client
+	wsPongWait = 60 * time.Second
+	
+	// Ping period, must be less than wsPongWait
+	wsPingPeriod = (wsPongWait * 9) / 10
+	
+	// Maximum size of a client frame
+	wsMaxFrameSize = 4096
+	
+	// Maximum number of chats one connection may follow
+	wsMaxSubscriptions = 50
+)
+
+type WebSocketHandler struct {
+	appRepo     *repository.ApplicationRepository
+	chatRepo    *repository.ChatRepository
+	hub         *services.Hub
+	presenceSvc *services.PresenceService
+	upgrader    websocket.Upgrader
+}
+
+func NewWebSocketHandler(
+	appRepo *repository.ApplicationRepository,
+	chatRepo *repository.ChatRepository,
+	hub *services.Hub,
+	presenceSvc *services.PresenceService,
+	store *config.Store,
+) *WebSocketHandler {
+	return &WebSocketHandler{
+		appRepo:     appRepo,
+		chatRepo:    chatRepo,
+		hub:         hub,
+		presenceSvc: presenceSvc,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return origin == "" || middleware.OriginAllowed(middleware.CORSPolicyFor(store.For(r.Context()).CORS, r.URL.Path).AllowedOrigins, origin)
+			},
+		},
+	}
+}
+
+// wsFrame is a server-to-client control frame
+type wsFrame struct {
+	Type             string `json:"type"`
+	ApplicationToken string `json:"application_token,omitempty"`
+	ChatNumber       int    `json:"chat_number,omitempty"`
+	Error            string `json:"error,omitempty"`
+	Message          string `json:"message,omitempty"`
+}
+
+// wsPresence identifies a user announced as present on this connection
+type wsPresence struct {
+	chatID int64
+	userID string
+}
+
+// wsSession is the state of one connection, owned by its writer goroutine
+type wsSession struct {
+	events     chan models.ChatEvent
+	subscribed map[int64]services.ChatRef
+	present    map[wsPresence]services.ChatRef
+}
+
+func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade already replied with an HTTP error
+		log.Printf("Error upgrading WebSocket: %v", err)
+		return
+	}
+	
+	session := &wsSession{
+		events:     make(chan models.ChatEvent, 64),
+		subscribed: make(map[int64]services.ChatRef),
+		present:    make(map[wsPresence]services.ChatRef),
+	}
+	commands := make(chan models.SubscriptionRequest)
+	done := make(chan struct{})
+	quit := make(chan struct{})
+	
+	defer func() {
+		close(quit)
+		for p, chat := range session.present {
+			if err := h.presenceSvc.Disconnect(chat, p.userID); err != nil {
+				log.Printf("Warning: Failed to clear presence: %v", err)
+			}
+		}
+		for chatID := range session.subscribed {
+			h.hub.Unsubscribe(chatID, session.events)
+		}
+		conn.Close()
+	}()
+	
+	go h.readLoop(conn, commands, done, quit)
+	
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-done:
+			return
+		case cmd := <-commands:
+			// Commands are applied here so the session is only touched by this goroutine
+			reply := h.apply(cmd, session)
+			if !h.write(conn, reply) {
+				return
+			}
+		case event := <-session.events:
+			if !h.write(conn, event) {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readLoop decodes client commands and forwards them to the writer goroutine
+func (h *WebSocketHandler) readLoop(conn *websocket.Conn, commands chan<- models.SubscriptionRequest, done, quit chan struct{}) {
+	defer close(done)
+	
+	conn.SetReadLimit(wsMaxFrameSize)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	
+	for {
+		var req models.SubscriptionRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("Error reading WebSocket: %v", err)
+			}
+			return
+		}
+		
+		select {
+		case commands <- req:
+		case <-quit:
+			return
+		}
+	}
+}
+
+// apply executes a client command and returns the reply frame
+func (h *WebSocketHandler) apply(cmd models.SubscriptionRequest, session *wsSession) wsFrame {
+	switch cmd.Action {
+	case "subscribe", "unsubscribe", "heartbeat", "typing", "stop_typing", "leave":
+	default:
+		return wsFrame{Type: "error", Error: "Invalid action", Message: "unknown action " + cmd.Action}
+	}
+	
+	chat, errFrame := h.resolveChat(cmd)
+	if errFrame != nil {
+		return *errFrame
+	}
+	
+	reply := wsFrame{
+		ApplicationToken: chat.ApplicationToken,
+		ChatNumber:       chat.Number,
+	}
+	
+	switch cmd.Action {
+	case "subscribe":
+		if _, ok := session.subscribed[chat.ID]; !ok {
+			if len(session.subscribed) >= wsMaxSubscriptions {
+				return wsFrame{Type: "error", Error: "Too many subscriptions", Message: "unsubscribe from a chat first"}
+			}
+			if err := h.hub.Subscribe(chat.ID, session.events); err != nil {
+				log.Printf("Error subscribing to chat %d: %v", chat.ID, err)
+				return wsFrame{Type: "error", Error: "Failed to subscribe", Message: err.Error()}
+			}
+			session.subscribed[chat.ID] = chat
+		}
+		reply.Type = "subscribed"
+		return reply
+		
+	case "unsubscribe":
+		if _, ok := session.subscribed[chat.ID]; ok {
+			h.hub.Unsubscribe(chat.ID, session.events)
+			delete(session.subscribed, chat.ID)
+		}
+		reply.Type = "unsubscribed"
+		return reply
+	}
+	
+	// Presence commands require a subscription to the chat
+	if _, ok := session.subscribed[chat.ID]; !ok {
+		return wsFrame{Type: "error", Error: "Not subscribed", Message: "subscribe to the chat first"}
+	}
+	
+	if err := services.ValidateUserID(cmd.UserID); err != nil {
+		return wsFrame{Type: "error", Error: "Invalid user", Message: err.Error()}
+	}
+	
+	key := wsPresence{chatID: chat.ID, userID: cmd.UserID}
+	_, present := session.present[key]
+	var err error
+	
+	switch cmd.Action {
+	case "heartbeat", "typing":
+		// Other connections of the same user keep them online, so this one
+		// only counts itself in and out
+		if !present {
+			if err = h.presenceSvc.Connect(chat, cmd.UserID); err != nil {
+				break
+			}
+			session.present[key] = chat
+		}
+		if cmd.Action == "heartbeat" {
+			err = h.presenceSvc.Heartbeat(chat, cmd.UserID)
+		} else {
+			err = h.presenceSvc.Typing(chat, cmd.UserID)
+		}
+	case "stop_typing":
+		err = h.presenceSvc.StopTyping(chat, cmd.UserID)
+	case "leave":
+		if present {
+			err = h.presenceSvc.Disconnect(chat, cmd.UserID)
+			delete(session.present, key)
+		}
+	}
+	
+	if err != nil {
+		log.Printf("Error updating presence: %v", err)
+		return wsFrame{Type: "error", Error: "Failed to update presence", Message: err.Error()}
+	}
+	
+	reply.Type = "ack"
+	return reply
+}
+
+// resolveChat validates the command target and loads the chat
+func (h *WebSocketHandler) resolveChat(cmd models.SubscriptionRequest) (services.ChatRef, *wsFrame) {
+	// Validate inputs
+	if err := services.ValidateToken(cmd.ApplicationToken); err != nil {
+		return services.ChatRef{}, &wsFrame{Type: "error", Error: "Invalid token", Message: err.Error()}
+	}
+	
+	if err := services.ValidateChatNumber(cmd.ChatNumber); err != nil {
+		return services.ChatRef{}, &wsFrame{Type: "error", Error: "Invalid chat number", Message: err.Error()}
+	}
+	
+	// Get application
+	app, err := h.appRepo.GetByToken(cmd.ApplicationToken)
+	if err != nil {
+		return services.ChatRef{}, &wsFrame{Type: "error", Error: "Application not found", Message: err.Error()}
+	}
+	
+	// Get chat
+	chat, err := h.chatRepo.GetByApplicationAndNumber(app.ID, cmd.ChatNumber)
+	if err != nil {
+		return services.ChatRef{}, &wsFrame{Type: "error", Error: "Chat not found", Message: err.Error()}
+	}
+	
+	return services.ChatRef{ID: chat.ID, ApplicationToken: app.Token, Number: chat.Number}, nil
+}
+
+func (h *WebSocketHandler) write(conn *websocket.Conn, v interface{}) bool {
+	conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	if err := conn.WriteJSON(v); err != nil {
+		log.Printf("Error writing WebSocket: %v", err)
+		return false
+	}
+	return true
+}
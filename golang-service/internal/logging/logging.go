@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level orders log lines by severity
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var current atomic.Int32
+
+func init() {
+	current.Store(int32(LevelInfo))
+}
+
+// ParseLevel maps debug, info, warn and error to a Level
+func ParseLevel(name string) (Level, error) {
+	switch name {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", name)
+}
+
+// SetLevel changes the minimum level written; safe to call at any time
+func SetLevel(name string) error {
+	level, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	current.Store(int32(level))
+	return nil
+}
+
+// Install routes the standard logger through the level filter. The service
+// logs with plain log.Printf, so a line's level comes from its first word
+// only: "Error ..." and "Failed ..." are errors, "Warning: ..." is a warning
+// and "Debug: ..." is debug. Everything else is info, whatever it mentions.
+func Install() {
+	log.SetOutput(&filter{out: os.Stderr})
+	log.SetFlags(0)
+}
+
+type filter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (f *filter) Write(p []byte) (int, error) {
+	if classify(p) < Level(current.Load()) {
+		return len(p), nil
+	}
+	
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	
+	// The standard logger's timestamp, added here so classify sees the
+	// message itself
+	line := append([]byte(time.Now().Format("2006/01/02 15:04:05 ")), p...)
+	if _, err := f.out.Write(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// levelPrefixes map a line's first word to its level
+var levelPrefixes = []struct {
+	prefix string
+	level  Level
+}{
+	{"Debug", LevelDebug},
+	{"Warning", LevelWarn},
+	{"⚠️", LevelWarn},
+	{"Error", LevelError},
+	{"Failed", LevelError},
+}
+
+func classify(line []byte) Level {
+	for _, p := range levelPrefixes {
+		rest, ok := bytes.CutPrefix(line, []byte(p.prefix))
+		// A whole word, so "Errors resolved" or "Debugger" stay info
+		if ok && (len(rest) == 0 || rest[0] == ' ' || rest[0] == ':') {
+			return p.level
+		}
+	}
+	return LevelInfo
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		line string
+		want Level
+	}{
+		{"Debug: cache miss for app:abc", LevelDebug},
+		{"Warning: Failed to publish message event: timeout", LevelWarn},
+		{"⚠️ Rate limit exceeded for 10.0.0.1", LevelWarn},
+		{"Error creating message: deadlock", LevelError},
+		{"Error: unexpected EOF", LevelError},
+		{"Failed to load config: missing file", LevelError},
+		{"✅ Created message #3 for chat 7", LevelInfo},
+		// What a line mentions does not change its level
+		{"✅ Webhook payload has an error field", LevelInfo},
+		{"Counter drift: chat 4 failed checks 2 -> 3", LevelInfo},
+		{"Reindexed 10 messages, 0 failed", LevelInfo},
+		{"Errors resolved after reconnect", LevelInfo},
+		{"Debugger attached", LevelInfo},
+		{"", LevelInfo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := classify([]byte(tt.line + "\n")); got != tt.want {
+				t.Errorf("classify(%q) = %d, want %d", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterDropsLinesBelowLevel(t *testing.T) {
+	defer current.Store(current.Load())
+	if err := SetLevel("warn"); err != nil {
+		t.Fatal(err)
+	}
+	
+	var out bytes.Buffer
+	f := &filter{out: &out}
+	for _, line := range []string{
+		"Debug: dropped\n",
+		"✅ an info line about an error, dropped\n",
+		"Warning: kept\n",
+		"Error kept\n",
+	} {
+		if n, err := f.Write([]byte(line)); err != nil || n != len(line) {
+			t.Fatalf("Write(%q) = %d, %v", line, n, err)
+		}
+	}
+	
+	got := out.String()
+	if strings.Contains(got, "dropped") || strings.Count(got, "kept") != 2 {
+		t.Errorf("filter wrote %q, want only the warning and the error", got)
+	}
+}
+
+func TestSetLevelRejectsUnknownNames(t *testing.T) {
+	if err := SetLevel("verbose"); err == nil {
+		t.Error("SetLevel(verbose) succeeded")
+	}
+}
@@ -0,0 +1,46 @@
+package messaging
+
+import "sync"
+
+// indexQueue runs search index writes one at a time per message, in the
+// order they were queued, so an older body can never land after a newer
+// one or revive a deleted message. Writes to different messages still run
+// in parallel.
+type indexQueue struct {
+	mu sync.Mutex
+	// pending holds the writes waiting behind the one running for each
+	// message; a message is present while its writes are being run
+	pending map[int64][]func()
+}
+
+func (q *indexQueue) push(messageID int64, write func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	
+	if waiting, running := q.pending[messageID]; running {
+		q.pending[messageID] = append(waiting, write)
+		return
+	}
+	if q.pending == nil {
+		q.pending = make(map[int64][]func())
+	}
+	q.pending[messageID] = nil
+	go q.run(messageID, write)
+}
+
+func (q *indexQueue) run(messageID int64, write func()) {
+	for {
+		write()
+	
+		q.mu.Lock()
+		waiting := q.pending[messageID]
+		if len(waiting) == 0 {
+			delete(q.pending, messageID)
+			q.mu.Unlock()
+			return
+		}
+		write = waiting[0]
+		q.pending[messageID] = waiting[1:]
+		q.mu.Unlock()
+	}
+}
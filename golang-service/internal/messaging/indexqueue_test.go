@@ -0,0 +1,50 @@
+package messaging
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestIndexQueueKeepsOrderPerMessage(t *testing.T) {
+	var q indexQueue
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	got := map[int64][]int{}
+	
+	// Hold the first write of message 1 so later ones queue behind it
+	release := make(chan struct{})
+	for i := 0; i < 50; i++ {
+		for _, id := range []int64{1, 2} {
+			id, i := id, i
+			wg.Add(1)
+			q.push(id, func() {
+				defer wg.Done()
+				if id == 1 && i == 0 {
+					<-release
+				}
+				mu.Lock()
+				got[id] = append(got[id], i)
+				mu.Unlock()
+			})
+		}
+	}
+	close(release)
+	wg.Wait()
+	
+	want := make([]int, 50)
+	for i := range want {
+		want[i] = i
+	}
+	for _, id := range []int64{1, 2} {
+		if !reflect.DeepEqual(got[id], want) {
+			t.Errorf("message %d writes ran as %v, want in queued order", id, got[id])
+		}
+	}
+	
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) != 0 {
+		t.Errorf("pending = %v, want empty once drained", q.pending)
+	}
+}
@@ -0,0 +1,220 @@
+// Package messaging creates and changes chats and messages for both the
+// HTTP handlers and the gRPC API, so the two transports share one flow:
+// quotas, storage, usage, search indexing, live events and webhooks
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/search"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+)
+
+// ErrNumber wraps failures to allocate a chat or message number, as opposed
+// to failures to store the chat or message. Quota rejections are returned
+// as *services.QuotaError instead.
+var ErrNumber = errors.New("failed to allocate number")
+
+type Service struct {
+	chatRepo    *repository.ChatRepository
+	messageRepo *repository.MessageRepository
+	counterSvc  *services.CounterService
+	quotaSvc    *services.QuotaService
+	usageSvc    *services.UsageService
+	webhookSvc  *services.WebhookService
+	reactionSvc *services.ReactionService
+	hub         *services.Hub
+	searchIndex search.SearchIndex
+	indexQueue  indexQueue
+}
+
+func NewService(
+	chatRepo *repository.ChatRepository,
+	messageRepo *repository.MessageRepository,
+	counterSvc *services.CounterService,
+	quotaSvc *services.QuotaService,
+	usageSvc *services.UsageService,
+	webhookSvc *services.WebhookService,
+	reactionSvc *services.ReactionService,
+	hub *services.Hub,
+	searchIndex search.SearchIndex,
+) *Service {
+	return &Service{
+		chatRepo:    chatRepo,
+		messageRepo: messageRepo,
+		counterSvc:  counterSvc,
+		quotaSvc:    quotaSvc,
+		usageSvc:    usageSvc,
+		webhookSvc:  webhookSvc,
+		reactionSvc: reactionSvc,
+		hub:         hub,
+		searchIndex: searchIndex,
+	}
+}
+
+// CreateChat allocates the next chat number within the application's quota
+// and stores the chat
+func (s *Service) CreateChat(app *models.Application) (*models.Chat, error) {
+	// Get next chat number (atomic), within the application's chat quota
+	chatNumber, err := s.quotaSvc.NextChatNumber(app)
+	if err != nil {
+		return nil, numberError(err)
+	}
+	
+	// Create chat in database
+	chat, err := s.chatRepo.Create(app.ID, int(chatNumber))
+	if err != nil {
+		s.quotaSvc.ReleaseChat(app)
+		return nil, err
+	}
+	
+	// Initialize message counter for this chat
+	if err := s.counterSvc.InitializeMessageCounter(chat.ID); err != nil {
+		log.Printf("Warning: Failed to initialize message counter: %v", err)
+	}
+	
+	s.usageSvc.RecordChat(app.ID)
+	s.webhookSvc.Emit(app, models.EventChatCreated, models.WebhookEventData{Chat: ChatResponse(chat)})
+	
+	log.Printf("✅ Created chat #%d for app %s", chat.Number, app.Token)
+	return chat, nil
+}
+
+// CreateMessage allocates the next message number within the application's
+// quotas, stores the message, then indexes and announces it
+func (s *Service) CreateMessage(app *models.Application, chat *models.Chat, body string) (*models.Message, error) {
+	// Get next message number (atomic), within the application's quotas
+	reservation, err := s.quotaSvc.ReserveMessage(app, chat, body)
+	if err != nil {
+		return nil, numberError(err)
+	}
+	
+	// Create message in database
+	message, err := s.messageRepo.Create(chat.ID, int(reservation.Number), body)
+	if err != nil {
+		s.quotaSvc.ReleaseMessage(reservation)
+		return nil, err
+	}
+	
+	s.usageSvc.RecordMessage(app.ID, len(message.Body))
+	
+	// Index off the request path, like IndexMessageWorker does for Rails
+	s.index(search.DocumentFromMessage(*message))
+	s.publish(models.EventMessageCreated, app, chat, MessageResponse(message))
+	
+	log.Printf("✅ Created message #%d for chat %d", message.Number, chat.ID)
+	return message, nil
+}
+
+// UpdateMessage stores a new body for a message, then reindexes and
+// announces it
+func (s *Service) UpdateMessage(app *models.Application, chat *models.Chat, number int, body string) (*models.Message, error) {
+	message, err := s.messageRepo.Update(chat.ID, number, body)
+	if err != nil {
+		return nil, err
+	}
+	
+	s.index(search.DocumentFromMessage(*message))
+	s.publish(models.EventMessageUpdated, app, chat, MessageResponse(message))
+	
+	return message, nil
+}
+
+// DeleteMessage removes a message, frees its quota, drops its cached
+// reaction counts and announces it
+func (s *Service) DeleteMessage(app *models.Application, chat *models.Chat, number int) (*models.Message, error) {
+	message, err := s.messageRepo.Delete(chat.ID, number)
+	if err != nil {
+		return nil, err
+	}
+	
+	s.quotaSvc.MessageDeleted(chat)
+	if err := s.reactionSvc.Forget(chat.ID, []int64{message.ID}); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	s.unindex(chat.ID, message.ID)
+	s.publish(models.EventMessageDeleted, app, chat, MessageResponse(message))
+	
+	log.Printf("✅ Deleted message #%d from chat %d", number, chat.ID)
+	return message, nil
+}
+
+// publish pushes a message event to live subscribers on every replica and
+// to the application's webhooks
+func (s *Service) publish(eventType string, app *models.Application, chat *models.Chat, message *models.MessageResponse) {
+	event := models.ChatEvent{
+		Type:             eventType,
+		ApplicationToken: app.Token,
+		ChatNumber:       chat.Number,
+		Message:          message,
+	}
+	if err := s.hub.Publish(chat.ID, event); err != nil {
+		log.Printf("Warning: Failed to publish message event: %v", err)
+	}
+	
+	s.webhookSvc.Emit(app, eventType, models.WebhookEventData{
+		ChatNumber: chat.Number,
+		Message:    message,
+	})
+}
+
+// index queues doc to be written to the search index in the background,
+// after any earlier write to the same message
+func (s *Service) index(doc search.Document) {
+	s.indexQueue.push(doc.ID, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+	
+		if err := s.searchIndex.Index(ctx, doc); err != nil {
+			log.Printf("Warning: Failed to index message %d: %v", doc.ID, err)
+		}
+	})
+}
+
+// unindex queues the removal of a message from the search index
+func (s *Service) unindex(chatID, id int64) {
+	s.indexQueue.push(id, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+	
+		if err := s.searchIndex.Delete(ctx, chatID, id); err != nil {
+			log.Printf("Warning: Failed to remove message %d from index: %v", id, err)
+		}
+	})
+}
+
+// numberError passes quota rejections through and marks anything else as a
+// failure to allocate a number
+func numberError(err error) error {
+	var quotaErr *services.QuotaError
+	if errors.As(err, &quotaErr) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrNumber, err)
+}
+
+// ChatResponse is the API representation of a chat
+func ChatResponse(chat *models.Chat) *models.ChatResponse {
+	return &models.ChatResponse{
+		Number:        chat.Number,
+		MessagesCount: chat.MessagesCount,
+		CreatedAt:     chat.CreatedAt,
+		UpdatedAt:     chat.UpdatedAt,
+	}
+}
+
+// MessageResponse is the API representation of a message
+func MessageResponse(message *models.Message) *models.MessageResponse {
+	return &models.MessageResponse{
+		Number:    message.Number,
+		Body:      message.Body,
+		CreatedAt: message.CreatedAt,
+		UpdatedAt: message.UpdatedAt,
+	}
+}
@@ -0,0 +1,24 @@
+// Package metrics exposes service counters through expvar at /debug/vars
+package metrics
+
+import "expvar"
+
+var (
+	// CounterReconcileRuns counts completed reconciliation passes
+	CounterReconcileRuns = expvar.NewInt("counter_reconcile_runs")
+	
+	// CounterReconcileErrors counts passes that stopped on an error
+	CounterReconcileErrors = expvar.NewInt("counter_reconcile_errors")
+	
+	// CounterCorrections counts repairs by kind: chats_count,
+	// messages_count, app_chat_counter and chat_message_counter
+	CounterCorrections = expvar.NewMap("counter_corrections")
+)
+
+// WebhookDeliveries counts delivery attempts by outcome: succeeded,
+// retried and failed
+var WebhookDeliveries = expvar.NewMap("webhook_deliveries")
+
+// CacheLookups counts lookup cache outcomes as <cache>.local_hit,
+// <cache>.redis_hit and <cache>.miss
+var CacheLookups = expvar.NewMap("cache_lookups")
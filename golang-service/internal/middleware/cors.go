@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+)
+
+// CORSMiddleware applies the CORS policy for the request path. Preflight
+// requests are answered here; an OPTIONS request that is not a preflight
+// is not CORS at all and gets 405.
+func CORSMiddleware(store *config.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			policy := CORSPolicyFor(store.For(r.Context()).CORS, r.URL.Path)
+			origin := r.Header.Get("Origin")
+	
+			// The response depends on Origin, so shared caches must key on it
+			w.Header().Add("Vary", "Origin")
+	
+			if r.Method != http.MethodOptions {
+				if origin != "" && OriginAllowed(policy.AllowedOrigins, origin) {
+					setAllowOrigin(w, policy, origin)
+					if len(policy.ExposedHeaders) > 0 {
+						w.Header().Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+					}
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+	
+			method := r.Header.Get("Access-Control-Request-Method")
+			if origin == "" || method == "" {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				w.Write([]byte(`{"error":"Method not allowed","message":"OPTIONS is only supported for CORS preflight requests","status":405}`))
+				return
+			}
+	
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+	
+			if !OriginAllowed(policy.AllowedOrigins, origin) {
+				rejectPreflight(w, "Origin is not allowed")
+				return
+			}
+			if !containsFold(policy.AllowedMethods, method) {
+				rejectPreflight(w, "Method is not allowed")
+				return
+			}
+			for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+				if header = strings.TrimSpace(header); header != "" && !containsFold(policy.AllowedHeaders, header) {
+					rejectPreflight(w, "Header "+strconv.Quote(header)+" is not allowed")
+					return
+				}
+			}
+	
+			setAllowOrigin(w, policy, origin)
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+			if len(policy.AllowedHeaders) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+			}
+			if policy.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// CORSPolicyFor returns the policy for path: the route with the longest
+// matching prefix, with its empty fields filled from the default policy
+func CORSPolicyFor(cfg config.CORSConfig, path string) config.CORSPolicy {
+	policy := cfg.CORSPolicy
+	matched := -1
+	for _, route := range cfg.Routes {
+		if !strings.HasPrefix(path, route.PathPrefix) || len(route.PathPrefix) <= matched {
+			continue
+		}
+		matched = len(route.PathPrefix)
+		policy = cfg.RoutePolicy(route)
+	}
+	return policy
+}
+
+// OriginAllowed reports whether origin matches an entry in allowed: *, an
+// exact origin, or a wildcard subdomain pattern like https://*.example.com
+func OriginAllowed(allowed []string, origin string) bool {
+	for _, candidate := range allowed {
+		if candidate == "*" || strings.EqualFold(candidate, origin) {
+			return true
+		}
+	
+		scheme, host, ok := strings.Cut(candidate, "://*.")
+		if !ok {
+			continue
+		}
+		// The wildcard covers one or more labels, never the bare domain
+		prefix := scheme + "://"
+		suffix := "." + host
+		if len(origin) > len(prefix)+len(suffix) &&
+			strings.EqualFold(origin[:len(prefix)], prefix) &&
+			strings.EqualFold(origin[len(origin)-len(suffix):], suffix) &&
+			!strings.ContainsAny(origin[len(prefix):len(origin)-len(suffix)], "/:@") {
+			return true
+		}
+	}
+	return false
+}
+
+// setAllowOrigin answers with * only for a public policy; otherwise the
+// request origin is echoed, as browsers require with credentials
+func setAllowOrigin(w http.ResponseWriter, policy config.CORSPolicy, origin string) {
+	if !policy.AllowCredentials && len(policy.AllowedOrigins) == 1 && policy.AllowedOrigins[0] == "*" {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if policy.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func rejectPreflight(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	w.Write([]byte(`{"error":"CORS preflight rejected","message":` + strconv.Quote(message) + `,"status":403}`))
+}
+
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
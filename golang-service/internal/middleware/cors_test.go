@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+)
+
+func TestOriginAllowed(t *testing.T) {
+	allowed := []string{"https://app.example.com", "https://*.example.org"}
+	
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.example.com", true},
+		{"HTTPS://APP.EXAMPLE.COM", true},
+		{"http://app.example.com", false},
+		{"https://app.example.com:8443", false},
+		{"https://evil.com", false},
+		{"https://a.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://example.org", false},
+		{"http://a.example.org", false},
+		{"https://a.example.org.evil.com", false},
+		{"https://evil.com/.example.org", false},
+		{"https://user@a.example.org", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			if got := OriginAllowed(allowed, tt.origin); got != tt.want {
+				t.Errorf("OriginAllowed(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+	
+	if !OriginAllowed([]string{"*"}, "https://anything.test") {
+		t.Error("OriginAllowed(*) rejected an origin")
+	}
+	if OriginAllowed(nil, "https://app.example.com") {
+		t.Error("OriginAllowed(nil) accepted an origin")
+	}
+}
+
+func TestCORSPolicyFor(t *testing.T) {
+	cfg := config.CORSConfig{
+		CORSPolicy: config.CORSPolicy{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET", "POST"},
+			AllowedHeaders: []string{"Content-Type"},
+			MaxAge:         time.Hour,
+		},
+		Routes: []config.CORSRoute{
+			{PathPrefix: "/api/v1/applications/", CORSPolicy: config.CORSPolicy{
+				AllowedOrigins:   []string{"https://admin.example.com"},
+				AllowCredentials: true,
+			}},
+			{PathPrefix: "/api/v1/applications/public/", CORSPolicy: config.CORSPolicy{
+				AllowedMethods: []string{"GET"},
+				MaxAge:         time.Minute,
+			}},
+		},
+	}
+	
+	tests := []struct {
+		name string
+		path string
+		want config.CORSPolicy
+	}{
+		{"no route matches", "/health", cfg.CORSPolicy},
+		{"route inherits empty fields", "/api/v1/applications/abc", config.CORSPolicy{
+			AllowedOrigins:   []string{"https://admin.example.com"},
+			AllowedMethods:   []string{"GET", "POST"},
+			AllowedHeaders:   []string{"Content-Type"},
+			AllowCredentials: true,
+			MaxAge:           time.Hour,
+		}},
+		// The longest prefix wins and inherits from the default, not from
+		// the shorter route
+		{"longest prefix", "/api/v1/applications/public/x", config.CORSPolicy{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET"},
+			AllowedHeaders: []string{"Content-Type"},
+			MaxAge:         time.Minute,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CORSPolicyFor(cfg, tt.path); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CORSPolicyFor(%q) = %+v, want %+v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+)
+
+// FeatureMiddleware answers 404 while the feature chosen by enabled is
+// switched off, so flags can be flipped by a config reload
+func FeatureMiddleware(store *config.Store, enabled func(config.FeaturesConfig) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !enabled(store.For(r.Context()).Features) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":"Not found","message":"This feature is disabled","status":404}`))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Readiness gates traffic until the service has connected to its
+// dependencies. Health checks and metrics stay reachable while starting.
+type Readiness struct {
+	ready atomic.Bool
+}
+
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// SetReady opens the gate
+func (rd *Readiness) SetReady() {
+	rd.ready.Store(true)
+}
+
+func (rd *Readiness) Ready() bool {
+	return rd.ready.Load()
+}
+
+// Middleware answers 503 until SetReady is called
+func (rd *Readiness) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rd.Ready() || r.URL.Path == "/health" || r.URL.Path == "/debug/vars" || isDocsRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "5")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error":"Service starting","message":"Waiting for MySQL and Redis","status":503}`))
+	})
+}
@@ -2,10 +2,12 @@ package middleware
 
 import (
 	"net/http"
-	"os"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
 	"golang.org/x/time/rate"
 )
 
@@ -40,71 +42,115 @@ func (rl *RateLimiter) GetLimiter(ip string) *rate.Limiter {
 	return limiter
 }
 
-var rateLimiter = NewRateLimiter(rate.Every(time.Second), 10) // 10 req/sec
-
-// RateLimitMiddleware limits requests per IP
-func RateLimitMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Skip rate limiting for health check
-		if r.URL.Path == "/health" {
-			next.ServeHTTP(w, r)
-			return
-		}
+// SetRate changes the rate and burst for new and existing limiters
+func (rl *RateLimiter) SetRate(r rate.Limit, b int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	
+	if rl.rate == r && rl.burst == b {
+		return
+	}
+	rl.rate = r
+	rl.burst = b
+	for _, limiter := range rl.limiters {
+		limiter.SetLimit(r)
+		limiter.SetBurst(b)
+	}
+}
 
-		ip := r.RemoteAddr
-		limiter := rateLimiter.GetLimiter(ip)
+// ConfigMiddleware pins the current configuration to the request so the
+// middleware and handlers below it see one snapshot, even mid-reload
+func ConfigMiddleware(store *config.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := config.WithConfig(r.Context(), store.Current())
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
 
-		if !limiter.Allow() {
-			http.Error(w, `{"error":"Rate limit exceeded","message":"Too many requests","status":429}`, http.StatusTooManyRequests)
-			return
-		}
+// RateLimitMiddleware limits requests per IP to security.rate_limit per
+// second with bursts of security.rate_burst
+func RateLimitMiddleware(store *config.Store) func(http.Handler) http.Handler {
+	rateLimiter := NewRateLimiter(rate.Limit(store.Current().Security.RateLimit), store.Current().Security.RateBurst)
+	
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Skip rate limiting for health check
+			if r.URL.Path == "/health" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			
+			cfg := store.For(r.Context()).Security
+			rateLimiter.SetRate(rate.Limit(cfg.RateLimit), cfg.RateBurst)
+
+			ip := r.RemoteAddr
+			limiter := rateLimiter.GetLimiter(ip)
+
+			if !limiter.Allow() {
+				http.Error(w, `{"error":"Rate limit exceeded","message":"Too many requests","status":429}`, http.StatusTooManyRequests)
+				return
+			}
 
-		next.ServeHTTP(w, r)
-	})
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
 // AuthMiddleware validates API key
-func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Skip auth for health check
-		if r.URL.Path == "/health" {
-			next.ServeHTTP(w, r)
-			return
-		}
+func AuthMiddleware(store *config.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Skip auth for health check and API docs
+			if r.URL.Path == "/health" || isDocsRequest(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			
+			cfg := store.For(r.Context()).Security
+
+			// Skip in development if configured
+			if cfg.SkipAPIKeyCheck {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			apiKey := r.Header.Get("X-API-Key")
+			if apiKey == "" && isStreamingRequest(r) &&
+				services.VerifyStreamToken(cfg.MasterAPIKey, r.URL.Query().Get("stream_token"), time.Now()) {
+				// Browsers cannot set custom headers on WebSocket or EventSource
+				// requests. They pass a short-lived token in the URL instead of
+				// the key, since URLs end up in proxy and access logs.
+				next.ServeHTTP(w, r)
+				return
+			}
+			if apiKey == "" || apiKey != cfg.MasterAPIKey {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error":"Unauthorized","message":"Invalid or missing API key","status":401}`))
+				return
+			}
 
-		// Skip in development if configured
-		if os.Getenv("SKIP_API_KEY_CHECK") == "true" {
 			next.ServeHTTP(w, r)
-			return
-		}
-
-		apiKey := r.Header.Get("X-API-Key")
-		masterKey := os.Getenv("MASTER_API_KEY")
-
-		if apiKey == "" || apiKey != masterKey {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write([]byte(`{"error":"Unauthorized","message":"Invalid or missing API key","status":401}`))
-			return
-		}
-
-		next.ServeHTTP(w, r)
-	})
+		})
+	}
 }
 
-// RequestSizeMiddleware limits request body size
-func RequestSizeMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Skip for health check
-		if r.URL.Path == "/health" {
+// RequestSizeMiddleware limits request body size to security.max_body_bytes
+func RequestSizeMiddleware(store *config.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Skip for health check
+			if r.URL.Path == "/health" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, store.For(r.Context()).Security.MaxBodyBytes)
 			next.ServeHTTP(w, r)
-			return
-		}
-
-		// Limit to 1MB
-		r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
-		next.ServeHTTP(w, r)
-	})
+		})
+	}
 }
 
 // SecurityHeadersMiddleware adds security headers
@@ -119,24 +165,11 @@ func SecurityHeadersMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// CORSMiddleware handles CORS
-func CORSMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		origin := os.Getenv("ALLOWED_ORIGINS")
-		if origin == "" {
-			origin = "*" // Development default
-		}
-
-		w.Header().Set("Access-Control-Allow-Origin", origin)
-		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-API-Key")
-		w.Header().Set("Access-Control-Max-Age", "86400")
-
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
+func isDocsRequest(r *http.Request) bool {
+	return r.URL.Path == "/api-docs" || strings.HasPrefix(r.URL.Path, "/api-docs/")
+}
 
-		next.ServeHTTP(w, r)
-	})
+func isStreamingRequest(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		strings.Contains(r.Header.Get("Accept"), "text/event-stream")
 }
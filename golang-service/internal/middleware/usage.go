@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+	"github.com/gorilla/mux"
+)
+
+// UsageMiddleware meters API calls per application and route. Routes with
+// a {token} variable are attributed up front, which also covers long-lived
+// streams; others are attributed after the handler tags the request with
+// services.TagUsage.
+func UsageMiddleware(usageSvc *services.UsageService, appRepo *repository.ApplicationRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := mux.CurrentRoute(r)
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			template, err := route.GetPathTemplate()
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			label := r.Method + " " + template
+	
+			if token := mux.Vars(r)["token"]; token != "" {
+				if app, err := appRepo.GetByToken(token); err == nil {
+					usageSvc.RecordAPICall(app.ID, label)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+	
+			r = r.WithContext(services.WithUsageTag(r.Context()))
+			next.ServeHTTP(w, r)
+	
+			if appID := services.TaggedApplication(r.Context()); appID != 0 {
+				usageSvc.RecordAPICall(appID, label)
+			}
+		})
+	}
+}
@@ -1,33 +1,237 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Request models
+type ApplicationRequest struct {
+	Name string `json:"name" validate:"required,max=255,nocontrol"`
+}
+
 type ChatCreateRequest struct {
-	ApplicationToken string `json:"application_token" validate:"required,min=20,max=20"`
+	ApplicationToken string `json:"application_token" validate:"required,len=20,hex"`
 }
 
 type MessageCreateRequest struct {
-	ApplicationToken string `json:"application_token" validate:"required,min=20,max=20"`
+	ApplicationToken string `json:"application_token" validate:"required,len=20,hex"`
 	ChatNumber       int    `json:"chat_number" validate:"required,min=1"`
-	Body             string `json:"body" validate:"required,min=1,max=5000"`
+	Body             string `json:"body" validate:"required,max=max_body_length,nocontrol"`
+}
+
+type MessageUpdateRequest struct {
+	Body string `json:"body" validate:"required,max=max_body_length,nocontrol"`
+}
+
+type WebhookEndpointRequest struct {
+	URL    string   `json:"url" validate:"required,max=2048,url"`
+	Events []string `json:"events" validate:"required,oneof=chat.created message.created message.updated message.deleted"`
+	// Secret is generated when empty
+	Secret string `json:"secret,omitempty" validate:"min=16,max=255"`
+}
+
+type ReadReceiptRequest struct {
+	UserID         string `json:"user_id" validate:"required,max=255,nocontrol"`
+	LastReadNumber int    `json:"last_read_number" validate:"min=0"`
+}
+
+type ReactionRequest struct {
+	UserID string `json:"user_id" validate:"required,max=255,nocontrol"`
+	Emoji  string `json:"emoji" validate:"required,emoji"`
 }
 
 // Response models
+type ApplicationResponse struct {
+	Token      string    `json:"token"`
+	Name       string    `json:"name"`
+	ChatsCount int       `json:"chats_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type ApplicationListResponse struct {
+	Applications []ApplicationResponse `json:"applications"`
+	Pagination   Pagination            `json:"pagination"`
+}
+
 type ChatResponse struct {
-	Number        int       `json:"number"`
-	MessagesCount int       `json:"messages_count"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	Number         int       `json:"number"`
+	MessagesCount  int       `json:"messages_count"`
+	LastReadNumber *int      `json:"last_read_number,omitempty"`
+	UnreadCount    *int      `json:"unread_count,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type ChatListResponse struct {
+	Chats      []ChatResponse `json:"chats"`
+	Pagination Pagination     `json:"pagination"`
+}
+
+type ReadReceiptResponse struct {
+	ChatNumber     int    `json:"chat_number"`
+	UserID         string `json:"user_id"`
+	LastReadNumber int    `json:"last_read_number"`
+	UnreadCount    int    `json:"unread_count"`
+}
+
+// Pagination mirrors the Rails pagination_meta shape
+type Pagination struct {
+	CurrentPage int `json:"current_page"`
+	PerPage     int `json:"per_page"`
+	TotalPages  int `json:"total_pages"`
+	TotalCount  int `json:"total_count"`
 }
 
 type MessageResponse struct {
-	Number    int       `json:"number"`
-	Body      string    `json:"body"`
+	Number    int            `json:"number"`
+	Body      string         `json:"body"`
+	Reactions map[string]int `json:"reactions,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// Real-time event types pushed to subscribers
+const (
+	EventMessageCreated  = "message.created"
+	EventMessageUpdated  = "message.updated"
+	EventMessageDeleted  = "message.deleted"
+	EventPresenceOnline  = "presence.online"
+	EventPresenceOffline = "presence.offline"
+	EventTypingStarted   = "typing.started"
+	EventTypingStopped   = "typing.stopped"
+)
+
+// Event types only delivered through webhooks
+const (
+	EventChatCreated = "chat.created"
+)
+
+type ChatEvent struct {
+	Type             string           `json:"type"`
+	ApplicationToken string           `json:"application_token"`
+	ChatNumber       int              `json:"chat_number"`
+	UserID           string           `json:"user_id,omitempty"`
+	Message          *MessageResponse `json:"message,omitempty"`
+}
+
+// Client frames accepted on the WebSocket endpoint
+type SubscriptionRequest struct {
+	Action           string `json:"action"`
+	ApplicationToken string `json:"application_token"`
+	ChatNumber       int    `json:"chat_number"`
+	UserID           string `json:"user_id,omitempty"`
+}
+
+// SearchResult is a message plus a highlighted excerpt of its body
+type SearchResult struct {
+	MessageResponse
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// SearchResponse mirrors the Rails search action, with pagination added
+type SearchResponse struct {
+	Results      []SearchResult `json:"results"`
+	Query        string         `json:"query"`
+	Total        int            `json:"total"`
+	SearchEngine string         `json:"search_engine"`
+	Pagination   Pagination     `json:"pagination"`
+}
+
+// UsageTotals is metered usage summed over a period
+type UsageTotals struct {
+	ChatsCreated    int64            `json:"chats_created"`
+	MessagesCreated int64            `json:"messages_created"`
+	BytesStored     int64            `json:"bytes_stored"`
+	APICalls        map[string]int64 `json:"api_calls"`
+}
+
+// UsageHour is metered usage for one hour
+type UsageHour struct {
+	Hour time.Time `json:"hour"`
+	UsageTotals
+}
+
+type UsageResponse struct {
+	ApplicationToken string      `json:"application_token"`
+	From             time.Time   `json:"from"`
+	To               time.Time   `json:"to"`
+	Totals           UsageTotals `json:"totals"`
+	Hours            []UsageHour `json:"hours"`
+}
+
+// WebhookEndpointResponse carries the signing secret only when the endpoint
+// is created
+type WebhookEndpointResponse struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type WebhookEndpointListResponse struct {
+	Webhooks []WebhookEndpointResponse `json:"webhooks"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID            int64                    `json:"id"`
+	EventID       string                   `json:"event_id"`
+	Event         string                   `json:"event"`
+	Status        string                   `json:"status"`
+	Attempts      int                      `json:"attempts"`
+	NextAttemptAt *time.Time               `json:"next_attempt_at,omitempty"`
+	LastError     string                   `json:"last_error,omitempty"`
+	Payload       json.RawMessage          `json:"payload,omitempty"`
+	AttemptLog    []WebhookAttemptResponse `json:"attempt_log,omitempty"`
+	CreatedAt     time.Time                `json:"created_at"`
+	UpdatedAt     time.Time                `json:"updated_at"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	Pagination Pagination                `json:"pagination"`
+}
+
+type WebhookAttemptResponse struct {
+	Attempt    int       `json:"attempt"`
+	StatusCode *int      `json:"status_code"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookEvent is the JSON body POSTed to webhook endpoints
+type WebhookEvent struct {
+	ID               string           `json:"id"`
+	Type             string           `json:"type"`
+	CreatedAt        time.Time        `json:"created_at"`
+	ApplicationToken string           `json:"application_token"`
+	Data             WebhookEventData `json:"data"`
+}
+
+// WebhookEventData holds the chat for chat events, and the chat number and
+// message for message events. Deleted messages are sent as they were.
+type WebhookEventData struct {
+	Chat       *ChatResponse    `json:"chat,omitempty"`
+	ChatNumber int              `json:"chat_number,omitempty"`
+	Message    *MessageResponse `json:"message,omitempty"`
+}
+
+// StreamTokenResponse carries a token to pass as the stream_token query
+// parameter when opening a WebSocket or event stream
+type StreamTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type PresenceResponse struct {
+	Online []string `json:"online"`
+	Typing []string `json:"typing"`
+}
+
 type HealthResponse struct {
 	Status  string            `json:"status"`
 	Service string            `json:"service"`
@@ -36,9 +240,25 @@ type HealthResponse struct {
 }
 
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
-	Status  int    `json:"status"`
+	Error   string       `json:"error"`
+	Message string       `json:"message,omitempty"`
+	Status  int          `json:"status"`
+	Errors  []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is one failing field of a request body
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// QuotaErrorResponse names the quota a rejected request ran into
+type QuotaErrorResponse struct {
+	ErrorResponse
+	Quota string `json:"quota"`
+	Limit int64  `json:"limit"`
+	Plan  string `json:"plan"`
 }
 
 // Database models
@@ -68,3 +288,90 @@ type Message struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
+
+type Reaction struct {
+	ID        int64
+	MessageID int64
+	UserID    string
+	Emoji     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CounterStats compares a parent row's cached count with what MySQL holds.
+// Token is set for applications and empty for chats.
+type CounterStats struct {
+	ID          int64
+	Token       string
+	StoredCount int
+	ActualCount int
+	MaxNumber   int
+}
+
+// ApplicationQuota assigns an application to a plan. Nil limits fall back to
+// the plan's.
+type ApplicationQuota struct {
+	ApplicationID      int64
+	Plan               string
+	MaxChats           *int64
+	MaxMessagesPerChat *int64
+	MessagesPerDay     *int64
+	MaxBodyBytes       *int
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// UsageRecord is one row of usage_hourly
+type UsageRecord struct {
+	ApplicationID    int64
+	ApplicationToken string
+	Hour             time.Time
+	Metric           string
+	Value            int64
+}
+
+type WebhookEndpoint struct {
+	ID            int64
+	ApplicationID int64
+	URL           string
+	Secret        string
+	Events        []string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Webhook delivery states
+const (
+	WebhookPending   = "pending"
+	WebhookSucceeded = "succeeded"
+	WebhookFailed    = "failed"
+)
+
+// WebhookDelivery is one event queued for one endpoint. URL and Secret are
+// the endpoint's, filled in when the delivery is claimed for sending.
+type WebhookDelivery struct {
+	ID            int64
+	EndpointID    int64
+	EventID       string
+	EventType     string
+	Payload       []byte
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	URL           string
+	Secret        string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// WebhookAttempt logs one HTTP attempt; StatusCode is zero when the
+// endpoint did not answer
+type WebhookAttempt struct {
+	DeliveryID int64
+	Attempt    int
+	StatusCode int
+	Error      string
+	Duration   time.Duration
+	CreatedAt  time.Time
+}
@@ -1,22 +1,61 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log"
+	"time"
 
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/cache"
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/go-sql-driver/mysql"
+)
+
+var (
+	// ErrTokenTaken is returned when a generated token collides with an existing one
+	ErrTokenTaken = errors.New("application token already exists")
+	
+	ErrApplicationNotFound = errors.New("application not found")
 )
 
 type ApplicationRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	cache *cache.Tiered[models.Application]
 }
 
 func NewApplicationRepository(db *sql.DB) *ApplicationRepository {
 	return &ApplicationRepository{db: db}
 }
 
+// EnableCache serves GetByToken from c. Writes made through this repository
+// invalidate the affected entries.
+func (r *ApplicationRepository) EnableCache(c *cache.Tiered[models.Application]) {
+	r.cache = c
+}
+
 // GetByToken retrieves application by token
 func (r *ApplicationRepository) GetByToken(token string) (*models.Application, error) {
+	if r.cache == nil {
+		return r.getByToken(token)
+	}
+	
+	app, err := r.cache.Get(context.Background(), token, func() (models.Application, error) {
+		app, err := r.getByToken(token)
+		if err != nil {
+			return models.Application{}, err
+		}
+		return *app, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	return &app, nil
+}
+
+func (r *ApplicationRepository) getByToken(token string) (*models.Application, error) {
 	var app models.Application
 	
 	query := `SELECT id, token, name, chats_count, created_at, updated_at 
@@ -33,7 +72,7 @@ func (r *ApplicationRepository) GetByToken(token string) (*models.Application, e
 	)
 	
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("application not found")
+		return nil, ErrApplicationNotFound
 	}
 	
 	if err != nil {
@@ -42,3 +81,198 @@ func (r *ApplicationRepository) GetByToken(token string) (*models.Application, e
 	
 	return &app, nil
 }
+
+// Create inserts a new application
+func (r *ApplicationRepository) Create(token, name string) (*models.Application, error) {
+	now := time.Now()
+	
+	query := `INSERT INTO applications (token, name, chats_count, created_at, updated_at) 
+	          VALUES (?, ?, ?, ?, ?)`
+	
+	result, err := r.db.Exec(query, token, name, 0, now, now)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+			return nil, ErrTokenTaken
+		}
+		return nil, fmt.Errorf("failed to create application: %w", err)
+	}
+	
+	appID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get application ID: %w", err)
+	}
+	
+	r.invalidate(token)
+	
+	app := &models.Application{
+		ID:         appID,
+		Token:      token,
+		Name:       name,
+		ChatsCount: 0,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	
+	return app, nil
+}
+
+// List retrieves a page of applications, newest first
+func (r *ApplicationRepository) List(offset, limit int) ([]models.Application, error) {
+	query := `SELECT id, token, name, chats_count, created_at, updated_at 
+	          FROM applications 
+	          ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`
+	
+	rows, err := r.db.Query(query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	
+	apps := []models.Application{}
+	for rows.Next() {
+		var app models.Application
+		if err := rows.Scan(
+			&app.ID,
+			&app.Token,
+			&app.Name,
+			&app.ChatsCount,
+			&app.CreatedAt,
+			&app.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		apps = append(apps, app)
+	}
+	
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	
+	return apps, nil
+}
+
+// Count returns the total number of applications
+func (r *ApplicationRepository) Count() (int, error) {
+	var count int
+	
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM applications`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	
+	return count, nil
+}
+
+// Rename changes an application's name
+func (r *ApplicationRepository) Rename(appID int64, name string) error {
+	query := `UPDATE applications SET name = ?, updated_at = ? WHERE id = ?`
+	
+	result, err := r.db.Exec(query, name, time.Now(), appID)
+	if err != nil {
+		return fmt.Errorf("failed to rename application: %w", err)
+	}
+	
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if affected == 0 {
+		// MySQL reports 0 when the name is unchanged, so check the row exists
+		var exists int
+		err := r.db.QueryRow(`SELECT 1 FROM applications WHERE id = ?`, appID).Scan(&exists)
+		if err == sql.ErrNoRows {
+			return ErrApplicationNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+	}
+	
+	r.invalidateByID(appID)
+	return nil
+}
+
+// ChatStats returns stored and actual chat counts for one application
+func (r *ApplicationRepository) ChatStats(appID int64) (*models.CounterStats, error) {
+	var s models.CounterStats
+	
+	query := `SELECT a.id, a.token, a.chats_count, COUNT(c.id), COALESCE(MAX(c.number), 0) 
+	          FROM applications a 
+	          LEFT JOIN chats c ON c.application_id = a.id 
+	          WHERE a.id = ? 
+	          GROUP BY a.id, a.token, a.chats_count`
+	
+	err := r.db.QueryRow(query, appID).Scan(&s.ID, &s.Token, &s.StoredCount, &s.ActualCount, &s.MaxNumber)
+	if err == sql.ErrNoRows {
+		return nil, ErrApplicationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	
+	return &s, nil
+}
+
+// ListChatStats returns stored and actual chat counts for up to limit
+// applications with an ID greater than afterID
+func (r *ApplicationRepository) ListChatStats(afterID int64, limit int) ([]models.CounterStats, error) {
+	query := `SELECT a.id, a.token, a.chats_count, COUNT(c.id), COALESCE(MAX(c.number), 0) 
+	          FROM applications a 
+	          LEFT JOIN chats c ON c.application_id = a.id 
+	          WHERE a.id > ? 
+	          GROUP BY a.id, a.token, a.chats_count 
+	          ORDER BY a.id ASC LIMIT ?`
+	
+	rows, err := r.db.Query(query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	
+	var stats []models.CounterStats
+	for rows.Next() {
+		var s models.CounterStats
+		if err := rows.Scan(&s.ID, &s.Token, &s.StoredCount, &s.ActualCount, &s.MaxNumber); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		stats = append(stats, s)
+	}
+	
+	return stats, rows.Err()
+}
+
+// UpdateChatsCount overwrites the cached chats_count column
+func (r *ApplicationRepository) UpdateChatsCount(appID int64, count int) error {
+	query := `UPDATE applications SET chats_count = ? WHERE id = ?`
+	
+	if _, err := r.db.Exec(query, count, appID); err != nil {
+		return fmt.Errorf("failed to update chats_count: %w", err)
+	}
+	
+	r.invalidateByID(appID)
+	return nil
+}
+
+// invalidate drops cached lookups for tokens. A failure only delays the
+// change until the entries expire, so it is logged rather than returned.
+func (r *ApplicationRepository) invalidate(tokens ...string) {
+	if r.cache == nil {
+		return
+	}
+	if err := r.cache.Invalidate(context.Background(), tokens...); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+func (r *ApplicationRepository) invalidateByID(appID int64) {
+	if r.cache == nil {
+		return
+	}
+	
+	var token string
+	if err := r.db.QueryRow(`SELECT token FROM applications WHERE id = ?`, appID).Scan(&token); err != nil {
+		log.Printf("Warning: Failed to look up application %d for cache invalidation: %v", appID, err)
+		return
+	}
+	r.invalidate(token)
+}
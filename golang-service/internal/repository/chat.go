@@ -1,21 +1,38 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log"
 	"time"
 
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/cache"
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
 )
 
+var ErrChatNotFound = errors.New("chat not found")
+
 type ChatRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	cache *cache.Tiered[models.Chat]
 }
 
 func NewChatRepository(db *sql.DB) *ChatRepository {
 	return &ChatRepository{db: db}
 }
 
+// EnableCache serves GetByApplicationAndNumber from c. Writes made through
+// this repository invalidate the affected entries.
+func (r *ChatRepository) EnableCache(c *cache.Tiered[models.Chat]) {
+	r.cache = c
+}
+
+func chatCacheKey(appID int64, number int) string {
+	return fmt.Sprintf("%d:%d", appID, number)
+}
+
 // Create inserts a new chat
 func (r *ChatRepository) Create(appID int64, number int) (*models.Chat, error) {
 	now := time.Now()
@@ -33,6 +50,9 @@ func (r *ChatRepository) Create(appID int64, number int) (*models.Chat, error) {
 		return nil, fmt.Errorf("failed to get chat ID: %w", err)
 	}
 	
+	// A lookup made before the chat existed may be cached as a miss
+	r.invalidate(chatCacheKey(appID, number))
+	
 	chat := &models.Chat{
 		ID:             chatID,
 		ApplicationID:  appID,
@@ -47,6 +67,25 @@ func (r *ChatRepository) Create(appID int64, number int) (*models.Chat, error) {
 
 // GetByApplicationAndNumber retrieves chat by app ID and chat number
 func (r *ChatRepository) GetByApplicationAndNumber(appID int64, number int) (*models.Chat, error) {
+	if r.cache == nil {
+		return r.getByApplicationAndNumber(appID, number)
+	}
+	
+	chat, err := r.cache.Get(context.Background(), chatCacheKey(appID, number), func() (models.Chat, error) {
+		chat, err := r.getByApplicationAndNumber(appID, number)
+		if err != nil {
+			return models.Chat{}, err
+		}
+		return *chat, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	return &chat, nil
+}
+
+func (r *ChatRepository) getByApplicationAndNumber(appID int64, number int) (*models.Chat, error) {
 	var chat models.Chat
 	
 	query := `SELECT id, application_id, number, messages_count, created_at, updated_at 
@@ -63,7 +102,7 @@ func (r *ChatRepository) GetByApplicationAndNumber(appID int64, number int) (*mo
 	)
 	
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("chat not found")
+		return nil, ErrChatNotFound
 	}
 	
 	if err != nil {
@@ -72,3 +111,199 @@ func (r *ChatRepository) GetByApplicationAndNumber(appID int64, number int) (*mo
 	
 	return &chat, nil
 }
+
+// ListByApplication retrieves a page of an application's chats ordered by number
+func (r *ChatRepository) ListByApplication(appID int64, offset, limit int) ([]models.Chat, error) {
+	query := `SELECT id, application_id, number, messages_count, created_at, updated_at 
+	          FROM chats 
+	          WHERE application_id = ? 
+	          ORDER BY number ASC LIMIT ? OFFSET ?`
+	
+	rows, err := r.db.Query(query, appID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	
+	chats := []models.Chat{}
+	for rows.Next() {
+		var chat models.Chat
+		if err := rows.Scan(
+			&chat.ID,
+			&chat.ApplicationID,
+			&chat.Number,
+			&chat.MessagesCount,
+			&chat.CreatedAt,
+			&chat.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		chats = append(chats, chat)
+	}
+	
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	
+	return chats, nil
+}
+
+// CountByApplication returns the number of chats an application has
+func (r *ChatRepository) CountByApplication(appID int64) (int, error) {
+	var count int
+	
+	query := `SELECT COUNT(*) FROM chats WHERE application_id = ?`
+	
+	if err := r.db.QueryRow(query, appID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	
+	return count, nil
+}
+
+// MessageStats returns stored and actual message counts for one chat
+func (r *ChatRepository) MessageStats(chatID int64) (*models.CounterStats, error) {
+	var s models.CounterStats
+	
+	query := `SELECT c.id, c.messages_count, COUNT(m.id), COALESCE(MAX(m.number), 0) 
+	          FROM chats c 
+	          LEFT JOIN messages m ON m.chat_id = c.id 
+	          WHERE c.id = ? 
+	          GROUP BY c.id, c.messages_count`
+	
+	err := r.db.QueryRow(query, chatID).Scan(&s.ID, &s.StoredCount, &s.ActualCount, &s.MaxNumber)
+	if err == sql.ErrNoRows {
+		return nil, ErrChatNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	
+	return &s, nil
+}
+
+// Purge deletes a chat with all of its messages and returns the deleted
+// message IDs so callers can clean up caches and search indexes. Reactions
+// and read receipts go with them through ON DELETE CASCADE.
+func (r *ChatRepository) Purge(chatID int64) ([]int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+	
+	var appID int64
+	var number int
+	err = tx.QueryRow(`SELECT application_id, number FROM chats WHERE id = ? FOR UPDATE`, chatID).Scan(&appID, &number)
+	if err == sql.ErrNoRows {
+		return nil, ErrChatNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	
+	rows, err := tx.Query(`SELECT id FROM messages WHERE chat_id = ? FOR UPDATE`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	messageIDs := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		messageIDs = append(messageIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	
+	if _, err := tx.Exec(`DELETE FROM messages WHERE chat_id = ?`, chatID); err != nil {
+		return nil, fmt.Errorf("failed to delete messages: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM chats WHERE id = ?`, chatID); err != nil {
+		return nil, fmt.Errorf("failed to delete chat: %w", err)
+	}
+	
+	query := `UPDATE applications 
+	          SET chats_count = (SELECT COUNT(*) FROM chats WHERE application_id = ?) 
+	          WHERE id = ?`
+	if _, err := tx.Exec(query, appID, appID); err != nil {
+		return nil, fmt.Errorf("failed to update chats_count: %w", err)
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	
+	r.invalidate(chatCacheKey(appID, number))
+	return messageIDs, nil
+}
+
+// ListMessageStats returns stored and actual message counts for up to limit
+// chats with an ID greater than afterID
+func (r *ChatRepository) ListMessageStats(afterID int64, limit int) ([]models.CounterStats, error) {
+	query := `SELECT c.id, c.messages_count, COUNT(m.id), COALESCE(MAX(m.number), 0) 
+	          FROM chats c 
+	          LEFT JOIN messages m ON m.chat_id = c.id 
+	          WHERE c.id > ? 
+	          GROUP BY c.id, c.messages_count 
+	          ORDER BY c.id ASC LIMIT ?`
+	
+	rows, err := r.db.Query(query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	
+	var stats []models.CounterStats
+	for rows.Next() {
+		var s models.CounterStats
+		if err := rows.Scan(&s.ID, &s.StoredCount, &s.ActualCount, &s.MaxNumber); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		stats = append(stats, s)
+	}
+	
+	return stats, rows.Err()
+}
+
+// UpdateMessagesCount overwrites the cached messages_count column
+func (r *ChatRepository) UpdateMessagesCount(chatID int64, count int) error {
+	query := `UPDATE chats SET messages_count = ? WHERE id = ?`
+	
+	if _, err := r.db.Exec(query, count, chatID); err != nil {
+		return fmt.Errorf("failed to update messages_count: %w", err)
+	}
+	
+	r.invalidateByID(chatID)
+	return nil
+}
+
+// invalidate drops cached lookups. A failure only delays the change until
+// the entries expire, so it is logged rather than returned.
+func (r *ChatRepository) invalidate(keys ...string) {
+	if r.cache == nil {
+		return
+	}
+	if err := r.cache.Invalidate(context.Background(), keys...); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+func (r *ChatRepository) invalidateByID(chatID int64) {
+	if r.cache == nil {
+		return
+	}
+	
+	var appID int64
+	var number int
+	query := `SELECT application_id, number FROM chats WHERE id = ?`
+	if err := r.db.QueryRow(query, chatID).Scan(&appID, &number); err != nil {
+		log.Printf("Warning: Failed to look up chat %d for cache invalidation: %v", chatID, err)
+		return
+	}
+	r.invalidate(chatCacheKey(appID, number))
+}
@@ -2,12 +2,15 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
 )
 
+var ErrMessageNotFound = errors.New("message not found")
+
 type MessageRepository struct {
 	db *sql.DB
 }
@@ -44,3 +47,262 @@ func (r *MessageRepository) Create(chatID int64, number int, body string) (*mode
 	
 	return message, nil
 }
+
+// GetByChatAndNumber retrieves message by chat ID and message number
+func (r *MessageRepository) GetByChatAndNumber(chatID int64, number int) (*models.Message, error) {
+	var message models.Message
+	
+	query := `SELECT id, chat_id, number, body, created_at, updated_at 
+	          FROM messages 
+	          WHERE chat_id = ? AND number = ? LIMIT 1`
+	
+	err := r.db.QueryRow(query, chatID, number).Scan(
+		&message.ID,
+		&message.ChatID,
+		&message.Number,
+		&message.Body,
+		&message.CreatedAt,
+		&message.UpdatedAt,
+	)
+	
+	if err == sql.ErrNoRows {
+		return nil, ErrMessageNotFound
+	}
+	
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	
+	return &message, nil
+}
+
+// Update replaces a message's body and returns the updated message
+func (r *MessageRepository) Update(chatID int64, number int, body string) (*models.Message, error) {
+	now := time.Now()
+	
+	query := `UPDATE messages SET body = ?, updated_at = ? WHERE chat_id = ? AND number = ?`
+	
+	if _, err := r.db.Exec(query, body, now, chatID, number); err != nil {
+		return nil, fmt.Errorf("failed to update message: %w", err)
+	}
+	
+	// Read back rather than trust RowsAffected, which is zero for an
+	// unchanged body
+	return r.GetByChatAndNumber(chatID, number)
+}
+
+// Delete removes a message and returns it as it was. Its number is not
+// reused. Reactions go with it through ON DELETE CASCADE.
+func (r *MessageRepository) Delete(chatID int64, number int) (*models.Message, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+	
+	var message models.Message
+	query := `SELECT id, chat_id, number, body, created_at, updated_at 
+	          FROM messages 
+	          WHERE chat_id = ? AND number = ? LIMIT 1 FOR UPDATE`
+	
+	err = tx.QueryRow(query, chatID, number).Scan(
+		&message.ID,
+		&message.ChatID,
+		&message.Number,
+		&message.Body,
+		&message.CreatedAt,
+		&message.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrMessageNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	
+	if _, err := tx.Exec(`DELETE FROM messages WHERE id = ?`, message.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete message: %w", err)
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	
+	return &message, nil
+}
+
+// ListAfter retrieves up to limit messages with a number greater than afterNumber, oldest first
+func (r *MessageRepository) ListAfter(chatID int64, afterNumber, limit int) ([]models.Message, error) {
+	query := `SELECT id, chat_id, number, body, created_at, updated_at 
+	          FROM messages 
+	          WHERE chat_id = ? AND number > ? 
+	          ORDER BY number ASC LIMIT ?`
+	
+	rows, err := r.db.Query(query, chatID, afterNumber, limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	
+	var messages []models.Message
+	for rows.Next() {
+		var message models.Message
+		if err := rows.Scan(
+			&message.ID,
+			&message.ChatID,
+			&message.Number,
+			&message.Body,
+			&message.CreatedAt,
+			&message.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		messages = append(messages, message)
+	}
+	
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	
+	return messages, nil
+}
+
+// Full-text search modes supported by MySQL
+const (
+	SearchModeNatural = "natural"
+	SearchModeBoolean = "boolean"
+)
+
+// Search runs a FULLTEXT query over a chat's messages using idx_messages_body.
+// It returns one page of matches ordered by relevance plus the total match count.
+func (r *MessageRepository) Search(chatID int64, query, mode string, offset, limit int) ([]models.Message, int, error) {
+	against := "IN NATURAL LANGUAGE MODE"
+	if mode == SearchModeBoolean {
+		against = "IN BOOLEAN MODE"
+	}
+	match := `MATCH(body) AGAINST(? ` + against + `)`
+	
+	var total int
+	countQuery := `SELECT COUNT(*) FROM messages WHERE chat_id = ? AND ` + match
+	if err := r.db.QueryRow(countQuery, chatID, query).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("search error: %w", err)
+	}
+	
+	if total == 0 {
+		return []models.Message{}, 0, nil
+	}
+	
+	searchQuery := `SELECT id, chat_id, number, body, created_at, updated_at 
+	                FROM messages 
+	                WHERE chat_id = ? AND ` + match + ` 
+	                ORDER BY ` + match + ` DESC, number ASC 
+	                LIMIT ? OFFSET ?`
+	
+	rows, err := r.db.Query(searchQuery, chatID, query, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("search error: %w", err)
+	}
+	defer rows.Close()
+	
+	messages := []models.Message{}
+	for rows.Next() {
+		var message models.Message
+		if err := rows.Scan(
+			&message.ID,
+			&message.ChatID,
+			&message.Number,
+			&message.Body,
+			&message.CreatedAt,
+			&message.UpdatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("search error: %w", err)
+		}
+		messages = append(messages, message)
+	}
+	
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("search error: %w", err)
+	}
+	
+	return messages, total, nil
+}
+
+// ListAfterID retrieves up to limit messages across all chats with an ID
+// greater than afterID, in ID order, for keyset-paginated full scans
+func (r *MessageRepository) ListAfterID(afterID int64, limit int) ([]models.Message, error) {
+	query := `SELECT id, chat_id, number, body, created_at, updated_at 
+	          FROM messages 
+	          WHERE id > ? 
+	          ORDER BY id ASC LIMIT ?`
+	
+	rows, err := r.db.Query(query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	
+	var messages []models.Message
+	for rows.Next() {
+		var message models.Message
+		if err := rows.Scan(
+			&message.ID,
+			&message.ChatID,
+			&message.Number,
+			&message.Body,
+			&message.CreatedAt,
+			&message.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		messages = append(messages, message)
+	}
+	
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	
+	return messages, nil
+}
+
+// Count returns the total number of messages
+func (r *MessageRepository) Count() (int, error) {
+	var count int
+	
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM messages`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	
+	return count, nil
+}
+
+// CountInChat returns the number of messages a chat has
+func (r *MessageRepository) CountInChat(chatID int64) (int, error) {
+	var count int
+	
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM messages WHERE chat_id = ?`, chatID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	
+	return count, nil
+}
+
+// CountByChat returns the number of messages per chat ID
+func (r *MessageRepository) CountByChat() (map[int64]int, error) {
+	rows, err := r.db.Query(`SELECT chat_id, COUNT(*) FROM messages GROUP BY chat_id`)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var chatID int64
+		var count int
+		if err := rows.Scan(&chatID, &count); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		counts[chatID] = count
+	}
+	
+	return counts, rows.Err()
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/cache"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+)
+
+// ErrQuotaNotFound means the application has no plan assignment and uses
+// the default plan
+var ErrQuotaNotFound = errors.New("application quota not found")
+
+type QuotaRepository struct {
+	db    *sql.DB
+	cache *cache.Tiered[models.ApplicationQuota]
+}
+
+func NewQuotaRepository(db *sql.DB) *QuotaRepository {
+	return &QuotaRepository{db: db}
+}
+
+// EnableCache serves Get from c. Set and Delete invalidate the entry.
+func (r *QuotaRepository) EnableCache(c *cache.Tiered[models.ApplicationQuota]) {
+	r.cache = c
+}
+
+// Get returns an application's plan assignment, or ErrQuotaNotFound
+func (r *QuotaRepository) Get(appID int64) (*models.ApplicationQuota, error) {
+	if r.cache == nil {
+		return r.get(appID)
+	}
+	
+	quota, err := r.cache.Get(context.Background(), strconv.FormatInt(appID, 10), func() (models.ApplicationQuota, error) {
+		quota, err := r.get(appID)
+		if err != nil {
+			return models.ApplicationQuota{}, err
+		}
+		return *quota, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	return &quota, nil
+}
+
+func (r *QuotaRepository) get(appID int64) (*models.ApplicationQuota, error) {
+	var q models.ApplicationQuota
+	var maxChats, maxMessagesPerChat, messagesPerDay sql.NullInt64
+	var maxBodyBytes sql.NullInt32
+	
+	query := `SELECT application_id, plan, max_chats, max_messages_per_chat, messages_per_day,
+	                 max_body_bytes, created_at, updated_at
+	          FROM application_quotas
+	          WHERE application_id = ? LIMIT 1`
+	
+	err := r.db.QueryRow(query, appID).Scan(
+		&q.ApplicationID,
+		&q.Plan,
+		&maxChats,
+		&maxMessagesPerChat,
+		&messagesPerDay,
+		&maxBodyBytes,
+		&q.CreatedAt,
+		&q.UpdatedAt,
+	)
+	
+	if err == sql.ErrNoRows {
+		return nil, ErrQuotaNotFound
+	}
+	
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	
+	if maxChats.Valid {
+		q.MaxChats = &maxChats.Int64
+	}
+	if maxMessagesPerChat.Valid {
+		q.MaxMessagesPerChat = &maxMessagesPerChat.Int64
+	}
+	if messagesPerDay.Valid {
+		q.MessagesPerDay = &messagesPerDay.Int64
+	}
+	if maxBodyBytes.Valid {
+		value := int(maxBodyBytes.Int32)
+		q.MaxBodyBytes = &value
+	}
+	
+	return &q, nil
+}
+
+// Set assigns an application to a plan with optional per-limit overrides
+func (r *QuotaRepository) Set(q *models.ApplicationQuota) error {
+	now := time.Now()
+	
+	query := `INSERT INTO application_quotas
+	            (application_id, plan, max_chats, max_messages_per_chat, messages_per_day, max_body_bytes, created_at, updated_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	          ON DUPLICATE KEY UPDATE
+	            plan = VALUES(plan),
+	            max_chats = VALUES(max_chats),
+	            max_messages_per_chat = VALUES(max_messages_per_chat),
+	            messages_per_day = VALUES(messages_per_day),
+	            max_body_bytes = VALUES(max_body_bytes),
+	            updated_at = VALUES(updated_at)`
+	
+	_, err := r.db.Exec(query, q.ApplicationID, q.Plan, q.MaxChats, q.MaxMessagesPerChat, q.MessagesPerDay, q.MaxBodyBytes, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to set application quota: %w", err)
+	}
+	
+	r.invalidate(q.ApplicationID)
+	return nil
+}
+
+// Delete returns an application to the default plan
+func (r *QuotaRepository) Delete(appID int64) error {
+	if _, err := r.db.Exec(`DELETE FROM application_quotas WHERE application_id = ?`, appID); err != nil {
+		return fmt.Errorf("failed to delete application quota: %w", err)
+	}
+	
+	r.invalidate(appID)
+	return nil
+}
+
+func (r *QuotaRepository) invalidate(appID int64) {
+	if r.cache == nil {
+		return
+	}
+	if err := r.cache.Invalidate(context.Background(), strconv.FormatInt(appID, 10)); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlDuplicateEntry is the MySQL error number for unique key violations
+const mysqlDuplicateEntry = 1062
+
+// mysqlNoReferencedRow is the MySQL error number for a foreign key that
+// points at a missing row
+const mysqlNoReferencedRow = 1452
+
+var (
+	ErrReactionExists   = errors.New("reaction already exists")
+	ErrReactionNotFound = errors.New("reaction not found")
+)
+
+type ReactionRepository struct {
+	db *sql.DB
+}
+
+func NewReactionRepository(db *sql.DB) *ReactionRepository {
+	return &ReactionRepository{db: db}
+}
+
+// Create inserts a reaction, failing with ErrReactionExists if the user
+// already reacted to the message with the same emoji
+func (r *ReactionRepository) Create(messageID int64, userID, emoji string) (*models.Reaction, error) {
+	now := time.Now()
+	
+	query := `INSERT INTO reactions (message_id, user_id, emoji, created_at, updated_at) 
+	          VALUES (?, ?, ?, ?, ?)`
+	
+	result, err := r.db.Exec(query, messageID, userID, emoji, now, now)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+			return nil, ErrReactionExists
+		}
+		return nil, fmt.Errorf("failed to create reaction: %w", err)
+	}
+	
+	reactionID, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reaction ID: %w", err)
+	}
+	
+	reaction := &models.Reaction{
+		ID:        reactionID,
+		MessageID: messageID,
+		UserID:    userID,
+		Emoji:     emoji,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	
+	return reaction, nil
+}
+
+// Delete removes a user's reaction, failing with ErrReactionNotFound if absent
+func (r *ReactionRepository) Delete(messageID int64, userID, emoji string) error {
+	query := `DELETE FROM reactions WHERE message_id = ? AND user_id = ? AND emoji = ?`
+	
+	result, err := r.db.Exec(query, messageID, userID, emoji)
+	if err != nil {
+		return fmt.Errorf("failed to delete reaction: %w", err)
+	}
+	
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	
+	if affected == 0 {
+		return ErrReactionNotFound
+	}
+	
+	return nil
+}
+
+// CountByMessage aggregates reactions for a message straight from the reactions table
+func (r *ReactionRepository) CountByMessage(messageID int64) (map[string]int, error) {
+	query := `SELECT emoji, COUNT(*) FROM reactions WHERE message_id = ? GROUP BY emoji`
+	
+	rows, err := r.db.Query(query, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	
+	counts := make(map[string]int)
+	for rows.Next() {
+		var emoji string
+		var count int
+		if err := rows.Scan(&emoji, &count); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		counts[emoji] = count
+	}
+	
+	return counts, rows.Err()
+}
+
+// ReplaceCounts overwrites the stored per-emoji counts for a message. It
+// fails with ErrMessageNotFound once the message has been deleted.
+func (r *ReactionRepository) ReplaceCounts(messageID int64, counts map[string]int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	
+	if _, err := tx.Exec(`DELETE FROM reaction_counts WHERE message_id = ?`, messageID); err != nil {
+		return fmt.Errorf("failed to clear reaction counts: %w", err)
+	}
+	
+	now := time.Now()
+	query := `INSERT INTO reaction_counts (message_id, emoji, count, created_at, updated_at) 
+	          VALUES (?, ?, ?, ?, ?)`
+	
+	for emoji, count := range counts {
+		if count <= 0 {
+			continue
+		}
+		if _, err := tx.Exec(query, messageID, emoji, count, now, now); err != nil {
+			var mysqlErr *mysql.MySQLError
+			if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlNoReferencedRow {
+				return ErrMessageNotFound
+			}
+			return fmt.Errorf("failed to store reaction count: %w", err)
+		}
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit reaction counts: %w", err)
+	}
+	
+	return nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type ReadReceiptRepository struct {
+	db *sql.DB
+}
+
+func NewReadReceiptRepository(db *sql.DB) *ReadReceiptRepository {
+	return &ReadReceiptRepository{db: db}
+}
+
+// Advance moves a user's read marker forward and returns the stored value.
+// The marker never moves backwards, so stale clients cannot un-read messages.
+func (r *ReadReceiptRepository) Advance(chatID int64, userID string, number int) (int, error) {
+	now := time.Now()
+	
+	query := `INSERT INTO chat_reads (chat_id, user_id, last_read_number, created_at, updated_at) 
+	          VALUES (?, ?, ?, ?, ?) 
+	          ON DUPLICATE KEY UPDATE 
+	            updated_at = IF(VALUES(last_read_number) > last_read_number, VALUES(updated_at), updated_at), 
+	            last_read_number = GREATEST(last_read_number, VALUES(last_read_number))`
+	
+	if _, err := r.db.Exec(query, chatID, userID, number, now, now); err != nil {
+		return 0, fmt.Errorf("failed to update read receipt: %w", err)
+	}
+	
+	return r.Get(chatID, userID)
+}
+
+// Get returns a user's read marker for a chat, zero if they never read it
+func (r *ReadReceiptRepository) Get(chatID int64, userID string) (int, error) {
+	var number int
+	
+	query := `SELECT last_read_number FROM chat_reads WHERE chat_id = ? AND user_id = ? LIMIT 1`
+	
+	err := r.db.QueryRow(query, chatID, userID).Scan(&number)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	
+	if err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	
+	return number, nil
+}
+
+// GetForChats returns a user's read markers keyed by chat ID
+func (r *ReadReceiptRepository) GetForChats(userID string, chatIDs []int64) (map[int64]int, error) {
+	markers := make(map[int64]int, len(chatIDs))
+	if len(chatIDs) == 0 {
+		return markers, nil
+	}
+	
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(chatIDs)), ",")
+	query := `SELECT chat_id, last_read_number FROM chat_reads 
+	          WHERE user_id = ? AND chat_id IN (` + placeholders + `)`
+	
+	args := make([]interface{}, 0, len(chatIDs)+1)
+	args = append(args, userID)
+	for _, id := range chatIDs {
+		args = append(args, id)
+	}
+	
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	
+	for rows.Next() {
+		var chatID int64
+		var number int
+		if err := rows.Scan(&chatID, &number); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		markers[chatID] = number
+	}
+	
+	return markers, rows.Err()
+}
+
+// UnreadCounts returns how many stored messages each chat has past the
+// user's read marker, keyed by chat ID. Deleted messages are not counted,
+// and chats with nothing unread are left out.
+func (r *ReadReceiptRepository) UnreadCounts(userID string, chatIDs []int64) (map[int64]int, error) {
+	counts := make(map[int64]int, len(chatIDs))
+	if len(chatIDs) == 0 {
+		return counts, nil
+	}
+	
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(chatIDs)), ",")
+	query := `SELECT m.chat_id, COUNT(*) FROM messages m 
+	          LEFT JOIN chat_reads r ON r.chat_id = m.chat_id AND r.user_id = ? 
+	          WHERE m.chat_id IN (` + placeholders + `) 
+	            AND m.number > COALESCE(r.last_read_number, 0) 
+	          GROUP BY m.chat_id`
+	
+	args := make([]interface{}, 0, len(chatIDs)+1)
+	args = append(args, userID)
+	for _, id := range chatIDs {
+		args = append(args, id)
+	}
+	
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	
+	for rows.Next() {
+		var chatID int64
+		var count int
+		if err := rows.Scan(&chatID, &count); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		counts[chatID] = count
+	}
+	
+	return counts, rows.Err()
+}
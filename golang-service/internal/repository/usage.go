@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+)
+
+type UsageRepository struct {
+	db *sql.DB
+}
+
+func NewUsageRepository(db *sql.DB) *UsageRepository {
+	return &UsageRepository{db: db}
+}
+
+// Upsert stores an hour's running totals for an application. Totals only
+// grow within an hour, so the larger value wins and replays are harmless.
+func (r *UsageRepository) Upsert(appID int64, hour time.Time, values map[string]int64) error {
+	if len(values) == 0 {
+		return nil
+	}
+	
+	now := time.Now()
+	rows := make([]string, 0, len(values))
+	args := make([]interface{}, 0, len(values)*6)
+	for metric, value := range values {
+		rows = append(rows, "(?, ?, ?, ?, ?, ?)")
+		args = append(args, appID, hour, metric, value, now, now)
+	}
+	
+	query := `INSERT INTO usage_hourly (application_id, hour, metric, value, created_at, updated_at)
+	          VALUES ` + strings.Join(rows, ", ") + `
+	          ON DUPLICATE KEY UPDATE
+	            value = GREATEST(value, VALUES(value)),
+	            updated_at = VALUES(updated_at)`
+	
+	if _, err := r.db.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to store usage: %w", err)
+	}
+	
+	return nil
+}
+
+// Range returns an application's usage rows for hours in [from, to)
+func (r *UsageRepository) Range(appID int64, from, to time.Time) ([]models.UsageRecord, error) {
+	query := `SELECT application_id, hour, metric, value
+	          FROM usage_hourly
+	          WHERE application_id = ? AND hour >= ? AND hour < ?
+	          ORDER BY hour, metric`
+	
+	rows, err := r.db.Query(query, appID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	
+	var records []models.UsageRecord
+	for rows.Next() {
+		var rec models.UsageRecord
+		if err := rows.Scan(&rec.ApplicationID, &rec.Hour, &rec.Metric, &rec.Value); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		records = append(records, rec)
+	}
+	
+	return records, rows.Err()
+}
+
+// Export streams usage rows for hours in [from, to) to fn, ordered by
+// application ID and hour. An appID of zero exports every application.
+func (r *UsageRepository) Export(from, to time.Time, appID int64, fn func(models.UsageRecord) error) error {
+	// LEFT JOIN keeps usage of applications deleted since
+	query := `SELECT u.application_id, COALESCE(a.token, ''), u.hour, u.metric, u.value
+	          FROM usage_hourly u
+	          LEFT JOIN applications a ON a.id = u.application_id
+	          WHERE u.hour >= ? AND u.hour < ?`
+	args := []interface{}{from, to}
+	if appID != 0 {
+		query += ` AND u.application_id = ?`
+		args = append(args, appID)
+	}
+	query += ` ORDER BY u.application_id, u.hour, u.metric`
+	
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	
+	for rows.Next() {
+		var rec models.UsageRecord
+		if err := rows.Scan(&rec.ApplicationID, &rec.ApplicationToken, &rec.Hour, &rec.Metric, &rec.Value); err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	
+	return rows.Err()
+}
@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// CreateEndpoint registers a webhook endpoint for an application
+func (r *WebhookRepository) CreateEndpoint(appID int64, url, secret string, events []string) (*models.WebhookEndpoint, error) {
+	now := time.Now()
+	
+	query := `INSERT INTO webhook_endpoints (application_id, url, secret, events, created_at, updated_at)
+	          VALUES (?, ?, ?, ?, ?, ?)`
+	
+	result, err := r.db.Exec(query, appID, url, secret, strings.Join(events, ","), now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook ID: %w", err)
+	}
+	
+	return &models.WebhookEndpoint{
+		ID:            id,
+		ApplicationID: appID,
+		URL:           url,
+		Secret:        secret,
+		Events:        events,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+// ListEndpoints returns an application's webhook endpoints, oldest first
+func (r *WebhookRepository) ListEndpoints(appID int64) ([]models.WebhookEndpoint, error) {
+	query := `SELECT id, application_id, url, secret, events, created_at, updated_at
+	          FROM webhook_endpoints
+	          WHERE application_id = ?
+	          ORDER BY id ASC`
+	
+	rows, err := r.db.Query(query, appID)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	
+	var endpoints []models.WebhookEndpoint
+	for rows.Next() {
+		var e models.WebhookEndpoint
+		var events string
+		if err := rows.Scan(&e.ID, &e.ApplicationID, &e.URL, &e.Secret, &events, &e.CreatedAt, &e.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		e.Events = strings.Split(events, ",")
+		endpoints = append(endpoints, e)
+	}
+	
+	return endpoints, rows.Err()
+}
+
+// GetEndpoint returns one of an application's endpoints, or ErrWebhookNotFound
+func (r *WebhookRepository) GetEndpoint(appID, id int64) (*models.WebhookEndpoint, error) {
+	var e models.WebhookEndpoint
+	var events string
+	
+	query := `SELECT id, application_id, url, secret, events, created_at, updated_at
+	          FROM webhook_endpoints
+	          WHERE id = ? AND application_id = ? LIMIT 1`
+	
+	err := r.db.QueryRow(query, id, appID).Scan(&e.ID, &e.ApplicationID, &e.URL, &e.Secret, &events, &e.CreatedAt, &e.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	
+	e.Events = strings.Split(events, ",")
+	return &e, nil
+}
+
+// DeleteEndpoint removes an endpoint; its queued deliveries and attempt
+// logs go with it through ON DELETE CASCADE
+func (r *WebhookRepository) DeleteEndpoint(appID, id int64) error {
+	result, err := r.db.Exec(`DELETE FROM webhook_endpoints WHERE id = ? AND application_id = ?`, id, appID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// Enqueue queues one event for each endpoint, due immediately
+func (r *WebhookRepository) Enqueue(endpointIDs []int64, eventID, eventType string, payload []byte) error {
+	if len(endpointIDs) == 0 {
+		return nil
+	}
+	
+	now := time.Now()
+	rows := make([]string, 0, len(endpointIDs))
+	args := make([]interface{}, 0, len(endpointIDs)*8)
+	for _, endpointID := range endpointIDs {
+		rows = append(rows, "(?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, endpointID, eventID, eventType, payload, models.WebhookPending, now, now, now)
+	}
+	
+	query := `INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, status, next_attempt_at, created_at, updated_at)
+	          VALUES ` + strings.Join(rows, ", ")
+	
+	if _, err := r.db.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to queue webhook deliveries: %w", err)
+	}
+	
+	return nil
+}
+
+// ClaimDue returns up to limit pending deliveries whose time has come and
+// pushes their next attempt lease into the future, so other replicas skip
+// them while they are being sent. A claim that is never recorded is retried
+// once the lease runs out.
+func (r *WebhookRepository) ClaimDue(limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+	
+	now := time.Now()
+	query := `SELECT d.id, d.endpoint_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	                 d.next_attempt_at, COALESCE(d.last_error, ''), e.url, e.secret, d.created_at, d.updated_at
+	          FROM webhook_deliveries d
+	          JOIN webhook_endpoints e ON e.id = d.endpoint_id
+	          WHERE d.status = ? AND d.next_attempt_at <= ?
+	          ORDER BY d.next_attempt_at ASC LIMIT ?
+	          FOR UPDATE OF d SKIP LOCKED`
+	
+	rows, err := tx.Query(query, models.WebhookPending, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	
+	var deliveries []models.WebhookDelivery
+	var ids []interface{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastError, &d.URL, &d.Secret, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		deliveries = append(deliveries, d)
+		ids = append(ids, d.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if len(deliveries) == 0 {
+		return nil, nil
+	}
+	
+	update := `UPDATE webhook_deliveries SET next_attempt_at = ?
+	           WHERE id IN (?` + strings.Repeat(", ?", len(ids)-1) + `)`
+	if _, err := tx.Exec(update, append([]interface{}{now.Add(lease)}, ids...)...); err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	
+	return deliveries, nil
+}
+
+// RecordAttempt logs an attempt and moves its delivery to status. Pending
+// deliveries are retried at nextAttempt.
+func (r *WebhookRepository) RecordAttempt(attempt *models.WebhookAttempt, status string, nextAttempt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+	
+	var statusCode sql.NullInt64
+	if attempt.StatusCode != 0 {
+		statusCode = sql.NullInt64{Int64: int64(attempt.StatusCode), Valid: true}
+	}
+	var attemptErr sql.NullString
+	if attempt.Error != "" {
+		attemptErr = sql.NullString{String: attempt.Error, Valid: true}
+	}
+	
+	query := `INSERT INTO webhook_attempts (delivery_id, attempt, status_code, error, duration_ms, created_at)
+	          VALUES (?, ?, ?, ?, ?, ?)`
+	if _, err := tx.Exec(query, attempt.DeliveryID, attempt.Attempt, statusCode, attemptErr, attempt.Duration.Milliseconds(), attempt.CreatedAt); err != nil {
+		return fmt.Errorf("failed to log webhook attempt: %w", err)
+	}
+	
+	query = `UPDATE webhook_deliveries
+	         SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, updated_at = ?
+	         WHERE id = ?`
+	if _, err := tx.Exec(query, status, attempt.Attempt, nextAttempt, attemptErr, time.Now(), attempt.DeliveryID); err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries returns one page of an endpoint's deliveries, newest first,
+// without payloads
+func (r *WebhookRepository) ListDeliveries(endpointID int64, offset, limit int) ([]models.WebhookDelivery, error) {
+	query := `SELECT id, endpoint_id, event_id, event_type, status, attempts, next_attempt_at,
+	                 COALESCE(last_error, ''), created_at, updated_at
+	          FROM webhook_deliveries
+	          WHERE endpoint_id = ?
+	          ORDER BY id DESC LIMIT ? OFFSET ?`
+	
+	rows, err := r.db.Query(query, endpointID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastError, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	
+	return deliveries, rows.Err()
+}
+
+// CountDeliveries returns how many deliveries an endpoint has
+func (r *WebhookRepository) CountDeliveries(endpointID int64) (int, error) {
+	var count int
+	
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM webhook_deliveries WHERE endpoint_id = ?`, endpointID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	
+	return count, nil
+}
+
+// GetDelivery returns one of an endpoint's deliveries, or ErrDeliveryNotFound
+func (r *WebhookRepository) GetDelivery(endpointID, id int64) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	
+	query := `SELECT id, endpoint_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+	                 COALESCE(last_error, ''), created_at, updated_at
+	          FROM webhook_deliveries
+	          WHERE id = ? AND endpoint_id = ? LIMIT 1`
+	
+	err := r.db.QueryRow(query, id, endpointID).Scan(&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &d.Payload,
+		&d.Status, &d.Attempts, &d.NextAttemptAt, &d.LastError, &d.CreatedAt, &d.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	
+	return &d, nil
+}
+
+// Attempts returns a delivery's attempt log in order
+func (r *WebhookRepository) Attempts(deliveryID int64) ([]models.WebhookAttempt, error) {
+	query := `SELECT delivery_id, attempt, status_code, COALESCE(error, ''), duration_ms, created_at
+	          FROM webhook_attempts
+	          WHERE delivery_id = ?
+	          ORDER BY id ASC`
+	
+	rows, err := r.db.Query(query, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	
+	var attempts []models.WebhookAttempt
+	for rows.Next() {
+		var a models.WebhookAttempt
+		var statusCode sql.NullInt64
+		var durationMS int64
+		if err := rows.Scan(&a.DeliveryID, &a.Attempt, &statusCode, &a.Error, &durationMS, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		a.StatusCode = int(statusCode.Int64)
+		a.Duration = time.Duration(durationMS) * time.Millisecond
+		attempts = append(attempts, a)
+	}
+	
+	return attempts, rows.Err()
+}
+
+// Requeue queues a fresh delivery of the same event to the same endpoint,
+// keeping the event ID so receivers can deduplicate
+func (r *WebhookRepository) Requeue(delivery *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	now := time.Now()
+	
+	query := `INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, status, next_attempt_at, created_at, updated_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	
+	result, err := r.db.Exec(query, delivery.EndpointID, delivery.EventID, delivery.EventType, delivery.Payload, models.WebhookPending, now, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to queue webhook delivery: %w", err)
+	}
+	
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delivery ID: %w", err)
+	}
+	
+	return &models.WebhookDelivery{
+		ID:            id,
+		EndpointID:    delivery.EndpointID,
+		EventID:       delivery.EventID,
+		EventType:     delivery.EventType,
+		Payload:       delivery.Payload,
+		Status:        models.WebhookPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
@@ -0,0 +1,120 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// englishStopwords is the stopword list used by the Elasticsearch english analyzer
+var englishStopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "if": true, "in": true,
+	"into": true, "is": true, "it": true, "no": true, "not": true, "of": true,
+	"on": true, "or": true, "such": true, "that": true, "the": true,
+	"their": true, "then": true, "there": true, "these": true, "they": true,
+	"this": true, "to": true, "was": true, "will": true, "with": true,
+}
+
+// Token is one analyzed word and its rune offsets in the source text
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// Tokenize lowercases text and splits it into words on anything that is not
+// a letter or digit. Stopwords are kept so highlight offsets stay complete.
+func Tokenize(text string) []Token {
+	var tokens []Token
+	runes := []rune(text)
+	
+	start := -1
+	for i := 0; i <= len(runes); i++ {
+		inWord := i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsNumber(runes[i]))
+		if inWord && start < 0 {
+			start = i
+		}
+		if !inWord && start >= 0 {
+			tokens = append(tokens, Token{
+				Term:  strings.ToLower(string(runes[start:i])),
+				Start: start,
+				End:   i,
+			})
+			start = -1
+		}
+	}
+	
+	return tokens
+}
+
+// Analyze turns text into index terms: lowercase, stopwords removed, stemmed
+func Analyze(text string) []string {
+	var terms []string
+	for _, token := range Tokenize(text) {
+		if term, ok := AnalyzeWord(token.Term); ok {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// AnalyzeWord stems one lowercase word, reporting false for stopwords
+func AnalyzeWord(word string) (string, bool) {
+	if englishStopwords[word] {
+		return "", false
+	}
+	return Stem(word), true
+}
+
+// fuzziness mirrors Elasticsearch's fuzziness: AUTO edit distances
+func fuzziness(term string) int {
+	switch n := len([]rune(term)); {
+	case n <= 2:
+		return 0
+	case n <= 5:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// withinDistance reports whether the Levenshtein distance between a and b is
+// at most max, bailing out as soon as every alignment exceeds it
+func withinDistance(a, b string, max int) bool {
+	ra, rb := []rune(a), []rune(b)
+	if abs(len(ra)-len(rb)) > max {
+		return false
+	}
+	
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > max {
+			return false
+		}
+		prev, curr = curr, prev
+	}
+	
+	return prev[len(rb)] <= max
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
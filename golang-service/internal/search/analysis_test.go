@@ -0,0 +1,127 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []Token
+	}{
+		{"", nil},
+		{"Hello, World!", []Token{{"hello", 0, 5}, {"world", 7, 12}}},
+		{"it's 2 o'clock", []Token{{"it", 0, 2}, {"s", 3, 4}, {"2", 5, 6}, {"o", 7, 8}, {"clock", 9, 14}}},
+		{"  tabs\tand\nlines  ", []Token{{"tabs", 2, 6}, {"and", 7, 10}, {"lines", 11, 16}}},
+		// Offsets count runes, not bytes
+		{"café über", []Token{{"café", 0, 4}, {"über", 5, 9}}},
+		{"مرحبا بالعالم", []Token{{"مرحبا", 0, 5}, {"بالعالم", 6, 13}}},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"The cats are running to the park", []string{"cat", "run", "park"}},
+		{"Connected connections CONNECTING", []string{"connect", "connect", "connect"}},
+		{"this is it", nil},
+		{"Version 2.0 of café", []string{"version", "2", "0", "café"}},
+	}
+	for _, tt := range tests {
+		if got := Analyze(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Analyze(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+// Expected stems are from Porter's reference vocabulary
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		// Short and non-ASCII words are left alone
+		"a": "a", "is": "is", "naïve": "naïve", "abc123": "abc123",
+		// Step 1a
+		"caresses": "caress", "ponies": "poni", "ties": "ti", "caress": "caress", "cats": "cat",
+		// Step 1b
+		"feed": "feed", "agreed": "agre", "plastered": "plaster", "bled": "bled",
+		"motoring": "motor", "sing": "sing", "conflated": "conflat", "troubled": "troubl",
+		"sized": "size", "hopping": "hop", "tanned": "tan", "falling": "fall",
+		"hissing": "hiss", "fizzed": "fizz", "failing": "fail", "filing": "file",
+		// Step 1c
+		"happy": "happi", "sky": "sky",
+		// Step 2
+		"relational": "relat", "conditional": "condit", "rational": "ration",
+		"valenci": "valenc", "hesitanci": "hesit", "digitizer": "digit",
+		"conformabli": "conform", "radicalli": "radic", "differentli": "differ",
+		"vileli": "vile", "analogousli": "analog", "vietnamization": "vietnam",
+		"predication": "predic", "operator": "oper", "feudalism": "feudal",
+		"decisiveness": "decis", "hopefulness": "hope", "callousness": "callous",
+		"formaliti": "formal", "sensitiviti": "sensit", "sensibiliti": "sensibl",
+		// Step 3
+		"triplicate": "triplic", "formative": "form", "formalize": "formal",
+		"electriciti": "electr", "electrical": "electr", "hopeful": "hope", "goodness": "good",
+		// Step 4
+		"revival": "reviv", "allowance": "allow", "inference": "infer", "airliner": "airlin",
+		"gyroscopic": "gyroscop", "adjustable": "adjust", "defensible": "defens",
+		"irritant": "irrit", "replacement": "replac", "adjustment": "adjust",
+		"dependent": "depend", "adoption": "adopt", "homologou": "homolog",
+		"communism": "commun", "activate": "activ", "angulariti": "angular",
+		"homologous": "homolog", "effective": "effect", "bowdlerize": "bowdler",
+		// Step 5
+		"probate": "probat", "rate": "rate", "cease": "ceas", "controll": "control", "roll": "roll",
+		// Whole words
+		"running": "run", "generalizations": "gener", "messages": "messag",
+	}
+	for word, want := range tests {
+		if got := Stem(word); got != want {
+			t.Errorf("Stem(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestFuzziness(t *testing.T) {
+	tests := map[string]int{
+		"": 0, "ab": 0, "abc": 1, "hello": 1, "hellos": 2, "internationalization": 2,
+		// Counted in runes
+		"éé": 0, "ééé": 1,
+	}
+	for term, want := range tests {
+		if got := fuzziness(term); got != want {
+			t.Errorf("fuzziness(%q) = %d, want %d", term, got, want)
+		}
+	}
+}
+
+func TestWithinDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want bool
+	}{
+		{"hello", "hello", 0, true},
+		{"hello", "hallo", 1, true},
+		{"hello", "hallo", 0, false},
+		{"hello", "helo", 1, true},
+		{"hello", "helloo", 1, true},
+		{"hello", "olleh", 2, false},
+		{"kitten", "sitting", 3, true},
+		{"kitten", "sitting", 2, false},
+		{"messag", "mesage", 1, false},
+		{"messag", "mesage", 2, true},
+		{"abc", "abcdef", 2, false},
+		{"", "ab", 2, true},
+		{"café", "cafe", 1, true},
+	}
+	for _, tt := range tests {
+		if got := withinDistance(tt.a, tt.b, tt.max); got != tt.want {
+			t.Errorf("withinDistance(%q, %q, %d) = %v, want %v", tt.a, tt.b, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ElasticsearchIndex talks to the index maintained by the Rails Message
+// model (body analyzed with english, chat_id, number, created_at, updated_at)
+type ElasticsearchIndex struct {
+	baseURL string
+	index   string
+	client  *http.Client
+}
+
+func NewElasticsearchIndex(baseURL, index string) *ElasticsearchIndex {
+	return &ElasticsearchIndex{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		index:   index,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (e *ElasticsearchIndex) Name() string {
+	return "elasticsearch"
+}
+
+func (e *ElasticsearchIndex) Index(ctx context.Context, doc Document) error {
+	path := fmt.Sprintf("/%s/_doc/%d", e.index, doc.ID)
+	return e.do(ctx, http.MethodPut, path, doc, nil)
+}
+
+func (e *ElasticsearchIndex) Delete(ctx context.Context, chatID, id int64) error {
+	path := fmt.Sprintf("/%s/_doc/%d", e.index, id)
+	err := e.do(ctx, http.MethodDelete, path, nil, nil)
+	if statusErr, ok := err.(*StatusError); ok && statusErr.Code == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// Query mirrors Message.search_messages: fuzzy multi_match on body with
+// HTML-escaped <mark> highlights, restricted to one chat. Boolean mode uses
+// simple_query_string so +, - and * behave like MySQL's boolean mode.
+func (e *ElasticsearchIndex) Query(ctx context.Context, q Query) (*Result, error) {
+	var match map[string]interface{}
+	if q.Mode == ModeBoolean {
+		match = map[string]interface{}{
+			"simple_query_string": map[string]interface{}{
+				"query":            q.Text,
+				"fields":           []string{"body"},
+				"default_operator": "or",
+			},
+		}
+	} else {
+		match = map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":     q.Text,
+				"fields":    []string{"body"},
+				"fuzziness": "AUTO",
+				"operator":  "or",
+			},
+		}
+	}
+	
+	body := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must":   match,
+				"filter": map[string]interface{}{"term": map[string]interface{}{"chat_id": q.ChatID}},
+			},
+		},
+		"highlight": map[string]interface{}{
+			// Escape the message text around <mark>, like the local Snippet
+			"encoder": "html",
+			"fields": map[string]interface{}{
+				"body": map[string]interface{}{
+					"pre_tags":  []string{"<mark>"},
+					"post_tags": []string{"</mark>"},
+				},
+			},
+		},
+		"from":             q.Offset,
+		"size":             q.Limit,
+		"track_total_hits": true,
+	}
+	
+	var response struct {
+		Hits struct {
+			Total struct {
+				Value int `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				Source    Document            `json:"_source"`
+				Highlight map[string][]string `json:"highlight"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	
+	if err := e.do(ctx, http.MethodPost, "/"+e.index+"/_search", body, &response); err != nil {
+		return nil, err
+	}
+	
+	result := &Result{Hits: make([]Hit, 0, len(response.Hits.Hits)), Total: response.Hits.Total.Value}
+	for _, h := range response.Hits.Hits {
+		hit := Hit{Document: h.Source}
+		if fragments := h.Highlight["body"]; len(fragments) > 0 {
+			hit.Highlight = strings.Join(fragments, " … ")
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+	
+	return result, nil
+}
+
+// messageMapping is the index definition declared by the Rails Message model
+var messageMapping = map[string]interface{}{
+	"settings": map[string]interface{}{
+		"index": map[string]interface{}{"number_of_shards": 1, "number_of_replicas": 0},
+	},
+	"mappings": map[string]interface{}{
+		"dynamic": false,
+		"properties": map[string]interface{}{
+			"id":         map[string]interface{}{"type": "integer"},
+			"number":     map[string]interface{}{"type": "integer"},
+			"body":       map[string]interface{}{"type": "text", "analyzer": "english"},
+			"chat_id":    map[string]interface{}{"type": "integer"},
+			"created_at": map[string]interface{}{"type": "date"},
+			"updated_at": map[string]interface{}{"type": "date"},
+		},
+	},
+}
+
+// EnsureIndex creates the index with the Message mapping when it does not
+// exist yet, and reports whether it did. Otherwise the first bulk request
+// would leave Elasticsearch to guess a dynamic mapping.
+func (e *ElasticsearchIndex) EnsureIndex(ctx context.Context) (bool, error) {
+	err := e.do(ctx, http.MethodHead, "/"+e.index, nil, nil)
+	if err == nil {
+		return false, nil
+	}
+	if statusErr, ok := err.(*StatusError); !ok || statusErr.Code != http.StatusNotFound {
+		return false, err
+	}
+	
+	if err := e.do(ctx, http.MethodPut, "/"+e.index, messageMapping, nil); err != nil {
+		return false, fmt.Errorf("failed to create index %s: %w", e.index, err)
+	}
+	return true, nil
+}
+
+// Bulk indexes docs in one _bulk request, failing if any item was rejected
+func (e *ElasticsearchIndex) Bulk(ctx context.Context, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	
+	var payload bytes.Buffer
+	encoder := json.NewEncoder(&payload)
+	for _, doc := range docs {
+		action := map[string]interface{}{
+			"index": map[string]interface{}{"_index": e.index, "_id": fmt.Sprint(doc.ID)},
+		}
+		if err := encoder.Encode(action); err != nil {
+			return fmt.Errorf("failed to encode bulk action: %w", err)
+		}
+		if err := encoder.Encode(doc); err != nil {
+			return fmt.Errorf("failed to encode document: %w", err)
+		}
+	}
+	
+	var response struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID     string          `json:"_id"`
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	
+	if err := e.doRaw(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", payload.Bytes(), &response); err != nil {
+		return err
+	}
+	
+	if response.Errors {
+		failed := 0
+		var first string
+		for _, item := range response.Items {
+			for _, result := range item {
+				if result.Status >= 300 {
+					if failed == 0 {
+						first = fmt.Sprintf("document %s: %s", result.ID, result.Error)
+					}
+					failed++
+				}
+			}
+		}
+		return &BulkError{Failed: failed, Total: len(docs), First: first}
+	}
+	
+	return nil
+}
+
+// Count returns the number of documents in the index
+func (e *ElasticsearchIndex) Count(ctx context.Context) (int, error) {
+	var response struct {
+		Count int `json:"count"`
+	}
+	
+	if err := e.do(ctx, http.MethodGet, "/"+e.index+"/_count", nil, &response); err != nil {
+		return 0, err
+	}
+	
+	return response.Count, nil
+}
+
+// CountByChat returns document counts per chat_id for up to maxChats chats
+func (e *ElasticsearchIndex) CountByChat(ctx context.Context, maxChats int) (map[int64]int, error) {
+	body := map[string]interface{}{
+		"size": 0,
+		"aggs": map[string]interface{}{
+			"chats": map[string]interface{}{
+				"terms": map[string]interface{}{"field": "chat_id", "size": maxChats},
+			},
+		},
+	}
+	
+	var response struct {
+		Aggregations struct {
+			Chats struct {
+				Buckets []struct {
+					Key      int64 `json:"key"`
+					DocCount int   `json:"doc_count"`
+				} `json:"buckets"`
+			} `json:"chats"`
+		} `json:"aggregations"`
+	}
+	
+	if err := e.do(ctx, http.MethodPost, "/"+e.index+"/_search", body, &response); err != nil {
+		return nil, err
+	}
+	
+	counts := make(map[int64]int, len(response.Aggregations.Chats.Buckets))
+	for _, bucket := range response.Aggregations.Chats.Buckets {
+		counts[bucket.Key] = bucket.DocCount
+	}
+	
+	return counts, nil
+}
+
+// BulkError reports documents Elasticsearch rejected inside a _bulk
+// request. Rejections are deterministic, so they are not retried.
+type BulkError struct {
+	Failed int
+	Total  int
+	First  string
+}
+
+func (e *BulkError) Error() string {
+	return fmt.Sprintf("bulk indexing rejected %d of %d documents, first: %s", e.Failed, e.Total, e.First)
+}
+
+// StatusError is returned when Elasticsearch answers with a non-2xx status
+type StatusError struct {
+	Code int
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("elasticsearch returned %d: %s", e.Code, e.Body)
+}
+
+func (e *ElasticsearchIndex) do(ctx context.Context, method, path string, in, out interface{}) error {
+	if in == nil {
+		return e.doRaw(ctx, method, path, "", nil, out)
+	}
+	
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	return e.doRaw(ctx, method, path, "application/json", payload, out)
+}
+
+func (e *ElasticsearchIndex) doRaw(ctx context.Context, method, path, contentType string, payload []byte, out interface{}) error {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	
+	req, err := http.NewRequestWithContext(ctx, method, e.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("elasticsearch request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &StatusError{Code: resp.StatusCode, Body: string(body)}
+	}
+	
+	if out == nil {
+		return nil
+	}
+	
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	
+	return nil
+}
@@ -0,0 +1,30 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Highlights are rendered as HTML by clients, so Elasticsearch must escape
+// the message text around the <mark> tags
+func TestElasticsearchQueryRequestsEscapedHighlights(t *testing.T) {
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(`{"hits":{"total":{"value":0},"hits":[]}}`))
+	}))
+	defer server.Close()
+	
+	es := NewElasticsearchIndex(server.URL, "messages")
+	if _, err := es.Query(context.Background(), Query{ChatID: 1, Text: "hello", Limit: 10}); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	
+	highlight, _ := request["highlight"].(map[string]interface{})
+	if highlight["encoder"] != "html" {
+		t.Errorf("highlight = %v, want encoder html", highlight)
+	}
+}
@@ -0,0 +1,565 @@
+package search
+
+import (
+	"bufio"
+	"container/list"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/services"
+)
+
+// compactThreshold is how many log entries a chat accumulates before its
+// snapshot is rewritten and the log truncated
+const compactThreshold = 1000
+
+// fuzzyWeight discounts terms reached through edit distance
+const fuzzyWeight = 0.5
+
+// maxOpenSegments bounds how many chats are kept in memory, each holding an
+// open log file. The least recently used chats are closed beyond it.
+const maxOpenSegments = 256
+
+// EmbeddedIndex is an on-disk inverted index for deployments without
+// Elasticsearch. Each chat has its own posting lists, persisted as a gob
+// snapshot (chat-<id>.snap) plus an append-only JSON log (chat-<id>.log)
+// that is replayed on load and folded into the snapshot periodically.
+type EmbeddedIndex struct {
+	dir     string
+	maxOpen int
+	
+	// mu guards the segment table only; each segment has its own lock, so
+	// chats are indexed and searched in parallel
+	mu       sync.Mutex
+	segments map[int64]*segment
+	recent   *list.List // of *segment, most recently used first
+}
+
+// segment holds one chat's documents and posting lists
+type segment struct {
+	Docs     map[int64]Document
+	Postings map[string]map[int64]int
+	
+	mu         sync.Mutex
+	chatID     int64
+	loaded     bool
+	closed     bool
+	element    *list.Element
+	vocabulary []string
+	pending    int
+	log        *os.File
+}
+
+// logEntry is one line of a chat's append-only log
+type logEntry struct {
+	Op  string    `json:"op"`
+	Doc *Document `json:"doc,omitempty"`
+	ID  int64     `json:"id,omitempty"`
+}
+
+func NewEmbeddedIndex(dir string) (*EmbeddedIndex, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create index directory: %w", err)
+	}
+	
+	return &EmbeddedIndex{
+		dir:      dir,
+		maxOpen:  maxOpenSegments,
+		segments: make(map[int64]*segment),
+		recent:   list.New(),
+	}, nil
+}
+
+func (e *EmbeddedIndex) Name() string {
+	return "embedded"
+}
+
+func (e *EmbeddedIndex) Index(ctx context.Context, doc Document) error {
+	seg, err := e.acquire(doc.ChatID)
+	if err != nil {
+		return err
+	}
+	defer seg.mu.Unlock()
+	
+	seg.add(doc)
+	return e.appendLog(seg, logEntry{Op: "index", Doc: &doc})
+}
+
+func (e *EmbeddedIndex) Delete(ctx context.Context, chatID, id int64) error {
+	seg, err := e.acquire(chatID)
+	if err != nil {
+		return err
+	}
+	defer seg.mu.Unlock()
+	
+	if _, ok := seg.Docs[id]; !ok {
+		return nil
+	}
+	
+	seg.remove(id)
+	return e.appendLog(seg, logEntry{Op: "delete", ID: id})
+}
+
+// queryTerm is one parsed query word with the index terms it expands to
+type queryTerm struct {
+	required  bool
+	excluded  bool
+	expansion map[string]float64
+}
+
+func (e *EmbeddedIndex) Query(ctx context.Context, q Query) (*Result, error) {
+	seg, err := e.acquire(q.ChatID)
+	if err != nil {
+		return nil, err
+	}
+	defer seg.mu.Unlock()
+	
+	terms := seg.parseQuery(q.Text, q.Mode)
+	matched := make(map[string]bool)
+	scores := make(map[int64]float64)
+	
+	// Excluded and required terms filter; the rest only add to the score
+	eligible := make(map[int64]bool, len(seg.Docs))
+	for id := range seg.Docs {
+		eligible[id] = true
+	}
+	
+	for _, term := range terms {
+		docs := make(map[int64]float64)
+		for indexTerm, weight := range term.expansion {
+			postings := seg.Postings[indexTerm]
+			idf := math.Log(1 + float64(len(seg.Docs))/float64(len(postings)+1))
+			for id, tf := range postings {
+				docs[id] += weight * float64(tf) * idf
+			}
+			if !term.excluded && len(postings) > 0 {
+				matched[indexTerm] = true
+			}
+		}
+		
+		switch {
+		case term.excluded:
+			for id := range docs {
+				delete(eligible, id)
+			}
+		case term.required:
+			for id := range eligible {
+				if _, ok := docs[id]; !ok {
+					delete(eligible, id)
+				}
+			}
+			fallthrough
+		default:
+			for id, score := range docs {
+				scores[id] += score
+			}
+		}
+	}
+	
+	var hits []Hit
+	for id, score := range scores {
+		if !eligible[id] || score == 0 {
+			continue
+		}
+		hits = append(hits, Hit{Document: seg.Docs[id]})
+	}
+	
+	sort.Slice(hits, func(i, j int) bool {
+		si, sj := scores[hits[i].ID], scores[hits[j].ID]
+		if si != sj {
+			return si > sj
+		}
+		return hits[i].Number < hits[j].Number
+	})
+	
+	result := &Result{Total: len(hits), Hits: []Hit{}}
+	if q.Offset < len(hits) {
+		end := len(hits)
+		if q.Limit > 0 && q.Offset+q.Limit < end {
+			end = q.Offset + q.Limit
+		}
+		result.Hits = hits[q.Offset:end]
+	}
+	
+	// Highlight by stem so "running" marks "runs" and fuzzy matches show up
+	for i := range result.Hits {
+		result.Hits[i].Highlight = services.SnippetFunc(result.Hits[i].Body, func(word string) bool {
+			term, ok := AnalyzeWord(word)
+			return ok && matched[term]
+		})
+	}
+	
+	return result, nil
+}
+
+// parseQuery analyzes query words and expands each into index terms.
+// A trailing * requests prefix matching; natural mode adds fuzzy matches
+// with Elasticsearch's AUTO distances; boolean mode honours + and -.
+func (s *segment) parseQuery(text, mode string) []queryTerm {
+	var terms []queryTerm
+	
+	for _, field := range strings.Fields(strings.ToLower(text)) {
+		term := queryTerm{expansion: make(map[string]float64)}
+		
+		if mode == ModeBoolean {
+			term.required = strings.HasPrefix(field, "+")
+			term.excluded = strings.HasPrefix(field, "-")
+		}
+		prefix := strings.HasSuffix(field, "*")
+		
+		for _, token := range Tokenize(field) {
+			stem, ok := AnalyzeWord(token.Term)
+			if !ok {
+				continue
+			}
+			term.expansion[stem] = 1
+			
+			if prefix {
+				for _, candidate := range s.withPrefix(token.Term) {
+					term.expansion[candidate] = 1
+				}
+				for _, candidate := range s.withPrefix(stem) {
+					term.expansion[candidate] = 1
+				}
+			}
+			
+			if mode != ModeBoolean {
+				if distance := fuzziness(stem); distance > 0 {
+					for _, candidate := range s.terms() {
+						if _, ok := term.expansion[candidate]; !ok && withinDistance(stem, candidate, distance) {
+							term.expansion[candidate] = fuzzyWeight
+						}
+					}
+				}
+			}
+		}
+		
+		if len(term.expansion) > 0 {
+			terms = append(terms, term)
+		}
+	}
+	
+	return terms
+}
+
+// terms returns the sorted vocabulary, rebuilt lazily after changes
+func (s *segment) terms() []string {
+	if s.vocabulary == nil {
+		s.vocabulary = make([]string, 0, len(s.Postings))
+		for term := range s.Postings {
+			s.vocabulary = append(s.vocabulary, term)
+		}
+		sort.Strings(s.vocabulary)
+	}
+	return s.vocabulary
+}
+
+func (s *segment) withPrefix(prefix string) []string {
+	vocabulary := s.terms()
+	start := sort.SearchStrings(vocabulary, prefix)
+	
+	var matches []string
+	for i := start; i < len(vocabulary) && strings.HasPrefix(vocabulary[i], prefix); i++ {
+		matches = append(matches, vocabulary[i])
+	}
+	return matches
+}
+
+func (s *segment) add(doc Document) {
+	if _, ok := s.Docs[doc.ID]; ok {
+		s.remove(doc.ID)
+	}
+	
+	s.Docs[doc.ID] = doc
+	for _, term := range Analyze(doc.Body) {
+		if s.Postings[term] == nil {
+			s.Postings[term] = make(map[int64]int)
+			s.vocabulary = nil
+		}
+		s.Postings[term][doc.ID]++
+	}
+}
+
+func (s *segment) remove(id int64) {
+	doc, ok := s.Docs[id]
+	if !ok {
+		return
+	}
+	
+	delete(s.Docs, id)
+	for _, term := range Analyze(doc.Body) {
+		delete(s.Postings[term], id)
+		if len(s.Postings[term]) == 0 {
+			delete(s.Postings, term)
+			s.vocabulary = nil
+		}
+	}
+}
+
+func (s *segment) apply(entry logEntry) {
+	switch entry.Op {
+	case "index":
+		if entry.Doc != nil {
+			s.add(*entry.Doc)
+		}
+	case "delete":
+		s.remove(entry.ID)
+	}
+}
+
+func newSegment() *segment {
+	return &segment{
+		Docs:     make(map[int64]Document),
+		Postings: make(map[string]map[int64]int),
+	}
+}
+
+func (e *EmbeddedIndex) snapshotPath(chatID int64) string {
+	return filepath.Join(e.dir, fmt.Sprintf("chat-%d.snap", chatID))
+}
+
+func (e *EmbeddedIndex) logPath(chatID int64) string {
+	return filepath.Join(e.dir, fmt.Sprintf("chat-%d.log", chatID))
+}
+
+// acquire returns a chat's segment locked, loading it on first use. The
+// caller must unlock it.
+func (e *EmbeddedIndex) acquire(chatID int64) (*segment, error) {
+	for {
+		e.mu.Lock()
+		seg, ok := e.segments[chatID]
+		if ok {
+			e.recent.MoveToFront(seg.element)
+		} else {
+			seg = newSegment()
+			seg.chatID = chatID
+			seg.element = e.recent.PushFront(seg)
+			e.segments[chatID] = seg
+			e.evict()
+		}
+		e.mu.Unlock()
+		
+		seg.mu.Lock()
+		if seg.closed {
+			// Evicted or rebuilt since it was looked up
+			seg.mu.Unlock()
+			continue
+		}
+		if !seg.loaded {
+			if err := e.load(seg); err != nil {
+				seg.close()
+				seg.mu.Unlock()
+				e.forget(seg)
+				return nil, err
+			}
+		}
+		return seg, nil
+	}
+}
+
+// evict closes least recently used segments beyond maxOpen. Segments in use
+// are skipped rather than waited for, so a chat is never loaded again while
+// its previous segment is still writing. The segment just added is kept.
+// e.mu must be held.
+func (e *EmbeddedIndex) evict() {
+	for el := e.recent.Back(); el != e.recent.Front() && e.recent.Len() > e.maxOpen; {
+		seg := el.Value.(*segment)
+		el = el.Prev()
+		if !seg.mu.TryLock() {
+			continue
+		}
+		e.recent.Remove(seg.element)
+		delete(e.segments, seg.chatID)
+		seg.close()
+		seg.mu.Unlock()
+	}
+}
+
+// forget drops a segment that failed to load so the next call retries
+func (e *EmbeddedIndex) forget(seg *segment) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	
+	if e.segments[seg.chatID] == seg {
+		e.recent.Remove(seg.element)
+		delete(e.segments, seg.chatID)
+	}
+}
+
+// load reads a segment's snapshot and replays its log
+func (e *EmbeddedIndex) load(seg *segment) error {
+	chatID := seg.chatID
+	if file, err := os.Open(e.snapshotPath(chatID)); err == nil {
+		err = gob.NewDecoder(file).Decode(seg)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read index snapshot for chat %d: %w", chatID, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to open index snapshot for chat %d: %w", chatID, err)
+	}
+	
+	logFile, err := os.OpenFile(e.logPath(chatID), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open index log for chat %d: %w", chatID, err)
+	}
+	
+	scanner := bufio.NewScanner(logFile)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry logEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A torn final line from a crash; everything before it is intact
+			break
+		}
+		seg.apply(entry)
+		seg.pending++
+	}
+	
+	seg.log = logFile
+	seg.loaded = true
+	return nil
+}
+
+// close releases the log file; seg.mu must be held
+func (s *segment) close() {
+	s.closed = true
+	if s.log != nil {
+		s.log.Close()
+		s.log = nil
+	}
+}
+
+func (e *EmbeddedIndex) appendLog(seg *segment, entry logEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode index entry: %w", err)
+	}
+	
+	if _, err := seg.log.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write index log for chat %d: %w", seg.chatID, err)
+	}
+	
+	seg.pending++
+	if seg.pending >= compactThreshold {
+		return e.compact(seg)
+	}
+	return nil
+}
+
+// compact writes a fresh snapshot and truncates the log it supersedes
+func (e *EmbeddedIndex) compact(seg *segment) error {
+	if err := writeSnapshot(e.snapshotPath(seg.chatID), seg); err != nil {
+		return err
+	}
+	
+	if err := seg.log.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate index log for chat %d: %w", seg.chatID, err)
+	}
+	seg.pending = 0
+	return nil
+}
+
+func writeSnapshot(path string, seg *segment) error {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create index snapshot: %w", err)
+	}
+	
+	if err := gob.NewEncoder(file).Encode(seg); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write index snapshot: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to sync index snapshot: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close index snapshot: %w", err)
+	}
+	
+	return os.Rename(tmp, path)
+}
+
+// Rebuild replaces the whole index with the documents produced by source.
+// The new index is built beside the old one and swapped in when complete.
+// Other calls wait until it finishes.
+func (e *EmbeddedIndex) Rebuild(ctx context.Context, source func(yield func(Document) error) error) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	
+	segments := make(map[int64]*segment)
+	count := 0
+	
+	err := source(func(doc Document) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		seg, ok := segments[doc.ChatID]
+		if !ok {
+			seg = newSegment()
+			segments[doc.ChatID] = seg
+		}
+		seg.add(doc)
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	
+	staging := e.dir + ".rebuild"
+	if err := os.RemoveAll(staging); err != nil {
+		return count, fmt.Errorf("failed to clear staging directory: %w", err)
+	}
+	if err := os.MkdirAll(staging, 0o755); err != nil {
+		return count, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	
+	for chatID, seg := range segments {
+		path := filepath.Join(staging, fmt.Sprintf("chat-%d.snap", chatID))
+		if err := writeSnapshot(path, seg); err != nil {
+			return count, err
+		}
+	}
+	
+	e.closeSegments()
+	if err := os.RemoveAll(e.dir); err != nil {
+		return count, fmt.Errorf("failed to remove old index: %w", err)
+	}
+	if err := os.Rename(staging, e.dir); err != nil {
+		return count, fmt.Errorf("failed to swap in rebuilt index: %w", err)
+	}
+	
+	return count, nil
+}
+
+// Close releases the open log files
+func (e *EmbeddedIndex) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	
+	e.closeSegments()
+	return nil
+}
+
+// closeSegments waits for calls in progress and closes every segment.
+// e.mu must be held.
+func (e *EmbeddedIndex) closeSegments() {
+	for _, seg := range e.segments {
+		seg.mu.Lock()
+		seg.close()
+		seg.mu.Unlock()
+	}
+	e.segments = make(map[int64]*segment)
+	e.recent.Init()
+}
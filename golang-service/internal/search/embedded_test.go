@@ -0,0 +1,163 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newTestEmbeddedIndex(t *testing.T, dir string) *EmbeddedIndex {
+	t.Helper()
+	index, err := NewEmbeddedIndex(dir)
+	if err != nil {
+		t.Fatalf("NewEmbeddedIndex() error = %v", err)
+	}
+	t.Cleanup(func() { index.Close() })
+	return index
+}
+
+func queryNumbers(t *testing.T, index SearchIndex, q Query) []int {
+	t.Helper()
+	result, err := index.Query(context.Background(), q)
+	if err != nil {
+		t.Fatalf("Query(%q) error = %v", q.Text, err)
+	}
+	return hitNumbers(result)
+}
+
+func TestEmbeddedIndexQuery(t *testing.T) {
+	ctx := context.Background()
+	index := newTestEmbeddedIndex(t, t.TempDir())
+	for i, body := range []string{
+		"The deployment is running smoothly",
+		"We deployed the new release yesterday",
+		"Lunch at noon?",
+		"<b>deploy</b> again",
+	} {
+		if err := index.Index(ctx, Document{ID: int64(i + 1), ChatID: 1, Number: i + 1, Body: body}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	index.Index(ctx, Document{ID: 10, ChatID: 2, Number: 1, Body: "deploy in another chat"})
+	
+	tests := []struct {
+		name string
+		q    Query
+		want []int
+	}{
+		// "deploying" stems to deploi like "deployed"; "deployment" is one edit away
+		{"stemmed", Query{ChatID: 1, Text: "deploying"}, []int{2, 4, 1}},
+		{"prefix", Query{ChatID: 1, Text: "deploy*"}, []int{1, 2, 4}},
+		{"fuzzy", Query{ChatID: 1, Text: "lanch"}, []int{3}},
+		{"other chat", Query{ChatID: 2, Text: "deploy"}, []int{1}},
+		{"boolean required", Query{ChatID: 1, Text: "+deploy +again", Mode: ModeBoolean}, []int{4}},
+		{"boolean excluded", Query{ChatID: 1, Text: "deploy -again", Mode: ModeBoolean}, []int{2}},
+		{"boolean is not fuzzy", Query{ChatID: 1, Text: "lanch", Mode: ModeBoolean}, []int{}},
+		{"stopwords only", Query{ChatID: 1, Text: "the is"}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := queryNumbers(t, index, tt.q); !equalInts(got, tt.want) {
+				t.Errorf("Query(%q) = %v, want %v", tt.q.Text, got, tt.want)
+			}
+		})
+	}
+	
+	result, _ := index.Query(ctx, Query{ChatID: 1, Text: "deploy again"})
+	if highlight := result.Hits[0].Highlight; !strings.Contains(highlight, "&lt;b&gt;<mark>deploy</mark>") {
+		t.Errorf("Highlight = %q, want escaped HTML around <mark>", highlight)
+	}
+}
+
+func TestEmbeddedIndexDeleteAndReopen(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	index := newTestEmbeddedIndex(t, dir)
+	
+	// Enough entries to compact once, so both the snapshot and the log are read back
+	for i := 1; i <= compactThreshold+10; i++ {
+		if err := index.Index(ctx, Document{ID: int64(i), ChatID: 7, Number: i, Body: fmt.Sprintf("message %d", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := index.Delete(ctx, 7, 3); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := index.Delete(ctx, 7, 99999); err != nil {
+		t.Errorf("Delete(missing) error = %v", err)
+	}
+	index.Close()
+	
+	reopened := newTestEmbeddedIndex(t, dir)
+	// Boolean mode, since numbers are fuzzy matched against each other
+	if got := queryNumbers(t, reopened, Query{ChatID: 7, Text: "3", Mode: ModeBoolean}); len(got) != 0 {
+		t.Errorf("deleted message found after reopening: %v", got)
+	}
+	if got := queryNumbers(t, reopened, Query{ChatID: 7, Text: fmt.Sprint(compactThreshold + 10), Mode: ModeBoolean}); !equalInts(got, []int{compactThreshold + 10}) {
+		t.Errorf("last message not found after reopening: %v", got)
+	}
+}
+
+func TestEmbeddedIndexEvictsLeastRecentlyUsedChats(t *testing.T) {
+	ctx := context.Background()
+	index := newTestEmbeddedIndex(t, t.TempDir())
+	index.maxOpen = 2
+	
+	for chatID := int64(1); chatID <= 5; chatID++ {
+		if err := index.Index(ctx, Document{ID: chatID, ChatID: chatID, Number: 1, Body: "hello"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if open := len(index.segments); open != 2 {
+		t.Errorf("%d segments open, want 2", open)
+	}
+	for _, seg := range index.segments {
+		if seg.chatID < 4 {
+			t.Errorf("chat %d still open, want only the two most recent", seg.chatID)
+		}
+	}
+	
+	// Evicted chats are loaded again from disk
+	if got := queryNumbers(t, index, Query{ChatID: 1, Text: "hello"}); !equalInts(got, []int{1}) {
+		t.Errorf("Query(evicted chat) = %v, want [1]", got)
+	}
+}
+
+// Run with -race: chats are indexed in parallel while eviction closes them
+func TestEmbeddedIndexConcurrentChats(t *testing.T) {
+	ctx := context.Background()
+	index := newTestEmbeddedIndex(t, t.TempDir())
+	index.maxOpen = 3
+	
+	var wg sync.WaitGroup
+	for chatID := int64(1); chatID <= 8; chatID++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 1; n <= 50; n++ {
+				doc := Document{ID: chatID*1000 + int64(n), ChatID: chatID, Number: n, Body: fmt.Sprintf("chat %d message", chatID)}
+				if err := index.Index(ctx, doc); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := index.Query(ctx, Query{ChatID: chatID, Text: "message"}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	
+	for chatID := int64(1); chatID <= 8; chatID++ {
+		result, err := index.Query(ctx, Query{ChatID: chatID, Text: "message"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Total != 50 {
+			t.Errorf("chat %d has %d messages, want 50", chatID, result.Total)
+		}
+	}
+}
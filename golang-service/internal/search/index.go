@@ -0,0 +1,114 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+)
+
+// Query modes shared by every backend
+const (
+	ModeNatural = "natural"
+	ModeBoolean = "boolean"
+)
+
+// Document is the indexed form of a message, matching Message#as_indexed_json
+type Document struct {
+	ID        int64     `json:"id"`
+	Number    int       `json:"number"`
+	Body      string    `json:"body"`
+	ChatID    int64     `json:"chat_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Query restricts a search to one chat and selects a page of hits
+type Query struct {
+	ChatID int64
+	Text   string
+	Mode   string
+	Offset int
+	Limit  int
+}
+
+// Hit is a matching document. Highlight is empty when the backend
+// cannot highlight; callers then build their own snippet.
+type Hit struct {
+	Document
+	Highlight string
+}
+
+type Result struct {
+	Hits  []Hit
+	Total int
+}
+
+// SearchIndex is implemented by every message search backend
+type SearchIndex interface {
+	// Name identifies the backend in API responses
+	Name() string
+	
+	// Index adds or replaces a document
+	Index(ctx context.Context, doc Document) error
+	
+	// Delete removes a document from a chat; deleting a missing document
+	// is not an error
+	Delete(ctx context.Context, chatID, id int64) error
+	
+	// Query returns one page of hits ordered by relevance
+	Query(ctx context.Context, q Query) (*Result, error)
+}
+
+// New builds the backend selected by configuration
+func New(cfg config.SearchConfig, messageRepo *repository.MessageRepository) (SearchIndex, error) {
+	switch cfg.Backend {
+	case "mysql", "":
+		return NewMySQLIndex(messageRepo), nil
+	case "elasticsearch":
+		return NewElasticsearchIndex(cfg.ElasticsearchURL, cfg.ElasticsearchIndex), nil
+	case "embedded":
+		return NewEmbeddedIndex(cfg.IndexDir)
+	case "memory":
+		return NewMemoryIndex(), nil
+	default:
+		return nil, fmt.Errorf("unknown search backend %q", cfg.Backend)
+	}
+}
+
+// DocumentFromMessage converts a stored message into its indexed form
+func DocumentFromMessage(message models.Message) Document {
+	return Document{
+		ID:        message.ID,
+		Number:    message.Number,
+		Body:      message.Body,
+		ChatID:    message.ChatID,
+		CreatedAt: message.CreatedAt,
+		UpdatedAt: message.UpdatedAt,
+	}
+}
+
+// ScanMessages streams every message from MySQL in ID order, batchSize rows
+// per query, starting after afterID
+func ScanMessages(messageRepo *repository.MessageRepository, afterID int64, batchSize int, yield func(Document) error) error {
+	for {
+		messages, err := messageRepo.ListAfterID(afterID, batchSize)
+		if err != nil {
+			return err
+		}
+		
+		for _, message := range messages {
+			if err := yield(DocumentFromMessage(message)); err != nil {
+				return err
+			}
+			afterID = message.ID
+		}
+		
+		if len(messages) < batchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package search
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemoryIndex is an in-process fake for offline tests. Matching is a plain
+// case-insensitive word comparison with no relevance ranking.
+type MemoryIndex struct {
+	mu   sync.RWMutex
+	docs map[int64]Document
+}
+
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{docs: make(map[int64]Document)}
+}
+
+func (m *MemoryIndex) Name() string {
+	return "memory"
+}
+
+func (m *MemoryIndex) Index(ctx context.Context, doc Document) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.docs[doc.ID] = doc
+	return nil
+}
+
+func (m *MemoryIndex) Delete(ctx context.Context, chatID, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	delete(m.docs, id)
+	return nil
+}
+
+func (m *MemoryIndex) Query(ctx context.Context, q Query) (*Result, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	var required, optional, excluded []string
+	for _, field := range strings.Fields(strings.ToLower(q.Text)) {
+		switch {
+		case q.Mode == ModeBoolean && strings.HasPrefix(field, "+"):
+			required = append(required, strings.TrimPrefix(field, "+"))
+		case q.Mode == ModeBoolean && strings.HasPrefix(field, "-"):
+			excluded = append(excluded, strings.TrimPrefix(field, "-"))
+		default:
+			optional = append(optional, field)
+		}
+	}
+	
+	var hits []Hit
+	for _, doc := range m.docs {
+		if doc.ChatID != q.ChatID {
+			continue
+		}
+		
+		words := make(map[string]bool)
+		for _, word := range strings.Fields(strings.ToLower(doc.Body)) {
+			words[strings.Trim(word, ".,!?;:\"'()")] = true
+		}
+		
+		if matchesAll(words, required) && !matchesAny(words, excluded) &&
+			(len(optional) == 0 || matchesAny(words, optional)) {
+			hits = append(hits, Hit{Document: doc})
+		}
+	}
+	
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Number < hits[j].Number })
+	
+	result := &Result{Total: len(hits), Hits: []Hit{}}
+	if q.Offset < len(hits) {
+		end := len(hits)
+		if q.Limit > 0 && q.Offset+q.Limit < end {
+			end = q.Offset + q.Limit
+		}
+		result.Hits = hits[q.Offset:end]
+	}
+	
+	return result, nil
+}
+
+func matchesAll(words map[string]bool, terms []string) bool {
+	for _, term := range terms {
+		if !words[term] {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAny(words map[string]bool, terms []string) bool {
+	for _, term := range terms {
+		if words[term] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package search
+
+import (
+	"context"
+	"testing"
+)
+
+func seedMemoryIndex(t *testing.T, docs ...Document) *MemoryIndex {
+	t.Helper()
+	index := NewMemoryIndex()
+	for _, doc := range docs {
+		if err := index.Index(context.Background(), doc); err != nil {
+			t.Fatalf("Index(%d) error = %v", doc.ID, err)
+		}
+	}
+	return index
+}
+
+func hitNumbers(result *Result) []int {
+	numbers := make([]int, len(result.Hits))
+	for i, hit := range result.Hits {
+		numbers[i] = hit.Number
+	}
+	return numbers
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMemoryIndexQuery(t *testing.T) {
+	index := seedMemoryIndex(t,
+		Document{ID: 1, ChatID: 1, Number: 1, Body: "Hello world!"},
+		Document{ID: 2, ChatID: 1, Number: 2, Body: "hello there"},
+		Document{ID: 3, ChatID: 1, Number: 3, Body: "goodbye world"},
+		Document{ID: 4, ChatID: 2, Number: 1, Body: "hello from another chat"},
+	)
+	
+	tests := []struct {
+		name  string
+		query Query
+		want  []int
+		total int
+	}{
+		{"any word", Query{ChatID: 1, Text: "hello world"}, []int{1, 2, 3}, 3},
+		{"case and punctuation", Query{ChatID: 1, Text: "WORLD"}, []int{1, 3}, 2},
+		{"other chat", Query{ChatID: 2, Text: "hello"}, []int{1}, 1},
+		{"no match", Query{ChatID: 1, Text: "missing"}, []int{}, 0},
+		{"boolean required", Query{ChatID: 1, Text: "+hello +world", Mode: ModeBoolean}, []int{1}, 1},
+		{"boolean excluded", Query{ChatID: 1, Text: "world -goodbye", Mode: ModeBoolean}, []int{1}, 1},
+		{"page", Query{ChatID: 1, Text: "hello world", Offset: 1, Limit: 1}, []int{2}, 3},
+		{"page past end", Query{ChatID: 1, Text: "hello", Offset: 5, Limit: 10}, []int{}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := index.Query(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if got := hitNumbers(result); !equalInts(got, tt.want) || result.Total != tt.total {
+				t.Errorf("Query() = %v (total %d), want %v (total %d)", got, result.Total, tt.want, tt.total)
+			}
+		})
+	}
+}
+
+func TestMemoryIndexReplacesAndDeletes(t *testing.T) {
+	ctx := context.Background()
+	index := seedMemoryIndex(t, Document{ID: 1, ChatID: 1, Number: 1, Body: "first draft"})
+	
+	// Indexing the same ID again replaces the document, as an edit does
+	if err := index.Index(ctx, Document{ID: 1, ChatID: 1, Number: 1, Body: "final text"}); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := index.Query(ctx, Query{ChatID: 1, Text: "draft"}); result.Total != 0 {
+		t.Errorf("old body still matches after reindexing")
+	}
+	if result, _ := index.Query(ctx, Query{ChatID: 1, Text: "final"}); result.Total != 1 {
+		t.Errorf("new body does not match after reindexing")
+	}
+	
+	if err := index.Delete(ctx, 1, 1); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if result, _ := index.Query(ctx, Query{ChatID: 1, Text: "final"}); result.Total != 0 {
+		t.Errorf("deleted document still matches")
+	}
+	
+	// Deleting a missing document is not an error
+	if err := index.Delete(ctx, 1, 42); err != nil {
+		t.Errorf("Delete(missing) error = %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package search
+
+import (
+	"context"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+)
+
+// MySQLIndex searches the idx_messages_body FULLTEXT index. MySQL maintains
+// that index on insert, so Index and Delete have nothing to do.
+type MySQLIndex struct {
+	messageRepo *repository.MessageRepository
+}
+
+func NewMySQLIndex(messageRepo *repository.MessageRepository) *MySQLIndex {
+	return &MySQLIndex{messageRepo: messageRepo}
+}
+
+func (m *MySQLIndex) Name() string {
+	return "mysql_fulltext"
+}
+
+func (m *MySQLIndex) Index(ctx context.Context, doc Document) error {
+	return nil
+}
+
+func (m *MySQLIndex) Delete(ctx context.Context, chatID, id int64) error {
+	return nil
+}
+
+func (m *MySQLIndex) Query(ctx context.Context, q Query) (*Result, error) {
+	mode := repository.SearchModeNatural
+	if q.Mode == ModeBoolean {
+		mode = repository.SearchModeBoolean
+	}
+	
+	messages, total, err := m.messageRepo.Search(q.ChatID, q.Text, mode, q.Offset, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	
+	result := &Result{Hits: make([]Hit, 0, len(messages)), Total: total}
+	for _, message := range messages {
+		result.Hits = append(result.Hits, Hit{Document: DocumentFromMessage(message)})
+	}
+	
+	return result, nil
+}
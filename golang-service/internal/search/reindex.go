@@ -0,0 +1,249 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// BulkIndexer accepts batches of documents; ElasticsearchIndex implements it
+type BulkIndexer interface {
+	Bulk(ctx context.Context, docs []Document) error
+}
+
+// CheckpointStore remembers the last message ID known to be indexed
+type CheckpointStore interface {
+	Load(ctx context.Context) (int64, error)
+	Save(ctx context.Context, lastID int64) error
+	Clear(ctx context.Context) error
+}
+
+// RedisCheckpoint keeps the reindex checkpoint in a single Redis key
+type RedisCheckpoint struct {
+	redis *redis.Client
+	key   string
+}
+
+func NewRedisCheckpoint(redisClient *redis.Client, key string) *RedisCheckpoint {
+	return &RedisCheckpoint{redis: redisClient, key: key}
+}
+
+func (c *RedisCheckpoint) Load(ctx context.Context) (int64, error) {
+	value, err := c.redis.Get(ctx, c.key).Result()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	
+	lastID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid checkpoint %q: %w", value, err)
+	}
+	return lastID, nil
+}
+
+func (c *RedisCheckpoint) Save(ctx context.Context, lastID int64) error {
+	if err := c.redis.Set(ctx, c.key, lastID, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (c *RedisCheckpoint) Clear(ctx context.Context) error {
+	if err := c.redis.Del(ctx, c.key).Err(); err != nil {
+		return fmt.Errorf("failed to clear checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Reindexer copies messages into a bulk index. Batches are read in ID order
+// and indexed concurrently; the checkpoint only advances past a batch once
+// every batch before it has succeeded, so a restart never skips messages.
+type Reindexer struct {
+	Fetch       func(afterID int64, limit int) ([]Document, error)
+	Target      BulkIndexer
+	Checkpoint  CheckpointStore
+	BatchSize   int
+	Concurrency int
+	MaxRetries  int
+}
+
+type ReindexStats struct {
+	Indexed  int
+	Batches  int
+	StartID  int64
+	LastID   int64
+	Duration time.Duration
+}
+
+type reindexBatch struct {
+	seq    int
+	docs   []Document
+	lastID int64
+}
+
+type reindexResult struct {
+	seq    int
+	count  int
+	lastID int64
+	err    error
+}
+
+func (r *Reindexer) Run(ctx context.Context) (ReindexStats, error) {
+	started := time.Now()
+	stats := ReindexStats{}
+	
+	startID, err := r.Checkpoint.Load(ctx)
+	if err != nil {
+		return stats, err
+	}
+	stats.StartID, stats.LastID = startID, startID
+	
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	
+	batches := make(chan reindexBatch, r.Concurrency)
+	results := make(chan reindexResult, r.Concurrency)
+	fetchErr := make(chan error, 1)
+	
+	// Producer: keyset pagination has to be sequential
+	go func() {
+		defer close(batches)
+		afterID := startID
+		for seq := 0; ; seq++ {
+			docs, err := r.Fetch(afterID, r.BatchSize)
+			if err != nil {
+				fetchErr <- err
+				return
+			}
+			if len(docs) == 0 {
+				return
+			}
+			afterID = docs[len(docs)-1].ID
+			
+			select {
+			case batches <- reindexBatch{seq: seq, docs: docs, lastID: afterID}:
+			case <-ctx.Done():
+				return
+			}
+			
+			if len(docs) < r.BatchSize {
+				return
+			}
+		}
+	}()
+	
+	var workers sync.WaitGroup
+	for i := 0; i < r.Concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for batch := range batches {
+				err := r.bulkWithRetry(ctx, batch.docs)
+				select {
+				case results <- reindexResult{seq: batch.seq, count: len(batch.docs), lastID: batch.lastID, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+	
+	// Advance the checkpoint over the contiguous prefix of finished batches
+	completed := make(map[int]reindexResult)
+	next := 0
+	var runErr error
+	
+	for result := range results {
+		if result.err != nil {
+			if runErr == nil {
+				runErr = result.err
+				cancel()
+			}
+			continue
+		}
+		if runErr != nil {
+			continue
+		}
+		
+		completed[result.seq] = result
+		for {
+			done, ok := completed[next]
+			if !ok {
+				break
+			}
+			delete(completed, next)
+			next++
+			
+			stats.Indexed += done.count
+			stats.Batches++
+			stats.LastID = done.lastID
+			if err := r.Checkpoint.Save(ctx, done.lastID); err != nil {
+				runErr = err
+				cancel()
+				break
+			}
+		}
+		
+		if stats.Batches > 0 && stats.Batches%100 == 0 {
+			log.Printf("Reindexed %d messages, checkpoint at ID %d", stats.Indexed, stats.LastID)
+		}
+	}
+	
+	select {
+	case err := <-fetchErr:
+		if runErr == nil {
+			runErr = err
+		}
+	default:
+	}
+	
+	stats.Duration = time.Since(started)
+	return stats, runErr
+}
+
+func (r *Reindexer) bulkWithRetry(ctx context.Context, docs []Document) error {
+	backoff := 500 * time.Millisecond
+	
+	for attempt := 0; ; attempt++ {
+		err := r.Target.Bulk(ctx, docs)
+		if err == nil || attempt >= r.MaxRetries || !retryable(err) {
+			return err
+		}
+		
+		log.Printf("Warning: Bulk request failed (attempt %d), retrying in %s: %v", attempt+1, backoff, err)
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// retryable treats throttling, server and network errors as transient
+func retryable(err error) bool {
+	var bulkErr *BulkError
+	if errors.As(err, &bulkErr) {
+		return false
+	}
+	
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code == 429 || statusErr.Code >= 500
+	}
+	return !errors.Is(err, context.Canceled)
+}
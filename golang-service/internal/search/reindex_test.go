@@ -0,0 +1,178 @@
+package search
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// fakeElasticsearch stands in for the index, the _bulk API and index creation
+type fakeElasticsearch struct {
+	mu      sync.Mutex
+	exists  bool
+	mapping map[string]interface{}
+	indexed []int64
+	// reject makes _bulk report this document as failed
+	reject int64
+}
+
+func (f *fakeElasticsearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	
+	switch {
+	case r.Method == http.MethodHead && r.URL.Path == "/messages":
+		if !f.exists {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case r.Method == http.MethodPut && r.URL.Path == "/messages":
+		json.NewDecoder(r.Body).Decode(&f.mapping)
+		f.exists = true
+	case r.Method == http.MethodPost && r.URL.Path == "/_bulk":
+		type item struct {
+			ID     string `json:"_id"`
+			Status int    `json:"status"`
+		}
+		var items []map[string]item
+		failed := false
+	
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			scanner.Scan()
+			var doc Document
+			json.Unmarshal(scanner.Bytes(), &doc)
+	
+			status := http.StatusCreated
+			if doc.ID == f.reject {
+				status, failed = http.StatusBadRequest, true
+			} else {
+				f.indexed = append(f.indexed, doc.ID)
+			}
+			items = append(items, map[string]item{"index": {ID: "x", Status: status}})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": failed, "items": items})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+type memoryCheckpoint struct {
+	mu     sync.Mutex
+	lastID int64
+}
+
+func (c *memoryCheckpoint) Load(context.Context) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastID, nil
+}
+
+func (c *memoryCheckpoint) Save(_ context.Context, lastID int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastID = lastID
+	return nil
+}
+
+func (c *memoryCheckpoint) Clear(context.Context) error {
+	return c.Save(context.Background(), 0)
+}
+
+// fetchRange serves documents 1..n the way MessageRepository.ListAfterID does
+func fetchRange(n int64) func(afterID int64, limit int) ([]Document, error) {
+	return func(afterID int64, limit int) ([]Document, error) {
+		var docs []Document
+		for id := afterID + 1; id <= n && len(docs) < limit; id++ {
+			docs = append(docs, Document{ID: id, ChatID: 1, Number: int(id), Body: "message"})
+		}
+		return docs, nil
+	}
+}
+
+func TestEnsureIndexCreatesMessageMapping(t *testing.T) {
+	fake := &fakeElasticsearch{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	es := NewElasticsearchIndex(server.URL, "messages")
+	
+	created, err := es.EnsureIndex(context.Background())
+	if err != nil || !created {
+		t.Fatalf("EnsureIndex() = %v, %v; want true, nil", created, err)
+	}
+	
+	properties := fake.mapping["mappings"].(map[string]interface{})["properties"].(map[string]interface{})
+	body := properties["body"].(map[string]interface{})
+	if body["type"] != "text" || body["analyzer"] != "english" {
+		t.Errorf("body mapping = %v, want text with the english analyzer", body)
+	}
+	
+	created, err = es.EnsureIndex(context.Background())
+	if err != nil || created {
+		t.Errorf("EnsureIndex() on an existing index = %v, %v; want false, nil", created, err)
+	}
+}
+
+func TestReindexerResumesFromCheckpoint(t *testing.T) {
+	fake := &fakeElasticsearch{exists: true}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	
+	checkpoint := &memoryCheckpoint{lastID: 4}
+	reindexer := &Reindexer{
+		Fetch:       fetchRange(20),
+		Target:      NewElasticsearchIndex(server.URL, "messages"),
+		Checkpoint:  checkpoint,
+		BatchSize:   3,
+		Concurrency: 3,
+	}
+	
+	stats, err := reindexer.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if stats.Indexed != 16 || stats.StartID != 4 || stats.LastID != 20 {
+		t.Errorf("stats = %+v, want 16 indexed from 4 to 20", stats)
+	}
+	if checkpoint.lastID != 20 {
+		t.Errorf("checkpoint = %d, want 20", checkpoint.lastID)
+	}
+	
+	sort.Slice(fake.indexed, func(i, j int) bool { return fake.indexed[i] < fake.indexed[j] })
+	for i, id := range fake.indexed {
+		if id != int64(i+5) {
+			t.Fatalf("indexed IDs = %v, want 5..20", fake.indexed)
+		}
+	}
+}
+
+func TestReindexerStopsCheckpointBeforeRejectedBatch(t *testing.T) {
+	fake := &fakeElasticsearch{exists: true, reject: 8}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	
+	checkpoint := &memoryCheckpoint{}
+	reindexer := &Reindexer{
+		Fetch:       fetchRange(12),
+		Target:      NewElasticsearchIndex(server.URL, "messages"),
+		Checkpoint:  checkpoint,
+		BatchSize:   3,
+		Concurrency: 1,
+		MaxRetries:  3,
+	}
+	
+	_, err := reindexer.Run(context.Background())
+	var bulkErr *BulkError
+	if !errors.As(err, &bulkErr) || bulkErr.Failed != 1 {
+		t.Fatalf("Run() error = %v, want a BulkError for one document", err)
+	}
+	// Batch 7-9 failed, so a rerun must start after 6
+	if checkpoint.lastID != 6 {
+		t.Errorf("checkpoint = %d, want 6", checkpoint.lastID)
+	}
+}
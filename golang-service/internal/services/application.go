@@ -0,0 +1,56 @@
+package services
+
+import (
+	"errors"
+	"log"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+)
+
+// tokenAttempts bounds retries when a generated token is already taken
+const tokenAttempts = 3
+
+type ApplicationService struct {
+	repo       *repository.ApplicationRepository
+	counterSvc *CounterService
+}
+
+func NewApplicationService(repo *repository.ApplicationRepository, counterSvc *CounterService) *ApplicationService {
+	return &ApplicationService{
+		repo:       repo,
+		counterSvc: counterSvc,
+	}
+}
+
+// Create stores a new application under a fresh token and seeds its chat
+// counter, mirroring the Rails after_create callback
+func (s *ApplicationService) Create(name string) (*models.Application, error) {
+	var (
+		app *models.Application
+		err error
+	)
+	
+	for attempt := 0; attempt < tokenAttempts; attempt++ {
+		var token string
+		token, err = GenerateToken()
+		if err != nil {
+			return nil, err
+		}
+		
+		app, err = s.repo.Create(token, name)
+		if !errors.Is(err, repository.ErrTokenTaken) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	
+	if err := s.counterSvc.InitializeChatCounter(app.Token); err != nil {
+		// INCR starts from zero anyway, so a missing key is harmless
+		log.Printf("Warning: Failed to initialize chat counter: %v", err)
+	}
+	
+	return app, nil
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -54,3 +55,170 @@ func (s *CounterService) InitializeMessageCounter(chatID int64) error {
 	
 	return nil
 }
+
+// GetMessageCounters returns the current message counters for several chats.
+// Chats without a Redis counter are omitted so callers can fall back to MySQL.
+func (s *CounterService) GetMessageCounters(chatIDs []int64) (map[int64]int64, error) {
+	counters := make(map[int64]int64, len(chatIDs))
+	if len(chatIDs) == 0 {
+		return counters, nil
+	}
+	
+	keys := make([]string, len(chatIDs))
+	for i, chatID := range chatIDs {
+		keys[i] = fmt.Sprintf("chat:%d:message_counter", chatID)
+	}
+	
+	values, err := s.redis.MGet(s.ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message counters: %w", err)
+	}
+	
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if number, err := strconv.ParseInt(str, 10, 64); err == nil {
+			counters[chatIDs[i]] = number
+		}
+	}
+	
+	return counters, nil
+}
+
+// GetChatCounters returns the current chat counters for several applications.
+// Applications without a Redis counter are omitted.
+func (s *CounterService) GetChatCounters(appTokens []string) (map[string]int64, error) {
+	counters := make(map[string]int64, len(appTokens))
+	if len(appTokens) == 0 {
+		return counters, nil
+	}
+	
+	keys := make([]string, len(appTokens))
+	for i, token := range appTokens {
+		keys[i] = fmt.Sprintf("app:%s:chat_counter", token)
+	}
+	
+	values, err := s.redis.MGet(s.ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chat counters: %w", err)
+	}
+	
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		counter, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			continue
+		}
+		counters[appTokens[i]] = counter
+	}
+	
+	return counters, nil
+}
+
+// InitializeChatCounter sets initial value for an application's chat counter
+func (s *CounterService) InitializeChatCounter(appToken string) error {
+	key := fmt.Sprintf("app:%s:chat_counter", appToken)
+	
+	err := s.redis.SetNX(s.ctx, key, 0, 0).Err()
+	if err != nil {
+		return fmt.Errorf("failed to initialize chat counter: %w", err)
+	}
+	
+	return nil
+}
+
+// GetChatCounter returns an application's chat counter and whether it exists
+func (s *CounterService) GetChatCounter(appToken string) (int64, bool, error) {
+	return s.getCounter(fmt.Sprintf("app:%s:chat_counter", appToken))
+}
+
+// GetMessageCounter returns a chat's message counter and whether it exists
+func (s *CounterService) GetMessageCounter(chatID int64) (int64, bool, error) {
+	return s.getCounter(fmt.Sprintf("chat:%d:message_counter", chatID))
+}
+
+// SetChatCounter overwrites an application's chat counter
+func (s *CounterService) SetChatCounter(appToken string, value int64) error {
+	key := fmt.Sprintf("app:%s:chat_counter", appToken)
+	
+	if err := s.redis.Set(s.ctx, key, value, 0).Err(); err != nil {
+		return fmt.Errorf("failed to set chat counter: %w", err)
+	}
+	
+	return nil
+}
+
+// SetMessageCounter overwrites a chat's message counter
+func (s *CounterService) SetMessageCounter(chatID int64, value int64) error {
+	key := fmt.Sprintf("chat:%d:message_counter", chatID)
+	
+	if err := s.redis.Set(s.ctx, key, value, 0).Err(); err != nil {
+		return fmt.Errorf("failed to set message counter: %w", err)
+	}
+	
+	return nil
+}
+
+// DeleteMessageCounter drops a chat's message counter
+func (s *CounterService) DeleteMessageCounter(chatID int64) error {
+	key := fmt.Sprintf("chat:%d:message_counter", chatID)
+	
+	if err := s.redis.Del(s.ctx, key).Err(); err != nil {
+		return fmt.Errorf("failed to delete message counter: %w", err)
+	}
+	
+	return nil
+}
+
+func (s *CounterService) getCounter(key string) (int64, bool, error) {
+	value, err := s.redis.Get(s.ctx, key).Int64()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read counter %s: %w", key, err)
+	}
+	
+	return value, true, nil
+}
+
+// raiseCounterScript sets KEYS[1] to ARGV[1] only if it is lower, returning
+// the previous value. Running it in Redis keeps concurrent INCRs safe.
+var raiseCounterScript = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '-1')
+if current < tonumber(ARGV[1]) then
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+return current
+`)
+
+// RaiseChatCounter makes sure the next chat number is above floor. It
+// returns the previous counter, or -1 if the key did not exist.
+func (s *CounterService) RaiseChatCounter(appToken string, floor int64) (int64, error) {
+	key := fmt.Sprintf("app:%s:chat_counter", appToken)
+	
+	previous, err := raiseCounterScript.Run(s.ctx, s.redis, []string{key}, floor).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to raise chat counter: %w", err)
+	}
+	
+	return previous, nil
+}
+
+// RaiseMessageCounter makes sure the next message number is above floor. It
+// returns the previous counter, or -1 if the key did not exist.
+func (s *CounterService) RaiseMessageCounter(chatID int64, floor int64) (int64, error) {
+	key := fmt.Sprintf("chat:%d:message_counter", chatID)
+	
+	previous, err := raiseCounterScript.Run(s.ctx, s.redis, []string{key}, floor).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to raise message counter: %w", err)
+	}
+	
+	return previous, nil
+}
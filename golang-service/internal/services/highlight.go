@@ -0,0 +1,120 @@
+package services
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Highlight tags match the Elasticsearch highlighter config in Message.search_messages
+const (
+	HighlightPreTag  = "<mark>"
+	HighlightPostTag = "</mark>"
+	
+	// Runes of context kept on each side of the first match
+	snippetRadius = 80
+)
+
+var wordRegex = regexp.MustCompile(`[\p{L}\p{N}_']+`)
+
+// SearchTerm is one query word; prefix terms came from a trailing '*'
+type SearchTerm struct {
+	text   string
+	prefix bool
+}
+
+// SearchTerms extracts highlightable words from a natural-language or
+// boolean-mode query, dropping operators and excluded (-word) terms
+func SearchTerms(query string) []SearchTerm {
+	var terms []SearchTerm
+	
+	for _, field := range strings.Fields(query) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		prefix := strings.HasSuffix(field, "*")
+		
+		for _, word := range wordRegex.FindAllString(field, -1) {
+			terms = append(terms, SearchTerm{text: strings.ToLower(word), prefix: prefix})
+		}
+	}
+	
+	return terms
+}
+
+// Snippet returns an HTML-escaped excerpt of body around the first matching
+// term, with every match wrapped in highlight tags
+func Snippet(body string, terms []SearchTerm) string {
+	return SnippetFunc(body, func(word string) bool {
+		return matchesTerm(word, terms)
+	})
+}
+
+// SnippetFunc is Snippet with a custom matcher, which receives each word lowercased
+func SnippetFunc(body string, match func(word string) bool) string {
+	runes := []rune(body)
+	
+	// Word boundaries are computed on the rune slice so the window never splits a character
+	type span struct{ start, end int }
+	var matches []span
+	
+	start := -1
+	for i := 0; i <= len(runes); i++ {
+		inWord := i < len(runes) && isWordRune(runes[i])
+		if inWord && start < 0 {
+			start = i
+		}
+		if !inWord && start >= 0 {
+			if match(strings.ToLower(string(runes[start:i]))) {
+				matches = append(matches, span{start, i})
+			}
+			start = -1
+		}
+	}
+	
+	from, to := 0, len(runes)
+	if len(matches) > 0 && len(runes) > 2*snippetRadius {
+		from = max(0, matches[0].start-snippetRadius)
+		to = min(len(runes), matches[0].end+snippetRadius)
+	} else if len(runes) > 2*snippetRadius {
+		to = 2 * snippetRadius
+	}
+	
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	
+	pos := from
+	for _, m := range matches {
+		if m.start < from || m.end > to {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:m.start])))
+		b.WriteString(HighlightPreTag)
+		b.WriteString(html.EscapeString(string(runes[m.start:m.end])))
+		b.WriteString(HighlightPostTag)
+		pos = m.end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:to])))
+	
+	if to < len(runes) {
+		b.WriteString("…")
+	}
+	
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_' || r == '\''
+}
+
+func matchesTerm(word string, terms []SearchTerm) bool {
+	for _, term := range terms {
+		if word == term.text || (term.prefix && strings.HasPrefix(word, term.text)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// Hub fans chat events out to local subscribers. Events travel through
+// Redis Pub/Sub so every replica sees messages created on any other one.
+type Hub struct {
+	redis  *redis.Client
+	pubsub *redis.PubSub
+	ctx    context.Context
+	
+	mu   sync.RWMutex
+	subs map[int64]map[chan<- models.ChatEvent]struct{}
+}
+
+func NewHub(redisClient *redis.Client) *Hub {
+	ctx := context.Background()
+	return &Hub{
+		redis:  redisClient,
+		pubsub: redisClient.Subscribe(ctx),
+		ctx:    ctx,
+		subs:   make(map[int64]map[chan<- models.ChatEvent]struct{}),
+	}
+}
+
+func chatEventsChannel(chatID int64) string {
+	return fmt.Sprintf("chat:%d:events", chatID)
+}
+
+// Publish broadcasts an event to every replica subscribed to the chat
+func (h *Hub) Publish(chatID int64, event models.ChatEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	
+	if err := h.redis.Publish(h.ctx, chatEventsChannel(chatID), payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	
+	return nil
+}
+
+// Subscribe registers ch for events of a chat. Delivery never blocks:
+// events are dropped for subscribers that fall behind.
+func (h *Hub) Subscribe(chatID int64, ch chan<- models.ChatEvent) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	
+	if _, ok := h.subs[chatID]; !ok {
+		if err := h.pubsub.Subscribe(h.ctx, chatEventsChannel(chatID)); err != nil {
+			return fmt.Errorf("failed to subscribe to chat events: %w", err)
+		}
+		h.subs[chatID] = make(map[chan<- models.ChatEvent]struct{})
+	}
+	h.subs[chatID][ch] = struct{}{}
+	
+	return nil
+}
+
+// Unsubscribe removes ch, dropping the Redis subscription with the last one
+func (h *Hub) Unsubscribe(chatID int64, ch chan<- models.ChatEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	
+	subs, ok := h.subs[chatID]
+	if !ok {
+		return
+	}
+	delete(subs, ch)
+	
+	if len(subs) == 0 {
+		delete(h.subs, chatID)
+		if err := h.pubsub.Unsubscribe(h.ctx, chatEventsChannel(chatID)); err != nil {
+			log.Printf("Warning: Failed to unsubscribe from chat %d events: %v", chatID, err)
+		}
+	}
+}
+
+// Run dispatches Redis messages to local subscribers until ctx is cancelled
+func (h *Hub) Run(ctx context.Context) {
+	messages := h.pubsub.Channel()
+	
+	for {
+		select {
+		case <-ctx.Done():
+			h.pubsub.Close()
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			h.dispatch(msg)
+		}
+	}
+}
+
+func (h *Hub) dispatch(msg *redis.Message) {
+	chatID, ok := parseChatEventsChannel(msg.Channel)
+	if !ok {
+		return
+	}
+	
+	var event models.ChatEvent
+	if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+		log.Printf("Warning: Dropping malformed event on %s: %v", msg.Channel, err)
+		return
+	}
+	
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	
+	for ch := range h.subs[chatID] {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("Warning: Subscriber for chat %d is falling behind, dropping event", chatID)
+		}
+	}
+}
+
+func parseChatEventsChannel(channel string) (int64, bool) {
+	parts := strings.Split(channel, ":")
+	if len(parts) != 3 || parts[0] != "chat" || parts[2] != "events" {
+		return 0, false
+	}
+	chatID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return chatID, true
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Scripts only touch the lock while it still holds our token, so a holder
+// whose lease expired can never release or extend someone else's lock
+var (
+	releaseLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+	
+	refreshLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+)
+
+// Lock is a lease-based mutual exclusion lock shared by every replica
+type Lock struct {
+	redis *redis.Client
+	key   string
+	ttl   time.Duration
+	token string
+}
+
+func NewLock(redisClient *redis.Client, key string, ttl time.Duration) *Lock {
+	return &Lock{redis: redisClient, key: key, ttl: ttl}
+}
+
+// TryAcquire takes the lock if nobody holds it
+func (l *Lock) TryAcquire(ctx context.Context) (bool, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return false, fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+	
+	ok, err := l.redis.SetNX(ctx, l.key, token, l.ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock %s: %w", l.key, err)
+	}
+	if ok {
+		l.token = token
+	}
+	return ok, nil
+}
+
+// Refresh extends the lease, reporting false if the lock was lost
+func (l *Lock) Refresh(ctx context.Context) (bool, error) {
+	n, err := refreshLockScript.Run(ctx, l.redis, []string{l.key}, l.token, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to refresh lock %s: %w", l.key, err)
+	}
+	return n == 1, nil
+}
+
+// Release gives the lock up if we still hold it
+func (l *Lock) Release(ctx context.Context) error {
+	if err := releaseLockScript.Run(ctx, l.redis, []string{l.key}, l.token).Err(); err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", l.key, err)
+	}
+	l.token = ""
+	return nil
+}
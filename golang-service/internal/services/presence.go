@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// A participant is online while heartbeats arrive within this window
+	PresenceTTL = 30 * time.Second
+	
+	// Typing indicators expire quickly once keystrokes stop
+	TypingTTL = 6 * time.Second
+	
+	// presenceChatsKey indexes chats with live presence state for the sweeper
+	presenceChatsKey = "presence:chats"
+	
+	// Connection counts outlive a missed heartbeat, but not a replica that
+	// died without decrementing them
+	connectionTTL = 2 * PresenceTTL
+)
+
+// PresenceService tracks ephemeral online/typing state in expiring Redis
+// keys. Nothing here is persisted to MySQL.
+type PresenceService struct {
+	redis *redis.Client
+	hub   *Hub
+	ctx   context.Context
+}
+
+func NewPresenceService(redisClient *redis.Client, hub *Hub) *PresenceService {
+	return &PresenceService{
+		redis: redisClient,
+		hub:   hub,
+		ctx:   context.Background(),
+	}
+}
+
+// ChatRef identifies a chat both internally and as clients see it
+type ChatRef struct {
+	ID               int64
+	ApplicationToken string
+	Number           int
+}
+
+// presenceKind is either "presence" or "typing"
+type presenceKind struct {
+	name    string
+	ttl     time.Duration
+	started string
+	stopped string
+}
+
+var (
+	onlineKind = presenceKind{"presence", PresenceTTL, models.EventPresenceOnline, models.EventPresenceOffline}
+	typingKind = presenceKind{"typing", TypingTTL, models.EventTypingStarted, models.EventTypingStopped}
+)
+
+func (k presenceKind) memberKey(chatID int64, userID string) string {
+	return fmt.Sprintf("chat:%d:%s:%s", chatID, k.name, userID)
+}
+
+func (k presenceKind) indexKey(chatID int64) string {
+	return fmt.Sprintf("chat:%d:%s", chatID, k.name)
+}
+
+// connectionsKey counts a user's open connections to a chat across replicas
+func connectionsKey(chatID int64, userID string) string {
+	return fmt.Sprintf("chat:%d:connections:%s", chatID, userID)
+}
+
+// Heartbeat marks a user online, broadcasting only when they were offline
+func (s *PresenceService) Heartbeat(chat ChatRef, userID string) error {
+	return s.touch(chat, userID, onlineKind)
+}
+
+// Typing marks a user as typing; typing also counts as a presence heartbeat
+func (s *PresenceService) Typing(chat ChatRef, userID string) error {
+	if err := s.touch(chat, userID, onlineKind); err != nil {
+		return err
+	}
+	return s.touch(chat, userID, typingKind)
+}
+
+// StopTyping clears a user's typing indicator
+func (s *PresenceService) StopTyping(chat ChatRef, userID string) error {
+	return s.clear(chat, userID, typingKind)
+}
+
+// Connect counts a connection on which a user is present. Each Connect is
+// paired with a Disconnect, so a user with several tabs stays online until
+// the last one closes.
+func (s *PresenceService) Connect(chat ChatRef, userID string) error {
+	pipe := s.redis.TxPipeline()
+	pipe.Incr(s.ctx, connectionsKey(chat.ID, userID))
+	pipe.Expire(s.ctx, connectionsKey(chat.ID, userID), connectionTTL)
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return fmt.Errorf("failed to count connection: %w", err)
+	}
+	return nil
+}
+
+// Disconnect ends a connection counted by Connect and marks the user
+// offline once no other connection is left
+func (s *PresenceService) Disconnect(chat ChatRef, userID string) error {
+	remaining, err := s.redis.Decr(s.ctx, connectionsKey(chat.ID, userID)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to count connection: %w", err)
+	}
+	if remaining > 0 {
+		return nil
+	}
+	
+	s.redis.Del(s.ctx, connectionsKey(chat.ID, userID))
+	return s.Leave(chat, userID)
+}
+
+// Leave marks a user offline immediately instead of waiting for expiry
+func (s *PresenceService) Leave(chat ChatRef, userID string) error {
+	if err := s.clear(chat, userID, typingKind); err != nil {
+		return err
+	}
+	return s.clear(chat, userID, onlineKind)
+}
+
+// Snapshot lists users currently online and typing in a chat
+func (s *PresenceService) Snapshot(chatID int64) (*models.PresenceResponse, error) {
+	online, err := s.live(chatID, onlineKind)
+	if err != nil {
+		return nil, err
+	}
+	
+	typing, err := s.live(chatID, typingKind)
+	if err != nil {
+		return nil, err
+	}
+	
+	return &models.PresenceResponse{Online: online, Typing: typing}, nil
+}
+
+func (s *PresenceService) touch(chat ChatRef, userID string, kind presenceKind) error {
+	pipe := s.redis.TxPipeline()
+	created := pipe.SetNX(s.ctx, kind.memberKey(chat.ID, userID), 1, kind.ttl)
+	pipe.Expire(s.ctx, kind.memberKey(chat.ID, userID), kind.ttl)
+	pipe.SAdd(s.ctx, kind.indexKey(chat.ID), userID)
+	pipe.SAdd(s.ctx, presenceChatsKey, chatRefMember(chat))
+	if kind == onlineKind {
+		pipe.Expire(s.ctx, connectionsKey(chat.ID, userID), connectionTTL)
+	}
+	
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return fmt.Errorf("failed to record %s: %w", kind.name, err)
+	}
+	
+	if created.Val() {
+		s.broadcast(chat, userID, kind.started)
+	}
+	return nil
+}
+
+func (s *PresenceService) clear(chat ChatRef, userID string, kind presenceKind) error {
+	removed, err := s.redis.Del(s.ctx, kind.memberKey(chat.ID, userID)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to clear %s: %w", kind.name, err)
+	}
+	s.redis.SRem(s.ctx, kind.indexKey(chat.ID), userID)
+	
+	if removed > 0 {
+		s.broadcast(chat, userID, kind.stopped)
+	}
+	return nil
+}
+
+// live returns index members whose expiring key still exists
+func (s *PresenceService) live(chatID int64, kind presenceKind) ([]string, error) {
+	members, err := s.redis.SMembers(s.ctx, kind.indexKey(chatID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", kind.name, err)
+	}
+	
+	pipe := s.redis.Pipeline()
+	checks := make([]*redis.IntCmd, len(members))
+	for i, userID := range members {
+		checks[i] = pipe.Exists(s.ctx, kind.memberKey(chatID, userID))
+	}
+	if len(members) > 0 {
+		if _, err := pipe.Exec(s.ctx); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", kind.name, err)
+		}
+	}
+	
+	users := []string{}
+	for i, userID := range members {
+		if checks[i].Val() > 0 {
+			users = append(users, userID)
+		}
+	}
+	sort.Strings(users)
+	
+	return users, nil
+}
+
+// sweep broadcasts the end of presence/typing whose keys expired silently.
+// SREM succeeds on exactly one replica, so each change is announced once.
+func (s *PresenceService) sweep() error {
+	chats, err := s.redis.SMembers(s.ctx, presenceChatsKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to read presence chats: %w", err)
+	}
+	
+	for _, member := range chats {
+		chat, ok := parseChatRefMember(member)
+		if !ok {
+			s.redis.SRem(s.ctx, presenceChatsKey, member)
+			continue
+		}
+		
+		remaining := 0
+		for _, kind := range []presenceKind{typingKind, onlineKind} {
+			users, err := s.redis.SMembers(s.ctx, kind.indexKey(chat.ID)).Result()
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", kind.name, err)
+			}
+			
+			for _, userID := range users {
+				exists, err := s.redis.Exists(s.ctx, kind.memberKey(chat.ID, userID)).Result()
+				if err != nil {
+					return fmt.Errorf("failed to check %s: %w", kind.name, err)
+				}
+				if exists > 0 {
+					remaining++
+					continue
+				}
+				if removed, _ := s.redis.SRem(s.ctx, kind.indexKey(chat.ID), userID).Result(); removed > 0 {
+					s.broadcast(chat, userID, kind.stopped)
+				}
+			}
+		}
+		
+		if remaining == 0 {
+			s.redis.SRem(s.ctx, presenceChatsKey, member)
+		}
+	}
+	
+	return nil
+}
+
+// RunSweeper detects expired presence every interval until ctx is cancelled
+func (s *PresenceService) RunSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.sweep(); err != nil {
+				log.Printf("Error sweeping presence: %v", err)
+			}
+		}
+	}
+}
+
+func (s *PresenceService) broadcast(chat ChatRef, userID, eventType string) {
+	event := models.ChatEvent{
+		Type:             eventType,
+		ApplicationToken: chat.ApplicationToken,
+		ChatNumber:       chat.Number,
+		UserID:           userID,
+	}
+	if err := s.hub.Publish(chat.ID, event); err != nil {
+		log.Printf("Warning: Failed to publish %s event: %v", eventType, err)
+	}
+}
+
+func chatRefMember(chat ChatRef) string {
+	return fmt.Sprintf("%d:%d:%s", chat.ID, chat.Number, chat.ApplicationToken)
+}
+
+func parseChatRefMember(member string) (ChatRef, bool) {
+	parts := strings.SplitN(member, ":", 3)
+	if len(parts) != 3 {
+		return ChatRef{}, false
+	}
+	chatID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return ChatRef{}, false
+	}
+	number, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return ChatRef{}, false
+	}
+	return ChatRef{ID: chatID, Number: number, ApplicationToken: parts[2]}, true
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+// Quota names, as reported to clients
+const (
+	QuotaMaxChats           = "max_chats"
+	QuotaMaxMessagesPerChat = "max_messages_per_chat"
+	QuotaMessagesPerDay     = "messages_per_day"
+	QuotaMaxBodyBytes       = "max_body_bytes"
+)
+
+// QuotaError reports which quota rejected a request
+type QuotaError struct {
+	Quota string
+	Limit int64
+	Plan  string
+	// RetryAfter is set for quotas that reset, such as the daily one
+	RetryAfter time.Duration
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s quota of %d exceeded for plan %q", e.Quota, e.Limit, e.Plan)
+}
+
+// QuotaService enforces per-application plan limits. Chat and message
+// numbers are only allocated once the request is within quota, so rejected
+// requests leave no gaps in the numbering.
+type QuotaService struct {
+	redis       *redis.Client
+	ctx         context.Context
+	quotaRepo   *repository.QuotaRepository
+	chatRepo    *repository.ChatRepository
+	messageRepo *repository.MessageRepository
+	counterSvc  *CounterService
+	store       *config.Store
+}
+
+func NewQuotaService(
+	redisClient *redis.Client,
+	quotaRepo *repository.QuotaRepository,
+	chatRepo *repository.ChatRepository,
+	messageRepo *repository.MessageRepository,
+	counterSvc *CounterService,
+	store *config.Store,
+) *QuotaService {
+	return &QuotaService{
+		redis:       redisClient,
+		ctx:         context.Background(),
+		quotaRepo:   quotaRepo,
+		chatRepo:    chatRepo,
+		messageRepo: messageRepo,
+		counterSvc:  counterSvc,
+		store:       store,
+	}
+}
+
+// Limits returns the plan an application is on and its effective limits:
+// the plan's, with any per-application overrides applied
+func (s *QuotaService) Limits(appID int64) (string, config.PlanLimits, error) {
+	quotas := s.store.Current().Quotas
+	
+	assignment, err := s.quotaRepo.Get(appID)
+	if errors.Is(err, repository.ErrQuotaNotFound) {
+		return quotas.DefaultPlan, quotas.Plans[quotas.DefaultPlan], nil
+	}
+	if err != nil {
+		return "", config.PlanLimits{}, err
+	}
+	
+	name := assignment.Plan
+	limits, ok := quotas.Plans[name]
+	if !ok {
+		log.Printf("Warning: Application %d is on unknown plan %q, using %q", appID, name, quotas.DefaultPlan)
+		name = quotas.DefaultPlan
+		limits = quotas.Plans[name]
+	}
+	
+	if assignment.MaxChats != nil {
+		limits.MaxChats = *assignment.MaxChats
+	}
+	if assignment.MaxMessagesPerChat != nil {
+		limits.MaxMessagesPerChat = *assignment.MaxMessagesPerChat
+	}
+	if assignment.MessagesPerDay != nil {
+		limits.MessagesPerDay = *assignment.MessagesPerDay
+	}
+	if assignment.MaxBodyBytes != nil {
+		limits.MaxBodyBytes = *assignment.MaxBodyBytes
+	}
+	
+	return name, limits, nil
+}
+
+// liveCountTTL bounds how long a live count is trusted before it is seeded
+// again from MySQL, which corrects any drift from requests that died
+// between reserving and storing
+const liveCountTTL = 10 * time.Minute
+
+// liveIncrScript takes one slot in the live count KEYS[1] unless that would
+// pass ARGV[1], returning -1 instead. It returns -2 if the count has not
+// been seeded.
+var liveIncrScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if not current then
+	return -2
+end
+if tonumber(current) >= tonumber(ARGV[1]) then
+	return -1
+end
+return redis.call('INCR', KEYS[1])
+`)
+
+// reserveLive takes one slot in a live count capped at max, seeding it from
+// count when Redis has none. It reports false once max slots are taken.
+func (s *QuotaService) reserveLive(key string, max int64, count func() (int, error)) (bool, error) {
+	for {
+		n, err := liveIncrScript.Run(s.ctx, s.redis, []string{key}, max).Int64()
+		if err != nil {
+			return false, fmt.Errorf("failed to reserve quota: %w", err)
+		}
+		if n != -2 {
+			return n >= 0, nil
+		}
+		
+		rows, err := count()
+		if err != nil {
+			return false, err
+		}
+		// Whoever seeds first wins; the loser retries against that count
+		if err := s.redis.SetNX(s.ctx, key, rows, liveCountTTL).Err(); err != nil {
+			return false, fmt.Errorf("failed to seed quota: %w", err)
+		}
+	}
+}
+
+// releaseScript gives back one use from KEYS[1]. A key that has already
+// expired is left alone rather than recreated below zero.
+var releaseScript = redis.NewScript(`
+local used = tonumber(redis.call('GET', KEYS[1]) or '0')
+if used <= 0 then
+	return 0
+end
+return redis.call('DECR', KEYS[1])
+`)
+
+func (s *QuotaService) release(key string) {
+	if err := releaseScript.Run(s.ctx, s.redis, []string{key}).Err(); err != nil {
+		log.Printf("Warning: Failed to release quota %s: %v", key, err)
+	}
+}
+
+// NextChatNumber allocates a chat number within the max_chats quota, which
+// counts the chats the application has now. Call ReleaseChat if the chat is
+// not stored after all.
+func (s *QuotaService) NextChatNumber(app *models.Application) (int64, error) {
+	plan, limits, err := s.Limits(app.ID)
+	if err != nil {
+		return 0, err
+	}
+	
+	if limits.MaxChats > 0 {
+		ok, err := s.reserveLive(liveChatsKey(app.Token), limits.MaxChats, func() (int, error) {
+			return s.chatRepo.CountByApplication(app.ID)
+		})
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return 0, &QuotaError{Quota: QuotaMaxChats, Limit: limits.MaxChats, Plan: plan}
+		}
+	}
+	
+	number, err := s.counterSvc.GetNextChatNumber(app.Token)
+	if err != nil {
+		s.ReleaseChat(app)
+		return 0, err
+	}
+	
+	return number, nil
+}
+
+// ReleaseChat frees the max_chats slot of a chat that was not stored or has
+// been purged
+func (s *QuotaService) ReleaseChat(app *models.Application) {
+	s.release(liveChatsKey(app.Token))
+}
+
+// ChatPurged frees the slot of a purged chat and forgets its message count
+func (s *QuotaService) ChatPurged(app *models.Application, chat *models.Chat) {
+	s.ReleaseChat(app)
+	if err := s.redis.Del(s.ctx, liveMessagesKey(chat.ID)).Err(); err != nil {
+		log.Printf("Warning: Failed to clear message quota for chat %d: %v", chat.ID, err)
+	}
+}
+
+// MessageDeleted frees the max_messages_per_chat slot of a deleted message
+func (s *QuotaService) MessageDeleted(chat *models.Chat) {
+	s.release(liveMessagesKey(chat.ID))
+}
+
+// dailyIncrScript counts one use in KEYS[1] unless ARGV[1] (zero for no
+// limit) is already reached, returning -1 instead. The key expires after
+// ARGV[2] seconds, well after its day is over.
+var dailyIncrScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local used = tonumber(redis.call('GET', KEYS[1]) or '0')
+if limit > 0 and used >= limit then
+	return -1
+end
+used = redis.call('INCR', KEYS[1])
+if used == 1 then
+	redis.call('EXPIRE', KEYS[1], ARGV[2])
+end
+return used
+`)
+
+// CheckMessageBody checks body against the application's size quota, for
+// edits of messages that already have a number
+func (s *QuotaService) CheckMessageBody(app *models.Application, body string) error {
+	plan, limits, err := s.Limits(app.ID)
+	if err != nil {
+		return err
+	}
+	return checkBodySize(plan, limits, body)
+}
+
+func checkBodySize(plan string, limits config.PlanLimits, body string) error {
+	if limits.MaxBodyBytes > 0 && len(body) > limits.MaxBodyBytes {
+		return &QuotaError{Quota: QuotaMaxBodyBytes, Limit: int64(limits.MaxBodyBytes), Plan: plan}
+	}
+	return nil
+}
+
+// MessageReservation is a message number allocated by ReserveMessage,
+// along with the quota slots taken for it
+type MessageReservation struct {
+	Number   int64
+	dailyKey string
+	// liveKey is empty when the chat has no message limit
+	liveKey string
+}
+
+// ReserveMessage checks body against the size quota, counts the message
+// against today's allowance and allocates its number within the per-chat
+// quota. Call ReleaseMessage if the message is not stored after all.
+func (s *QuotaService) ReserveMessage(app *models.Application, chat *models.Chat, body string) (*MessageReservation, error) {
+	plan, limits, err := s.Limits(app.ID)
+	if err != nil {
+		return nil, err
+	}
+	
+	if err := checkBodySize(plan, limits, body); err != nil {
+		return nil, err
+	}
+	
+	now := time.Now().UTC()
+	reservation := &MessageReservation{dailyKey: dailyMessagesKey(app.Token, now)}
+	used, err := dailyIncrScript.Run(s.ctx, s.redis, []string{reservation.dailyKey}, limits.MessagesPerDay, int64((48 * time.Hour).Seconds())).Int64()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count daily messages: %w", err)
+	}
+	if used < 0 {
+		midnight := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+		return nil, &QuotaError{Quota: QuotaMessagesPerDay, Limit: limits.MessagesPerDay, Plan: plan, RetryAfter: midnight.Sub(now)}
+	}
+	
+	if limits.MaxMessagesPerChat > 0 {
+		ok, err := s.reserveLive(liveMessagesKey(chat.ID), limits.MaxMessagesPerChat, func() (int, error) {
+			return s.messageRepo.CountInChat(chat.ID)
+		})
+		if err != nil || !ok {
+			s.ReleaseMessage(reservation)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, &QuotaError{Quota: QuotaMaxMessagesPerChat, Limit: limits.MaxMessagesPerChat, Plan: plan}
+		}
+		reservation.liveKey = liveMessagesKey(chat.ID)
+	}
+	
+	number, err := s.counterSvc.GetNextMessageNumber(chat.ID)
+	if err != nil {
+		s.ReleaseMessage(reservation)
+		return nil, err
+	}
+	
+	reservation.Number = number
+	return reservation, nil
+}
+
+// ReleaseMessage gives back the quota taken by ReserveMessage. The daily
+// allowance goes back to the day it was taken even if midnight has passed.
+func (s *QuotaService) ReleaseMessage(reservation *MessageReservation) {
+	s.release(reservation.dailyKey)
+	if reservation.liveKey != "" {
+		s.release(reservation.liveKey)
+	}
+}
+
+// MessagesToday returns how many messages an application sent today (UTC)
+func (s *QuotaService) MessagesToday(appToken string) (int64, error) {
+	used, err := s.redis.Get(s.ctx, dailyMessagesKey(appToken, time.Now().UTC())).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read daily messages: %w", err)
+	}
+	return used, nil
+}
+
+// dailyMessagesKey rolls over at midnight UTC
+func dailyMessagesKey(appToken string, day time.Time) string {
+	return fmt.Sprintf("app:%s:messages:%s", appToken, day.Format("20060102"))
+}
+
+func liveChatsKey(appToken string) string {
+	return fmt.Sprintf("app:%s:live_chats", appToken)
+}
+
+func liveMessagesKey(chatID int64) string {
+	return fmt.Sprintf("chat:%d:live_messages", chatID)
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+// reactionsDirtyKey tracks messages whose cached counts changed since the last flush
+const reactionsDirtyKey = "reactions:dirty"
+
+// reactionFlushBatch bounds how many messages are flushed per tick
+const reactionFlushBatch = 500
+
+type ReactionService struct {
+	redis *redis.Client
+	repo  *repository.ReactionRepository
+	ctx   context.Context
+}
+
+func NewReactionService(redisClient *redis.Client, repo *repository.ReactionRepository) *ReactionService {
+	return &ReactionService{
+		redis: redisClient,
+		repo:  repo,
+		ctx:   context.Background(),
+	}
+}
+
+func reactionsKey(chatID, messageID int64) string {
+	return fmt.Sprintf("chat:%d:message:%d:reactions", chatID, messageID)
+}
+
+// reactionsVersionKey counts writes to a message's reactions, so a seed
+// read from MySQL can tell whether a write raced with it
+func reactionsVersionKey(chatID, messageID int64) string {
+	return reactionsKey(chatID, messageID) + ":version"
+}
+
+// adjustScript applies a committed change in one step. Only an existing
+// hash is incremented; a missing one is seeded from MySQL on the next read.
+// KEYS: hash, version, dirty set. ARGV: emoji, delta, dirty member.
+var adjustScript = redis.NewScript(`
+redis.call('INCR', KEYS[2])
+redis.call('SADD', KEYS[3], ARGV[3])
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	local count = redis.call('HINCRBY', KEYS[1], ARGV[1], ARGV[2])
+	if count <= 0 then
+		redis.call('HDEL', KEYS[1], ARGV[1])
+	end
+end
+return 1
+`)
+
+// seedScript fills a missing hash with counts read from MySQL, unless a
+// write bumped the version after the read began and may be missing from it.
+// KEYS: hash, version. ARGV: version before the read, then emoji, count pairs.
+var seedScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+if (redis.call('GET', KEYS[2]) or '0') ~= ARGV[1] then
+	return 0
+end
+for i = 2, #ARGV, 2 do
+	redis.call('HSET', KEYS[1], ARGV[i], ARGV[i + 1])
+end
+return 1
+`)
+
+// Add records a user's reaction and bumps the cached count. Once the row is
+// saved the reaction counts as added, even if Redis cannot be updated.
+func (s *ReactionService) Add(chatID, messageID int64, userID, emoji string) error {
+	if _, err := s.repo.Create(messageID, userID, emoji); err != nil {
+		return err
+	}
+	s.adjustOrInvalidate(chatID, messageID, emoji, 1)
+	return nil
+}
+
+// Remove deletes a user's reaction and decrements the cached count
+func (s *ReactionService) Remove(chatID, messageID int64, userID, emoji string) error {
+	if err := s.repo.Delete(messageID, userID, emoji); err != nil {
+		return err
+	}
+	s.adjustOrInvalidate(chatID, messageID, emoji, -1)
+	return nil
+}
+
+// adjustOrInvalidate applies a committed change to the cache. When that
+// fails the cached hash is dropped so the next read rebuilds it from MySQL.
+func (s *ReactionService) adjustOrInvalidate(chatID, messageID int64, emoji string, delta int64) {
+	err := s.adjust(chatID, messageID, emoji, delta)
+	if err == nil {
+		return
+	}
+	
+	log.Printf("Warning: Failed to update cached reaction counts for message %d: %v", messageID, err)
+	pipe := s.redis.TxPipeline()
+	pipe.Del(s.ctx, reactionsKey(chatID, messageID))
+	pipe.Incr(s.ctx, reactionsVersionKey(chatID, messageID))
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		log.Printf("Warning: Failed to drop cached reaction counts for message %d: %v", messageID, err)
+	}
+}
+
+// Counts returns aggregated per-emoji counts for a message
+func (s *ReactionService) Counts(chatID, messageID int64) (map[string]int, error) {
+	values, err := s.redis.HGetAll(s.ctx, reactionsKey(chatID, messageID)).Result()
+	if err != nil {
+		log.Printf("Warning: Failed to read reaction counts from Redis: %v", err)
+		return s.repo.CountByMessage(messageID)
+	}
+	
+	if len(values) == 0 {
+		return s.seed(chatID, messageID)
+	}
+	
+	counts := make(map[string]int, len(values))
+	for emoji, value := range values {
+		count, err := strconv.Atoi(value)
+		if err != nil || count <= 0 {
+			continue
+		}
+		counts[emoji] = count
+	}
+	
+	return counts, nil
+}
+
+// adjust applies a committed delta to the cached hash. Emojis that fall to
+// zero are dropped so they vanish from responses.
+func (s *ReactionService) adjust(chatID, messageID int64, emoji string, delta int64) error {
+	keys := []string{reactionsKey(chatID, messageID), reactionsVersionKey(chatID, messageID), reactionsDirtyKey}
+	member := dirtyMember(chatID, messageID)
+	
+	if err := adjustScript.Run(s.ctx, s.redis, keys, emoji, delta, member).Err(); err != nil {
+		return fmt.Errorf("failed to update reaction counts: %w", err)
+	}
+	return nil
+}
+
+// seed reads counts from MySQL and caches them. The version is read first,
+// so a write committed during the read stops the seed instead of being
+// counted twice.
+func (s *ReactionService) seed(chatID, messageID int64) (map[string]int, error) {
+	versionKey := reactionsVersionKey(chatID, messageID)
+	version, err := s.redis.Get(s.ctx, versionKey).Result()
+	if err == redis.Nil {
+		version = "0"
+	} else if err != nil {
+		log.Printf("Warning: Failed to read reaction counts version from Redis: %v", err)
+		return s.repo.CountByMessage(messageID)
+	}
+	
+	counts, err := s.repo.CountByMessage(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if len(counts) == 0 {
+		return counts, nil
+	}
+	
+	args := []interface{}{version}
+	for emoji, count := range counts {
+		args = append(args, emoji, count)
+	}
+	if err := seedScript.Run(s.ctx, s.redis, []string{reactionsKey(chatID, messageID), versionKey}, args...).Err(); err != nil {
+		log.Printf("Warning: Failed to cache reaction counts: %v", err)
+	}
+	return counts, nil
+}
+
+// Forget drops cached counts for deleted messages, along with any pending
+// flush of them
+func (s *ReactionService) Forget(chatID int64, messageIDs []int64) error {
+	if len(messageIDs) == 0 {
+		return nil
+	}
+	
+	keys := make([]string, 0, 2*len(messageIDs))
+	members := make([]interface{}, 0, len(messageIDs))
+	for _, messageID := range messageIDs {
+		keys = append(keys, reactionsKey(chatID, messageID), reactionsVersionKey(chatID, messageID))
+		members = append(members, dirtyMember(chatID, messageID))
+	}
+	
+	pipe := s.redis.TxPipeline()
+	pipe.Del(s.ctx, keys...)
+	pipe.SRem(s.ctx, reactionsDirtyKey, members...)
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return fmt.Errorf("failed to delete reaction counts: %w", err)
+	}
+	
+	return nil
+}
+
+// Flush persists cached counts of every dirty message to MySQL
+func (s *ReactionService) Flush() (int, error) {
+	flushed := 0
+	
+	for {
+		members, err := s.redis.SPopN(s.ctx, reactionsDirtyKey, reactionFlushBatch).Result()
+		if err != nil {
+			return flushed, fmt.Errorf("failed to pop dirty reactions: %w", err)
+		}
+		if len(members) == 0 {
+			return flushed, nil
+		}
+		
+		for i, member := range members {
+			chatID, messageID, ok := parseDirtyMember(member)
+			if !ok {
+				continue
+			}
+			
+			counts, err := s.Counts(chatID, messageID)
+			if err == nil {
+				err = s.repo.ReplaceCounts(messageID, counts)
+			}
+			if errors.Is(err, repository.ErrMessageNotFound) {
+				// Deleted since; there is nothing left to store
+				if err := s.Forget(chatID, []int64{messageID}); err != nil {
+					log.Printf("Warning: %v", err)
+				}
+				continue
+			}
+			if err != nil {
+				// Put back this member and the rest of the batch so the
+				// next tick retries them
+				s.redis.SAdd(s.ctx, reactionsDirtyKey, unflushed(members[i:])...)
+				return flushed, err
+			}
+			flushed++
+		}
+	}
+}
+
+// RunFlusher flushes reaction counts every interval until ctx is cancelled
+func (s *ReactionService) RunFlusher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ctx.Done():
+			if _, err := s.Flush(); err != nil {
+				log.Printf("Error flushing reaction counts: %v", err)
+			}
+			return
+		case <-ticker.C:
+			flushed, err := s.Flush()
+			if err != nil {
+				log.Printf("Error flushing reaction counts: %v", err)
+			}
+			if flushed > 0 {
+				log.Printf("✅ Flushed reaction counts for %d messages", flushed)
+			}
+		}
+	}
+}
+
+// unflushed converts popped set members back into SADD arguments
+func unflushed(members []string) []interface{} {
+	args := make([]interface{}, len(members))
+	for i, member := range members {
+		args[i] = member
+	}
+	return args
+}
+
+func dirtyMember(chatID, messageID int64) string {
+	return fmt.Sprintf("%d:%d", chatID, messageID)
+}
+
+func parseDirtyMember(member string) (int64, int64, bool) {
+	parts := strings.SplitN(member, ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	chatID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	messageID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return chatID, messageID, true
+}
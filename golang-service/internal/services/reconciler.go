@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/metrics"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	reconcileLockKey   = "counter_reconciler:lock"
+	reconcileBatchSize = 500
+	reconcileLockTTL   = 5 * time.Minute
+)
+
+// ReconcileReport summarises one reconciliation pass
+type ReconcileReport struct {
+	Applications          int
+	Chats                 int
+	ChatsCountFixed       int
+	MessagesCountFixed    int
+	ChatCountersRaised    int
+	MessageCountersRaised int
+}
+
+// CounterReconciler replaces the Sidekiq counter workers. It recomputes
+// chats_count and messages_count from MySQL, rewrites drifted columns and
+// raises Redis counters that have fallen behind the highest stored number.
+// Redis counters are never lowered: numbers already handed out must not be
+// reused.
+type CounterReconciler struct {
+	appRepo    *repository.ApplicationRepository
+	chatRepo   *repository.ChatRepository
+	counterSvc *CounterService
+	lock       *Lock
+}
+
+func NewCounterReconciler(redisClient *redis.Client, appRepo *repository.ApplicationRepository, chatRepo *repository.ChatRepository, counterSvc *CounterService) *CounterReconciler {
+	return &CounterReconciler{
+		appRepo:    appRepo,
+		chatRepo:   chatRepo,
+		counterSvc: counterSvc,
+		lock:       NewLock(redisClient, reconcileLockKey, reconcileLockTTL),
+	}
+}
+
+// Reconcile runs one pass if no other replica is already running one. It
+// returns a nil report when the lock is held elsewhere.
+func (r *CounterReconciler) Reconcile(ctx context.Context) (*ReconcileReport, error) {
+	acquired, err := r.lock.TryAcquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, nil
+	}
+	defer func() {
+		if err := r.lock.Release(context.Background()); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}()
+	
+	report := &ReconcileReport{}
+	if err := r.reconcileApplications(ctx, report); err != nil {
+		return report, err
+	}
+	if err := r.reconcileChats(ctx, report); err != nil {
+		return report, err
+	}
+	
+	return report, nil
+}
+
+func (r *CounterReconciler) reconcileApplications(ctx context.Context, report *ReconcileReport) error {
+	var afterID int64
+	for {
+		stats, err := r.appRepo.ListChatStats(afterID, reconcileBatchSize)
+		if err != nil {
+			return err
+		}
+		
+		for _, s := range stats {
+			report.Applications++
+			
+			if s.StoredCount != s.ActualCount {
+				if err := r.appRepo.UpdateChatsCount(s.ID, s.ActualCount); err != nil {
+					return err
+				}
+				report.ChatsCountFixed++
+				metrics.CounterCorrections.Add("chats_count", 1)
+				log.Printf("Counter drift: application %s chats_count %d -> %d", s.Token, s.StoredCount, s.ActualCount)
+			}
+			
+			previous, err := r.counterSvc.RaiseChatCounter(s.Token, int64(s.MaxNumber))
+			if err != nil {
+				return err
+			}
+			if raised(previous, s) {
+				report.ChatCountersRaised++
+				metrics.CounterCorrections.Add("app_chat_counter", 1)
+				log.Printf("Counter drift: application %s chat counter %d -> %d", s.Token, previous, s.MaxNumber)
+			}
+		}
+		
+		if len(stats) < reconcileBatchSize {
+			return nil
+		}
+		afterID = stats[len(stats)-1].ID
+		
+		if err := r.keepLock(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *CounterReconciler) reconcileChats(ctx context.Context, report *ReconcileReport) error {
+	var afterID int64
+	for {
+		stats, err := r.chatRepo.ListMessageStats(afterID, reconcileBatchSize)
+		if err != nil {
+			return err
+		}
+		
+		for _, s := range stats {
+			report.Chats++
+			
+			if s.StoredCount != s.ActualCount {
+				if err := r.chatRepo.UpdateMessagesCount(s.ID, s.ActualCount); err != nil {
+					return err
+				}
+				report.MessagesCountFixed++
+				metrics.CounterCorrections.Add("messages_count", 1)
+				log.Printf("Counter drift: chat %d messages_count %d -> %d", s.ID, s.StoredCount, s.ActualCount)
+			}
+			
+			previous, err := r.counterSvc.RaiseMessageCounter(s.ID, int64(s.MaxNumber))
+			if err != nil {
+				return err
+			}
+			if raised(previous, s) {
+				report.MessageCountersRaised++
+				metrics.CounterCorrections.Add("chat_message_counter", 1)
+				log.Printf("Counter drift: chat %d message counter %d -> %d", s.ID, previous, s.MaxNumber)
+			}
+		}
+		
+		if len(stats) < reconcileBatchSize {
+			return nil
+		}
+		afterID = stats[len(stats)-1].ID
+		
+		if err := r.keepLock(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// keepLock extends the lease between batches and stops the pass if it was
+// lost or the service is shutting down
+func (r *CounterReconciler) keepLock(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	held, err := r.lock.Refresh(ctx)
+	if err != nil {
+		return err
+	}
+	if !held {
+		return fmt.Errorf("lost lock %s", reconcileLockKey)
+	}
+	return nil
+}
+
+// raised reports whether a counter really moved; seeding a missing key for
+// an empty parent is not drift
+func raised(previous int64, s models.CounterStats) bool {
+	if previous == -1 && s.MaxNumber == 0 {
+		return false
+	}
+	return previous < int64(s.MaxNumber)
+}
+
+// RunLoop reconciles once at startup and then every interval until ctx is
+// cancelled
+func (r *CounterReconciler) RunLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	
+	for {
+		r.run(ctx)
+		
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *CounterReconciler) run(ctx context.Context) {
+	report, err := r.Reconcile(ctx)
+	if err != nil {
+		metrics.CounterReconcileErrors.Add(1)
+		log.Printf("Error reconciling counters: %v", err)
+		return
+	}
+	if report == nil {
+		return
+	}
+	
+	metrics.CounterReconcileRuns.Add(1)
+	log.Printf("✅ Reconciled counters for %d applications and %d chats (%d column and %d Redis corrections)",
+		report.Applications,
+		report.Chats,
+		report.ChatsCountFixed+report.MessagesCountFixed,
+		report.ChatCountersRaised+report.MessageCountersRaised,
+	)
+}
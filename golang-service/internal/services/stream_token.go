@@ -0,0 +1,43 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StreamTokenTTL is how long a stream token can be used to open a
+// connection; it only needs to outlive the request that opens the stream
+const StreamTokenTTL = time.Minute
+
+// IssueStreamToken returns a token that stands in for the API key on
+// WebSocket and event-stream requests, and when it expires. It is signed
+// with the master key, so every replica accepts it without shared state.
+func IssueStreamToken(masterKey string, now time.Time) (string, time.Time) {
+	expiresAt := now.Add(StreamTokenTTL).Truncate(time.Second)
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+	return expiry + "." + signStreamToken(masterKey, expiry), expiresAt
+}
+
+// VerifyStreamToken reports whether token was issued with masterKey and
+// has not expired
+func VerifyStreamToken(masterKey, token string, now time.Time) bool {
+	expiry, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || !now.Before(time.Unix(unix, 0)) {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(signStreamToken(masterKey, expiry)))
+}
+
+func signStreamToken(masterKey, expiry string) string {
+	mac := hmac.New(sha256.New, []byte(masterKey))
+	mac.Write([]byte("stream_token:" + expiry))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyStreamToken(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	token, expiresAt := IssueStreamToken("master", now)
+	if !expiresAt.Equal(now.Add(StreamTokenTTL)) {
+		t.Errorf("expiresAt = %v, want %v", expiresAt, now.Add(StreamTokenTTL))
+	}
+	
+	tests := []struct {
+		name  string
+		key   string
+		token string
+		at    time.Time
+		want  bool
+	}{
+		{"fresh", "master", token, now, true},
+		{"just before expiry", "master", token, expiresAt.Add(-time.Second), true},
+		{"expired", "master", token, expiresAt, false},
+		{"other key", "rotated", token, now, false},
+		{"extended expiry", "master", "1900000000" + token[len("1800000060"):], now, false},
+		{"the key itself", "master", "master", now, false},
+		{"empty", "master", "", now, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyStreamToken(tt.key, tt.token, tt.at); got != tt.want {
+				t.Errorf("VerifyStreamToken(%q) = %v, want %v", tt.token, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+// Usage metrics stored per application and hour
+const (
+	UsageChatsCreated    = "chats_created"
+	UsageMessagesCreated = "messages_created"
+	UsageBytesStored     = "bytes_stored"
+	// UsageAPICallsPrefix is followed by "METHOD /route/template"
+	UsageAPICallsPrefix = "api_calls:"
+)
+
+// usageDirtyKey tracks hourly buckets changed since the last flush
+const usageDirtyKey = "usage:dirty"
+
+// usageFlushBatch bounds how many buckets are flushed per round
+const usageFlushBatch = 500
+
+// usageBucketTTL keeps buckets around long enough to survive a flusher
+// outage and to serve recent hours without waiting for a flush
+const usageBucketTTL = 48 * time.Hour
+
+const usageHourFormat = "2006010215"
+
+// UsageService meters per-application usage into hourly Redis hashes and
+// flushes them to usage_hourly. Metering never fails a request: Redis
+// errors are logged and the usage is lost.
+type UsageService struct {
+	redis *redis.Client
+	repo  *repository.UsageRepository
+	ctx   context.Context
+}
+
+func NewUsageService(redisClient *redis.Client, repo *repository.UsageRepository) *UsageService {
+	return &UsageService{
+		redis: redisClient,
+		repo:  repo,
+		ctx:   context.Background(),
+	}
+}
+
+func usageKey(appID int64, hour time.Time) string {
+	return fmt.Sprintf("usage:%d:%s", appID, hour.UTC().Format(usageHourFormat))
+}
+
+// RecordChat meters a created chat
+func (s *UsageService) RecordChat(appID int64) {
+	s.record(appID, map[string]int64{UsageChatsCreated: 1})
+}
+
+// RecordMessage meters a created message and the bytes of its body
+func (s *UsageService) RecordMessage(appID int64, bodyBytes int) {
+	s.record(appID, map[string]int64{
+		UsageMessagesCreated: 1,
+		UsageBytesStored:     int64(bodyBytes),
+	})
+}
+
+// RecordAPICall meters one request to route, e.g. "POST /api/v1/messages"
+func (s *UsageService) RecordAPICall(appID int64, route string) {
+	s.record(appID, map[string]int64{UsageAPICallsPrefix + route: 1})
+}
+
+func (s *UsageService) record(appID int64, deltas map[string]int64) {
+	now := time.Now().UTC()
+	key := usageKey(appID, now)
+	
+	pipe := s.redis.TxPipeline()
+	for metric, delta := range deltas {
+		pipe.HIncrBy(s.ctx, key, metric, delta)
+	}
+	pipe.Expire(s.ctx, key, usageBucketTTL)
+	pipe.SAdd(s.ctx, usageDirtyKey, fmt.Sprintf("%d:%s", appID, now.Format(usageHourFormat)))
+	
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		log.Printf("Warning: Failed to record usage for application %d: %v", appID, err)
+	}
+}
+
+// Flush persists every dirty hourly bucket to MySQL. Buckets hold running
+// totals, so flushing the current hour repeatedly is safe.
+func (s *UsageService) Flush() (int, error) {
+	flushed := 0
+	
+	for {
+		members, err := s.redis.SPopN(s.ctx, usageDirtyKey, usageFlushBatch).Result()
+		if err != nil {
+			return flushed, fmt.Errorf("failed to pop dirty usage: %w", err)
+		}
+		if len(members) == 0 {
+			return flushed, nil
+		}
+	
+		n, left, err := flushMembers(members, s.flushBucket)
+		flushed += n
+		if err != nil {
+			// Put back the failed bucket and the rest of the batch so the
+			// next tick retries them
+			s.redis.SAdd(s.ctx, usageDirtyKey, unflushed(left)...)
+			return flushed, err
+		}
+	}
+}
+
+func (s *UsageService) flushBucket(member string) error {
+	appID, hour, ok := parseUsageMember(member)
+	if !ok {
+		return nil
+	}
+	
+	values, err := s.bucket(appID, hour)
+	if err != nil {
+		return err
+	}
+	return s.repo.Upsert(appID, hour, values)
+}
+
+// flushMembers flushes members in order and stops at the first failure,
+// returning how many were flushed and the members still to flush
+func flushMembers(members []string, flush func(member string) error) (int, []string, error) {
+	for i, member := range members {
+		if err := flush(member); err != nil {
+			return i, members[i:], err
+		}
+	}
+	return len(members), nil, nil
+}
+
+// RunFlusher flushes usage every interval until ctx is cancelled
+func (s *UsageService) RunFlusher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ctx.Done():
+			if _, err := s.Flush(); err != nil {
+				log.Printf("Error flushing usage: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if _, err := s.Flush(); err != nil {
+				log.Printf("Error flushing usage: %v", err)
+			}
+		}
+	}
+}
+
+// Report returns an application's usage per hour in [from, to). Hours
+// still in Redis are merged in, so the report does not wait for a flush.
+func (s *UsageService) Report(appID int64, from, to time.Time) ([]models.UsageHour, models.UsageTotals, error) {
+	totals := models.UsageTotals{APICalls: map[string]int64{}}
+	
+	records, err := s.repo.Range(appID, from, to)
+	if err != nil {
+		return nil, totals, err
+	}
+	
+	byHour := make(map[time.Time]map[string]int64)
+	for _, rec := range records {
+		hour := rec.Hour.UTC()
+		if byHour[hour] == nil {
+			byHour[hour] = make(map[string]int64)
+		}
+		byHour[hour][rec.Metric] = rec.Value
+	}
+	
+	// Buckets only ever grow, so the larger of Redis and MySQL is current
+	recent := time.Now().UTC().Add(-usageBucketTTL).Truncate(time.Hour)
+	for hour := from; hour.Before(to); hour = hour.Add(time.Hour) {
+		if hour.Before(recent) {
+			continue
+		}
+		values, err := s.bucket(appID, hour)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			break
+		}
+		for metric, value := range values {
+			if byHour[hour] == nil {
+				byHour[hour] = make(map[string]int64)
+			}
+			if value > byHour[hour][metric] {
+				byHour[hour][metric] = value
+			}
+		}
+	}
+	
+	var hours []models.UsageHour
+	for hour := from; hour.Before(to); hour = hour.Add(time.Hour) {
+		values, ok := byHour[hour]
+		if !ok {
+			continue
+		}
+	
+		usage := models.UsageHour{Hour: hour, UsageTotals: models.UsageTotals{APICalls: map[string]int64{}}}
+		for metric, value := range values {
+			switch {
+			case metric == UsageChatsCreated:
+				usage.ChatsCreated = value
+			case metric == UsageMessagesCreated:
+				usage.MessagesCreated = value
+			case metric == UsageBytesStored:
+				usage.BytesStored = value
+			case strings.HasPrefix(metric, UsageAPICallsPrefix):
+				route := strings.TrimPrefix(metric, UsageAPICallsPrefix)
+				usage.APICalls[route] = value
+				totals.APICalls[route] += value
+			}
+		}
+		totals.ChatsCreated += usage.ChatsCreated
+		totals.MessagesCreated += usage.MessagesCreated
+		totals.BytesStored += usage.BytesStored
+		hours = append(hours, usage)
+	}
+	
+	return hours, totals, nil
+}
+
+// Export streams persisted usage rows in [from, to) to fn; see
+// UsageRepository.Export
+func (s *UsageService) Export(from, to time.Time, appID int64, fn func(models.UsageRecord) error) error {
+	return s.repo.Export(from, to, appID, fn)
+}
+
+func (s *UsageService) bucket(appID int64, hour time.Time) (map[string]int64, error) {
+	raw, err := s.redis.HGetAll(s.ctx, usageKey(appID, hour)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage bucket: %w", err)
+	}
+	
+	values := make(map[string]int64, len(raw))
+	for metric, value := range raw {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			values[metric] = n
+		}
+	}
+	return values, nil
+}
+
+func parseUsageMember(member string) (int64, time.Time, bool) {
+	id, hour, ok := strings.Cut(member, ":")
+	if !ok {
+		return 0, time.Time{}, false
+	}
+	appID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	parsed, err := time.Parse(usageHourFormat, hour)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	return appID, parsed, true
+}
+
+// ParseUsageRange parses from and to as RFC 3339 times or YYYY-MM-DD dates
+// (UTC) and widens them to whole hours. Without to, the range ends after
+// the current hour; without from, it starts 24 hours before to.
+func ParseUsageRange(from, to string) (time.Time, time.Time, error) {
+	end := time.Now().UTC().Truncate(time.Hour).Add(time.Hour)
+	if to != "" {
+		parsed, err := parseUsageTime(to)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("to: %w", err)
+		}
+		end = parsed
+		if truncated := end.Truncate(time.Hour); !truncated.Equal(end) {
+			end = truncated.Add(time.Hour)
+		}
+	}
+	
+	start := end.Add(-24 * time.Hour)
+	if from != "" {
+		parsed, err := parseUsageTime(from)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("from: %w", err)
+		}
+		start = parsed.Truncate(time.Hour)
+	}
+	
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from must be before to")
+	}
+	return start, end, nil
+}
+
+func parseUsageTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not an RFC 3339 time or a YYYY-MM-DD date", value)
+}
+
+type usageTagKey struct{}
+
+// usageTag carries the application a request turned out to belong to
+type usageTag struct {
+	appID int64
+}
+
+// WithUsageTag prepares ctx so handlers can attribute the request with
+// TagUsage once they know the application
+func WithUsageTag(ctx context.Context) context.Context {
+	return context.WithValue(ctx, usageTagKey{}, &usageTag{})
+}
+
+// TagUsage attributes the current request to an application, for handlers
+// that read the application token from the request body
+func TagUsage(ctx context.Context, appID int64) {
+	if tag, ok := ctx.Value(usageTagKey{}).(*usageTag); ok {
+		tag.appID = appID
+	}
+}
+
+// TaggedApplication returns the application set by TagUsage, or zero
+func TaggedApplication(ctx context.Context) int64 {
+	if tag, ok := ctx.Value(usageTagKey{}).(*usageTag); ok {
+		return tag.appID
+	}
+	return 0
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// A failed Upsert must leave the bucket it failed on and every bucket after
+// it to the next flush, not just the failed one
+func TestFlushMembersKeepsRemainderAfterFailure(t *testing.T) {
+	members := []string{"1:2026101900", "1:2026101901", "2:2026101900", "3:2026101900"}
+	upsertErr := errors.New("deadlock")
+	
+	var upserted []string
+	flushed, left, err := flushMembers(members, func(member string) error {
+		if member == "2:2026101900" {
+			return upsertErr
+		}
+		upserted = append(upserted, member)
+		return nil
+	})
+	
+	if !errors.Is(err, upsertErr) {
+		t.Fatalf("flushMembers() error = %v, want %v", err, upsertErr)
+	}
+	if flushed != 2 || !reflect.DeepEqual(upserted, members[:2]) {
+		t.Errorf("flushed %d (%v), want the 2 buckets before the failure", flushed, upserted)
+	}
+	if !reflect.DeepEqual(left, members[2:]) {
+		t.Errorf("left = %v, want %v", left, members[2:])
+	}
+}
+
+func TestFlushMembersFlushesWholeBatch(t *testing.T) {
+	members := []string{"1:2026101900", "2:2026101900"}
+	
+	flushed, left, err := flushMembers(members, func(string) error { return nil })
+	if err != nil || flushed != len(members) || len(left) != 0 {
+		t.Errorf("flushMembers() = %d, %v, %v; want %d, none left, nil", flushed, left, err, len(members))
+	}
+}
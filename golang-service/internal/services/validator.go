@@ -3,10 +3,22 @@ package services
 import (
 	"fmt"
 	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/validation"
 )
 
 var tokenRegex = regexp.MustCompile(`^[a-f0-9]{20}$`)
 
+// ValidationParams names the configured limits request tags refer to
+func ValidationParams(cfg *config.Config) validation.Params {
+	return validation.Params{
+		"max_body_length": cfg.Messages.MaxBodyLength,
+	}
+}
+
 // ValidateToken validates application token format
 func ValidateToken(token string) error {
 	if token == "" {
@@ -21,21 +33,32 @@ func ValidateToken(token string) error {
 	return nil
 }
 
-// ValidateMessageBody validates message body
-func ValidateMessageBody(body string) error {
-	if body == "" {
-		return fmt.Errorf("message body is required")
+// ValidateChatNumber validates chat number
+func ValidateChatNumber(number int) error {
+	if number < 1 {
+		return fmt.Errorf("chat number must be positive")
 	}
-	if len(body) > 5000 {
-		return fmt.Errorf("message body must be less than 5000 characters")
+	return nil
+}
+
+// ValidateUserID validates the reacting user's identifier
+func ValidateUserID(userID string) error {
+	if userID == "" {
+		return fmt.Errorf("user_id is required")
+	}
+	if utf8.RuneCountInString(userID) > 255 {
+		return fmt.Errorf("user_id must be at most 255 characters")
 	}
 	return nil
 }
 
-// ValidateChatNumber validates chat number
-func ValidateChatNumber(number int) error {
-	if number < 1 {
-		return fmt.Errorf("chat number must be positive")
+// ValidateSearchQuery validates a full-text search query
+func ValidateSearchQuery(query string) error {
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("query parameter q is required")
+	}
+	if utf8.RuneCountInString(query) > 200 {
+		return fmt.Errorf("query must be at most 200 characters")
 	}
 	return nil
 }
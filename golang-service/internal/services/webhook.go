@@ -0,0 +1,290 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	mathrand "math/rand"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/metrics"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/repository"
+)
+
+// Headers sent with every webhook delivery
+const (
+	WebhookIDHeader        = "X-Webhook-Id"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// webhookDrainLimit bounds how much of a response is read so the
+// connection can be reused
+const webhookDrainLimit = 512
+
+// WebhookService queues chat and message events for the endpoints that
+// subscribe to them and delivers them from the webhook_deliveries table, so
+// pending deliveries survive restarts and are shared between replicas.
+type WebhookService struct {
+	repo   *repository.WebhookRepository
+	cfg    config.WebhooksConfig
+	client *http.Client
+	// wake starts a dispatch round without waiting for the next poll
+	wake chan struct{}
+}
+
+func NewWebhookService(repo *repository.WebhookRepository, cfg config.WebhooksConfig) *WebhookService {
+	return &WebhookService{
+		repo: repo,
+		cfg:  cfg,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: webhookTransport(cfg.AllowPrivateTargets),
+			// Redirects are answers too; endpoints should give their final URL
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// GenerateWebhookSecret returns a random signing secret
+func GenerateWebhookSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// SignWebhook returns the X-Webhook-Signature value for a delivery: the
+// hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the endpoint secret
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Emit queues an event for every endpoint of app subscribed to eventType.
+// Call it once the change is committed. Failures are logged rather than
+// returned so they never fail the request that caused the event.
+func (s *WebhookService) Emit(app *models.Application, eventType string, data models.WebhookEventData) {
+	endpoints, err := s.repo.ListEndpoints(app.ID)
+	if err != nil {
+		log.Printf("Warning: Failed to load webhooks for %s: %v", app.Token, err)
+		return
+	}
+	
+	var subscribed []int64
+	for _, endpoint := range endpoints {
+		if slices.Contains(endpoint.Events, eventType) {
+			subscribed = append(subscribed, endpoint.ID)
+		}
+	}
+	if len(subscribed) == 0 {
+		return
+	}
+	
+	eventID, err := newEventID()
+	if err != nil {
+		log.Printf("Warning: Failed to queue %s webhook: %v", eventType, err)
+		return
+	}
+	
+	payload, err := json.Marshal(models.WebhookEvent{
+		ID:               eventID,
+		Type:             eventType,
+		CreatedAt:        time.Now().UTC(),
+		ApplicationToken: app.Token,
+		Data:             data,
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to queue %s webhook: %v", eventType, err)
+		return
+	}
+	
+	if err := s.repo.Enqueue(subscribed, eventID, eventType, payload); err != nil {
+		log.Printf("Warning: Failed to queue %s webhook: %v", eventType, err)
+		return
+	}
+	s.notify()
+}
+
+// Redeliver queues a delivery again as a new delivery of the same event
+func (s *WebhookService) Redeliver(delivery *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	redelivery, err := s.repo.Requeue(delivery)
+	if err != nil {
+		return nil, err
+	}
+	s.notify()
+	return redelivery, nil
+}
+
+func (s *WebhookService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func newEventID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate event ID: %w", err)
+	}
+	return "evt_" + hex.EncodeToString(buf), nil
+}
+
+// RunDispatcher delivers due webhooks every interval, and right away when
+// new events are queued, until ctx is cancelled
+func (s *WebhookService) RunDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	
+	for {
+		if err := s.Dispatch(ctx); err != nil {
+			log.Printf("Error dispatching webhooks: %v", err)
+		}
+	
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// Dispatch sends every delivery that is due, Concurrency at a time
+func (s *WebhookService) Dispatch(ctx context.Context) error {
+	// Claims outlast a full round of slow endpoints
+	lease := s.cfg.Timeout*2 + time.Minute
+	
+	for ctx.Err() == nil {
+		deliveries, err := s.repo.ClaimDue(s.cfg.Concurrency, lease)
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+	
+		var wg sync.WaitGroup
+		for i := range deliveries {
+			wg.Add(1)
+			go func(d *models.WebhookDelivery) {
+				defer wg.Done()
+				s.deliver(ctx, d)
+			}(&deliveries[i])
+		}
+		wg.Wait()
+	}
+	return nil
+}
+
+// deliver makes one attempt and records its outcome. 2xx answers succeed;
+// anything else is retried until MaxAttempts.
+func (s *WebhookService) deliver(ctx context.Context, d *models.WebhookDelivery) {
+	attempt := &models.WebhookAttempt{
+		DeliveryID: d.ID,
+		Attempt:    d.Attempts + 1,
+		CreatedAt:  time.Now(),
+	}
+	
+	attempt.StatusCode, attempt.Error = s.post(ctx, d)
+	attempt.Duration = time.Since(attempt.CreatedAt)
+	if ctx.Err() != nil {
+		// Shutting down; the claim lease runs out and another replica retries
+		return
+	}
+	
+	status := models.WebhookSucceeded
+	next := time.Now()
+	switch {
+	case attempt.Error == "":
+		metrics.WebhookDeliveries.Add("succeeded", 1)
+	case attempt.Attempt >= s.cfg.MaxAttempts:
+		status = models.WebhookFailed
+		metrics.WebhookDeliveries.Add("failed", 1)
+		log.Printf("Warning: Webhook delivery %d to %s failed after %d attempts: %s", d.ID, d.URL, attempt.Attempt, attempt.Error)
+	default:
+		status = models.WebhookPending
+		next = next.Add(s.backoff(attempt.Attempt))
+		metrics.WebhookDeliveries.Add("retried", 1)
+	}
+	
+	if err := s.repo.RecordAttempt(attempt, status, next); err != nil {
+		// The claim lease runs out and the delivery is sent again
+		log.Printf("Error recording webhook attempt: %v", err)
+	}
+}
+
+// post sends a delivery and returns the response status and, unless it was
+// 2xx, what went wrong. The error is shown to API clients, so it is one of
+// a few fixed messages and never includes what the endpoint answered.
+func (s *WebhookService) post(ctx context.Context, d *models.WebhookDelivery) (int, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, "invalid endpoint URL"
+	}
+	
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "chat-service-webhooks")
+	req.Header.Set(WebhookIDHeader, d.EventID)
+	req.Header.Set(WebhookEventHeader, d.EventType)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(d.Secret, timestamp, d.Payload))
+	
+	resp, err := s.client.Do(req)
+	if err != nil {
+		var netErr net.Error
+		switch {
+		case errors.Is(err, ErrWebhookTarget):
+			return 0, ErrWebhookTarget.Error()
+		case errors.As(err, &netErr) && netErr.Timeout():
+			return 0, "request timed out"
+		}
+		log.Printf("Warning: Webhook delivery %d failed: %v", d.ID, err)
+		return 0, "request failed"
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, webhookDrainLimit))
+	
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, ""
+	}
+	return resp.StatusCode, fmt.Sprintf("endpoint answered %d", resp.StatusCode)
+}
+
+// backoff doubles from RetryInitial per failed attempt up to RetryMax, with
+// equal jitter so endpoints coming back up are not hit all at once
+func (s *WebhookService) backoff(attempt int) time.Duration {
+	backoff := s.cfg.RetryInitial
+	for i := 1; i < attempt && backoff < s.cfg.RetryMax; i++ {
+		backoff *= 2
+	}
+	if backoff > s.cfg.RetryMax {
+		backoff = s.cfg.RetryMax
+	}
+	return backoff/2 + time.Duration(mathrand.Int63n(int64(backoff/2)+1))
+}
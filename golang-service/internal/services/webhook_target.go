@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+// ErrWebhookTarget rejects webhook URLs that point into the service's own
+// network: loopback, private, link-local (cloud metadata) and other
+// non-public ranges
+var ErrWebhookTarget = errors.New("url must resolve to a public address")
+
+// reservedPrefixes are non-public ranges that netip has no predicate for
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// publicAddress reports whether deliveries may connect to ip
+func publicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL resolves the host of a webhook URL and rejects it unless every
+// address is public. The delivery dialer checks again at connect time, so
+// a host that later resolves elsewhere is still refused.
+func (s *WebhookService) CheckURL(ctx context.Context, rawURL string) error {
+	if s.cfg.AllowPrivateTargets {
+		return nil
+	}
+	
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	
+	host := u.Hostname()
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if !publicAddress(ip) {
+			return ErrWebhookTarget
+		}
+		return nil
+	}
+	
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(ips) == 0 {
+		return errors.New("url host could not be resolved")
+	}
+	for _, ip := range ips {
+		if !publicAddress(ip) {
+			return ErrWebhookTarget
+		}
+	}
+	return nil
+}
+
+// webhookTransport dials only public addresses unless allowPrivate is set.
+// The check runs on the address actually connected to, which defeats DNS
+// rebinding, and proxies are not used since they would hide that address.
+func webhookTransport(allowPrivate bool) *http.Transport {
+	dialer := &net.Dialer{
+		Control: func(_, address string, _ syscall.RawConn) error {
+			if allowPrivate {
+				return nil
+			}
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !publicAddress(addr.Addr()) {
+				return ErrWebhookTarget
+			}
+			return nil
+		},
+	}
+	
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+)
+
+func TestPublicAddress(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := publicAddress(netip.MustParseAddr(tt.ip)); got != tt.want {
+			t.Errorf("publicAddress(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestCheckURLRejectsInternalTargets(t *testing.T) {
+	svc := NewWebhookService(nil, config.WebhooksConfig{})
+	for _, rawURL := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/hook",
+		"http://localhost/hook",
+	} {
+		if err := svc.CheckURL(context.Background(), rawURL); !errors.Is(err, ErrWebhookTarget) {
+			t.Errorf("CheckURL(%s) = %v, want ErrWebhookTarget", rawURL, err)
+		}
+	}
+	if err := svc.CheckURL(context.Background(), "https://93.184.216.34/hook"); err != nil {
+		t.Errorf("CheckURL(public IP) = %v, want nil", err)
+	}
+	
+	svc = NewWebhookService(nil, config.WebhooksConfig{AllowPrivateTargets: true})
+	if err := svc.CheckURL(context.Background(), "http://127.0.0.1/hook"); err != nil {
+		t.Errorf("CheckURL with AllowPrivateTargets = %v, want nil", err)
+	}
+}
+
+// The dialer refuses internal addresses even for URLs that passed CheckURL
+// when they were registered, as happens with DNS rebinding
+func TestWebhookClientRefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	
+	client := &http.Client{Transport: webhookTransport(false)}
+	_, err := client.Get(server.URL)
+	if !errors.Is(err, ErrWebhookTarget) {
+		t.Errorf("Get(%s) error = %v, want ErrWebhookTarget", server.URL, err)
+	}
+	
+	client = &http.Client{Transport: webhookTransport(true)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get with allowPrivate = %v", err)
+	}
+	resp.Body.Close()
+}
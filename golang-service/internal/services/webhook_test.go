@@ -0,0 +1,28 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/config"
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+)
+
+// Attempt errors are returned by the deliveries API, so they must not
+// carry anything the endpoint sent back
+func TestPostDoesNotKeepResponseBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"internal":"secret"}`))
+	}))
+	defer server.Close()
+	
+	svc := NewWebhookService(nil, config.WebhooksConfig{Timeout: time.Second, AllowPrivateTargets: true})
+	code, message := svc.post(context.Background(), &models.WebhookDelivery{URL: server.URL, Secret: "0123456789abcdef"})
+	if code != http.StatusInternalServerError || message != "endpoint answered 500" {
+		t.Errorf("post() = %d, %q; want 500, \"endpoint answered 500\"", code, message)
+	}
+}
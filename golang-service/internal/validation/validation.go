@@ -0,0 +1,372 @@
+// Package validation checks request structs against their validate tags,
+// for example `validate:"required,len=20,hex"`. Every failing field is
+// reported, not just the first.
+//
+// Rules run left to right and stop at a field's first failure. Fields that
+// are empty and not required skip their other rules. Lengths count
+// characters (runes), not bytes.
+//
+// Tags are parsed once per struct type. A mistake in them, such as an
+// unknown rule, is returned as an error rather than a field failure.
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/AhmedAbdelbasetAli/chat-service/internal/models"
+)
+
+// Rule checks one field value. param is the text after "=" in the tag,
+// with named limits already resolved. It returns what is wrong, completing
+// the sentence "<field> ...", or "" when the value passes.
+type Rule func(v reflect.Value, param string) string
+
+// Params are named limits that come from configuration, so tags can say
+// max=max_body_length instead of a fixed number
+type Params map[string]int
+
+// Errors lists every failing field
+type Errors []models.FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+var (
+	mu    sync.RWMutex
+	rules = map[string]Rule{
+		"min":       minRule,
+		"max":       maxRule,
+		"len":       lenRule,
+		"hex":       hexRule,
+		"nocontrol": noControlRule,
+		"url":       urlRule,
+		"oneof":     oneOfRule,
+		"emoji":     emojiRule,
+	}
+	// structs caches each struct type's parsed tags
+	structs = map[reflect.Type]*compiled{}
+)
+
+// limitRules take a number, or the name of a param holding one
+var limitRules = map[string]bool{"min": true, "max": true, "len": true}
+
+var paramName = regexp.MustCompile(`^[a-z_]+$`)
+
+// Register adds a custom rule, replacing any rule with the same name
+func Register(name string, rule Rule) {
+	mu.Lock()
+	defer mu.Unlock()
+	rules[name] = rule
+	// Tags are checked against the rules that exist when they are parsed
+	structs = map[reflect.Type]*compiled{}
+}
+
+// compiled is a struct type's validated fields, or why its tags are wrong
+type compiled struct {
+	fields []field
+	err    error
+}
+
+type field struct {
+	index    []int
+	name     string
+	required bool
+	rules    []boundRule
+}
+
+type boundRule struct {
+	code  string
+	rule  Rule
+	param string
+}
+
+// Struct validates the fields of v, a struct or pointer to one. It returns
+// the failing fields, or nil when every field passes. The error reports
+// tags that are wrong rather than values that are: an unknown rule, a bad
+// limit or a param missing from params.
+func Struct(v any, params Params) (Errors, error) {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("validation: %T is not a struct", v)
+	}
+	
+	c := compile(value.Type())
+	if c.err != nil {
+		return nil, c.err
+	}
+	
+	var errs Errors
+	for _, f := range c.fields {
+		fieldErr, err := f.check(value.FieldByIndex(f.index), params)
+		if err != nil {
+			return nil, err
+		}
+		if fieldErr != nil {
+			errs = append(errs, *fieldErr)
+		}
+	}
+	if len(errs) == 0 {
+		return nil, nil
+	}
+	return errs, nil
+}
+
+// Check parses the tags of v's struct type so mistakes surface at startup
+// rather than on the first request
+func Check(v any) error {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("validation: %T is not a struct", v)
+	}
+	return compile(t).err
+}
+
+// compile parses a struct type's tags the first time it is seen
+func compile(t reflect.Type) *compiled {
+	mu.RLock()
+	c, ok := structs[t]
+	mu.RUnlock()
+	if ok {
+		return c
+	}
+	
+	mu.Lock()
+	defer mu.Unlock()
+	if c, ok := structs[t]; ok {
+		return c
+	}
+	c = &compiled{}
+	c.fields, c.err = structFields(t, nil)
+	structs[t] = c
+	return c
+}
+
+func structFields(t reflect.Type, index []int) ([]field, error) {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		path := append(slices.Clone(index), i)
+	
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct {
+			embedded, err := structFields(sf.Type, path)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, embedded...)
+			continue
+		}
+	
+		tag := sf.Tag.Get("validate")
+		if tag == "" || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+	
+		f := field{index: path, name: name}
+		for _, ruleTag := range strings.Split(tag, ",") {
+			code, param, _ := strings.Cut(ruleTag, "=")
+			if code == "required" {
+				f.required = true
+				f.rules = append(f.rules, boundRule{code: code})
+				continue
+			}
+	
+			rule, ok := rules[code]
+			if !ok {
+				return nil, fmt.Errorf("validation: unknown rule %q on %s.%s", code, t.Name(), sf.Name)
+			}
+			if limitRules[code] {
+				if _, err := strconv.Atoi(param); err != nil && !paramName.MatchString(param) {
+					return nil, fmt.Errorf("validation: limit %q on %s.%s is neither a number nor a param name", param, t.Name(), sf.Name)
+				}
+			}
+			f.rules = append(f.rules, boundRule{code: code, rule: rule, param: param})
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// check runs a field's rules and returns its first failure
+func (f field) check(v reflect.Value, params Params) (*models.FieldError, error) {
+	if !f.required && isEmpty(v) {
+		return nil, nil
+	}
+	
+	for _, r := range f.rules {
+		if r.code == "required" {
+			if isEmpty(v) {
+				return &models.FieldError{Field: f.name, Code: r.code, Message: f.name + " is required"}, nil
+			}
+			continue
+		}
+	
+		param := r.param
+		if limit, ok := params[param]; ok {
+			param = strconv.Itoa(limit)
+		} else if limitRules[r.code] {
+			if _, err := strconv.Atoi(param); err != nil {
+				return nil, fmt.Errorf("validation: param %q for %s is not set", param, f.name)
+			}
+		}
+		if message := r.rule(v, param); message != "" {
+			return &models.FieldError{Field: f.name, Code: r.code, Message: f.name + " " + message}, nil
+		}
+	}
+	return nil, nil
+}
+
+// isEmpty reports zero values; blank strings count as empty
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// size is a string's length in runes, a collection's length or a number
+func size(v reflect.Value) (int, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String()), true
+	case reflect.Slice, reflect.Map:
+		return v.Len(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(v.Int()), true
+	}
+	return 0, false
+}
+
+// unit names what size counts, for messages
+func unit(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Map:
+		return " items"
+	}
+	return ""
+}
+
+// limit parses a limit rule's param, which Struct has already resolved
+// to a number
+func limit(param string) int {
+	n, _ := strconv.Atoi(param)
+	return n
+}
+
+func minRule(v reflect.Value, param string) string {
+	if n, ok := size(v); ok && n < limit(param) {
+		return fmt.Sprintf("must be at least %s%s", param, unit(v))
+	}
+	return ""
+}
+
+func maxRule(v reflect.Value, param string) string {
+	if n, ok := size(v); ok && n > limit(param) {
+		return fmt.Sprintf("must be at most %s%s", param, unit(v))
+	}
+	return ""
+}
+
+func lenRule(v reflect.Value, param string) string {
+	if n, ok := size(v); ok && n != limit(param) {
+		return fmt.Sprintf("must be exactly %s%s", param, unit(v))
+	}
+	return ""
+}
+
+var hexPattern = regexp.MustCompile(`^[a-f0-9]+$`)
+
+func hexRule(v reflect.Value, _ string) string {
+	if !hexPattern.MatchString(v.String()) {
+		return "must be hexadecimal"
+	}
+	return ""
+}
+
+// noControlRule rejects control characters other than tabs and line breaks
+func noControlRule(v reflect.Value, _ string) string {
+	for _, r := range v.String() {
+		if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+			return "must not contain control characters"
+		}
+	}
+	return ""
+}
+
+// urlRule accepts absolute http and https URLs
+func urlRule(v reflect.Value, _ string) string {
+	u, err := url.Parse(v.String())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "must be an absolute http or https URL"
+	}
+	return ""
+}
+
+// oneOfRule checks a string, or each string in a slice, against a
+// space-separated list
+func oneOfRule(v reflect.Value, param string) string {
+	allowed := strings.Fields(param)
+	values := []string{}
+	if v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, v.Index(i).String())
+		}
+	} else {
+		values = append(values, v.String())
+	}
+	
+	for _, value := range values {
+		if !slices.Contains(allowed, value) {
+			return fmt.Sprintf("must be one of %s, not %q", strings.Join(allowed, ", "), value)
+		}
+	}
+	return ""
+}
+
+// allowedEmoji is the set of reactions clients may attach to a message
+var allowedEmoji = map[string]bool{
+	"👍": true,
+	"👎": true,
+	"❤️": true,
+	"😂": true,
+	"😮": true,
+	"😢": true,
+	"🎉": true,
+	"🙏": true,
+}
+
+// emojiRule checks reactions against allowedEmoji
+func emojiRule(v reflect.Value, _ string) string {
+	if !allowedEmoji[v.String()] {
+		return "is not an allowed reaction"
+	}
+	return ""
+}
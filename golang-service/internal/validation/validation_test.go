@@ -0,0 +1,157 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Embedded struct {
+	Token string `json:"token" validate:"required,len=4,hex"`
+}
+
+type request struct {
+	Embedded
+	Name    string   `json:"name" validate:"required,max=5,nocontrol"`
+	Body    string   `json:"body" validate:"max=max_body"`
+	URL     string   `json:"url" validate:"url"`
+	Events  []string `json:"events" validate:"oneof=a b"`
+	Count   int      `json:"count" validate:"min=1"`
+	Emoji   string   `json:"emoji" validate:"emoji"`
+	Ignored string   `json:"-" validate:"required"`
+	NoName  string   `validate:"min=2"`
+}
+
+func valid() request {
+	return request{Embedded: Embedded{Token: "ab12"}, Name: "héllo", Count: 1}
+}
+
+func codes(errs Errors) map[string]string {
+	got := map[string]string{}
+	for _, err := range errs {
+		got[err.Field] = err.Code
+	}
+	return got
+}
+
+func TestStruct(t *testing.T) {
+	params := Params{"max_body": 3}
+	
+	tests := []struct {
+		name   string
+		modify func(r *request)
+		want   map[string]string
+	}{
+		{"valid", func(r *request) {}, map[string]string{}},
+		{"required", func(r *request) { r.Name = "  " }, map[string]string{"name": "required"}},
+		{"length counts runes", func(r *request) { r.Name = "héllos" }, map[string]string{"name": "max"}},
+		{"control characters", func(r *request) { r.Name = "a\x00b" }, map[string]string{"name": "nocontrol"}},
+		{"embedded fields", func(r *request) { r.Token = "xyz1" }, map[string]string{"token": "hex"}},
+		{"len", func(r *request) { r.Token = "abc" }, map[string]string{"token": "len"}},
+		{"named param", func(r *request) { r.Body = "abcd" }, map[string]string{"body": "max"}},
+		{"url", func(r *request) { r.URL = "ftp://example.com" }, map[string]string{"url": "url"}},
+		{"oneof each item", func(r *request) { r.Events = []string{"a", "c"} }, map[string]string{"events": "oneof"}},
+		{"emoji", func(r *request) { r.Emoji = "x" }, map[string]string{"emoji": "emoji"}},
+		{"allowed emoji", func(r *request) { r.Emoji = "👍" }, map[string]string{}},
+		{"field name without json tag", func(r *request) { r.NoName = "a" }, map[string]string{"NoName": "min"}},
+		{"empty optional fields skip rules", func(r *request) { r.Count = 0; r.URL = "" }, map[string]string{}},
+		{"every failure", func(r *request) { r.Name = ""; r.URL = "x"; r.Count = -1 }, map[string]string{"name": "required", "url": "url", "count": "min"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid()
+			tt.modify(&r)
+	
+			errs, err := Struct(&r, params)
+			if err != nil {
+				t.Fatalf("Struct() error = %v", err)
+			}
+			if got := codes(errs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Struct() failures = %v, want %v", got, tt.want)
+			}
+			if len(tt.want) == 0 && errs != nil {
+				t.Errorf("Struct() = %v, want nil", errs)
+			}
+		})
+	}
+}
+
+func TestStructMessages(t *testing.T) {
+	r := valid()
+	r.Name = "toolong"
+	r.Count = -1
+	
+	errs, _ := Struct(r, nil)
+	want := "name must be at most 5 characters; count must be at least 1"
+	if errs.Error() != want {
+		t.Errorf("Error() = %q, want %q", errs.Error(), want)
+	}
+}
+
+func TestStructRejectsBadTags(t *testing.T) {
+	type unknownRule struct {
+		Name string `json:"name" validate:"required,shiny"`
+	}
+	type badLimit struct {
+		Name string `json:"name" validate:"max=ten!"`
+	}
+	type missingParam struct {
+		Name string `json:"name" validate:"max=max_name"`
+	}
+	
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{"unknown rule", unknownRule{Name: "x"}, `unknown rule "shiny"`},
+		{"bad limit", badLimit{Name: "x"}, `limit "ten!"`},
+		{"missing param", missingParam{Name: "x"}, `param "max_name"`},
+		{"not a struct", "text", "is not a struct"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Struct(tt.v, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Struct() error = %v, want one mentioning %s", err, tt.want)
+			}
+		})
+	}
+	
+	// Tags are checked before any value is, even an empty optional one
+	if err := Check(&unknownRule{}); err == nil {
+		t.Error("Check() accepted an unknown rule")
+	}
+	if err := Check(missingParam{}); err != nil {
+		t.Errorf("Check() error = %v; params are only known per call", err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	type shout struct {
+		Word string `json:"word" validate:"upper"`
+	}
+	if err := Check(shout{}); err == nil {
+		t.Fatal("Check() accepted the upper rule before it was registered")
+	}
+	
+	Register("upper", func(v reflect.Value, _ string) string {
+		if strings.ToUpper(v.String()) != v.String() {
+			return "must be upper case"
+		}
+		return ""
+	})
+	defer func() {
+		mu.Lock()
+		delete(rules, "upper")
+		mu.Unlock()
+	}()
+	
+	errs, err := Struct(shout{Word: "quiet"}, nil)
+	if err != nil {
+		t.Fatalf("Struct() error = %v", err)
+	}
+	if len(errs) != 1 || errs[0].Message != "word must be upper case" {
+		t.Errorf("Struct() = %v, want the upper rule to fail", errs)
+	}
+}